@@ -28,6 +28,7 @@ type probeDesc struct {
 	Hostname string    `json:"hostname"`
 	Version  string    `json:"version"`
 	LastSeen time.Time `json:"lastSeen"`
+	Identity string    `json:"identity,omitempty"`
 }
 
 // Probe handler
@@ -53,11 +54,13 @@ func makeProbeHandler(rep Reporter) CtxHandlerFunc {
 			id, _ := n.Latest.Lookup(report.ControlProbeID)
 			hostname, _ := n.Latest.Lookup(report.HostName)
 			version, dt, _ := n.Latest.LookupEntry(report.ScopeVersion)
+			identity, _ := n.Latest.Lookup(report.ProbeIdentity)
 			result = append(result, probeDesc{
 				ID:       id,
 				Hostname: hostname,
 				Version:  version,
 				LastSeen: dt,
+				Identity: identity,
 			})
 		}
 		respondWith(w, http.StatusOK, result)
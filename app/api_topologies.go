@@ -38,6 +38,9 @@ const (
 	swarmServicesID        = "swarm-services"
 	poolsID                = "pools"
 	volumesID              = "volumes"
+	networkPoliciesID      = "network-policies"
+	ingressesID            = "ingresses"
+	csiDriversID           = "csi-drivers"
 )
 
 var (
@@ -58,6 +61,31 @@ var (
 			{Value: "hide", Label: "Hide snapshots", filter: render.IsNonSnapshotComponent, filterPseudo: false},
 		},
 	}
+	udpFilter = APITopologyOptionGroup{
+		ID:      "udp",
+		Default: "show",
+		Options: []APITopologyOption{
+			{Value: "show", Label: "Show UDP edges", filter: nil, filterPseudo: false},
+			{Value: "hide", Label: "Hide UDP edges", filter: nil, filterPseudo: false, transformer: render.FilterUDPEdges},
+		},
+	}
+	policyFilter = APITopologyOptionGroup{
+		ID:      "policy",
+		Default: "all",
+		Options: []APITopologyOption{
+			{Value: "all", Label: "All edges", filter: nil, filterPseudo: false},
+			{Value: "denied", Label: "Edges denied by policy", filter: nil, filterPseudo: false, transformer: render.FilterAllowedEdges},
+		},
+	}
+	resourcesFilter = APITopologyOptionGroup{
+		ID:      "resources",
+		Default: "all",
+		Options: []APITopologyOption{
+			{Value: "all", Label: "All pods", filter: nil, filterPseudo: false},
+			{Value: "overlimit", Label: "Pods over limits", filter: render.IsOverLimit, filterPseudo: false},
+			{Value: "underrequested", Label: "Pods under requests", filter: render.IsUnderRequested, filterPseudo: false},
+		},
+	}
 	podsFilter = APITopologyOptionGroup{
 		ID:      "cstor",
 		Default: "showCRs",
@@ -128,7 +156,7 @@ func updateKubeFilters(rpt report.Report, topologies []APITopologyDesc) []APITop
 	sort.Strings(ns)
 	topologies = append([]APITopologyDesc{}, topologies...) // Make a copy so we can make changes safely
 	for i, t := range topologies {
-		if t.id == containersID || t.id == podsID || t.id == servicesID || t.id == kubeControllersID {
+		if t.id == containersID || t.id == podsID || t.id == servicesID || t.id == kubeControllersID || t.id == networkPoliciesID || t.id == ingressesID || strings.HasPrefix(t.id, render.CustomResourceAPITopologyPrefix) {
 			topologies[i] = mergeTopologyFilters(t, []APITopologyOptionGroup{
 				namespaceFilters(ns, "All Namespaces"),
 			})
@@ -190,6 +218,7 @@ func MakeRegistry() *Registry {
 				{Value: "hide", Label: "Hide uncontained", filter: render.IsNotPseudo, filterPseudo: true},
 			},
 		},
+		udpFilter,
 	}
 
 	unconnectedFilter := []APITopologyOptionGroup{
@@ -201,6 +230,7 @@ func MakeRegistry() *Registry {
 				{Value: "hide", Label: "Hide unconnected", filter: render.IsConnected, filterPseudo: false},
 			},
 		},
+		udpFilter,
 	}
 
 	// Topology option labels should tell the current state. The first item must
@@ -248,7 +278,7 @@ func MakeRegistry() *Registry {
 			renderer:    render.PodRenderer,
 			Name:        "Pods",
 			Rank:        3,
-			Options:     []APITopologyOptionGroup{unmanagedFilter},
+			Options:     []APITopologyOptionGroup{unmanagedFilter, udpFilter, policyFilter, resourcesFilter},
 			HideIfEmpty: true,
 		},
 		APITopologyDesc{
@@ -256,7 +286,7 @@ func MakeRegistry() *Registry {
 			parent:      podsID,
 			renderer:    render.KubeControllerRenderer,
 			Name:        "Controllers",
-			Options:     []APITopologyOptionGroup{unmanagedFilter},
+			Options:     []APITopologyOptionGroup{unmanagedFilter, udpFilter},
 			HideIfEmpty: true,
 		},
 		APITopologyDesc{
@@ -264,7 +294,23 @@ func MakeRegistry() *Registry {
 			parent:      podsID,
 			renderer:    render.PodServiceRenderer,
 			Name:        "Services",
-			Options:     []APITopologyOptionGroup{unmanagedFilter},
+			Options:     []APITopologyOptionGroup{unmanagedFilter, udpFilter},
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          networkPoliciesID,
+			parent:      podsID,
+			renderer:    render.NetworkPolicyRenderer,
+			Name:        "Network Policies",
+			Options:     []APITopologyOptionGroup{},
+			HideIfEmpty: true,
+		},
+		APITopologyDesc{
+			id:          ingressesID,
+			parent:      podsID,
+			renderer:    render.IngressRenderer,
+			Name:        "Ingresses",
+			Options:     []APITopologyOptionGroup{},
 			HideIfEmpty: true,
 		},
 		APITopologyDesc{
@@ -272,7 +318,7 @@ func MakeRegistry() *Registry {
 			renderer:    render.ECSTaskRenderer,
 			Name:        "Tasks",
 			Rank:        3,
-			Options:     []APITopologyOptionGroup{unmanagedFilter},
+			Options:     []APITopologyOptionGroup{unmanagedFilter, udpFilter},
 			HideIfEmpty: true,
 		},
 		APITopologyDesc{
@@ -280,7 +326,7 @@ func MakeRegistry() *Registry {
 			parent:      ecsTasksID,
 			renderer:    render.ECSServiceRenderer,
 			Name:        "Services",
-			Options:     []APITopologyOptionGroup{unmanagedFilter},
+			Options:     []APITopologyOptionGroup{unmanagedFilter, udpFilter},
 			HideIfEmpty: true,
 		},
 		APITopologyDesc{
@@ -288,7 +334,7 @@ func MakeRegistry() *Registry {
 			renderer:    render.SwarmServiceRenderer,
 			Name:        "Services",
 			Rank:        3,
-			Options:     []APITopologyOptionGroup{unmanagedFilter},
+			Options:     []APITopologyOptionGroup{unmanagedFilter, udpFilter},
 			HideIfEmpty: true,
 		},
 		APITopologyDesc{
@@ -296,6 +342,7 @@ func MakeRegistry() *Registry {
 			renderer: render.HostRenderer,
 			Name:     "Hosts",
 			Rank:     4,
+			Options:  []APITopologyOptionGroup{udpFilter},
 		},
 		// APITopologyDesc{
 		// 	id:       weaveID,
@@ -319,6 +366,14 @@ func MakeRegistry() *Registry {
 			Options:     []APITopologyOptionGroup{podsFilter, snapshotFilter, unmanagedFilter},
 			HideIfEmpty: true,
 		},
+		APITopologyDesc{
+			id:          csiDriversID,
+			parent:      hostsID,
+			renderer:    render.CSIDriverRenderer,
+			Name:        "CSI Drivers",
+			Options:     []APITopologyOptionGroup{},
+			HideIfEmpty: true,
+		},
 	)
 
 	return registry
@@ -361,6 +416,17 @@ type APITopologyOptionGroup struct {
 	NoneLabel string `json:"noneLabel,omitempty"`
 }
 
+// Get the transformers to use for this option group, besides its filter.
+func (g APITopologyOptionGroup) transformers(value string) []render.Transformer {
+	var transformers []render.Transformer
+	for _, opt := range g.Options {
+		if opt.Value == value && opt.transformer != nil {
+			transformers = append(transformers, opt.transformer)
+		}
+	}
+	return transformers
+}
+
 // Get the render filters to use for this option group, if any, or nil otherwise.
 func (g APITopologyOptionGroup) filter(value string) render.FilterFunc {
 	var values []string
@@ -406,6 +472,8 @@ type APITopologyOption struct {
 
 	filter       render.FilterFunc
 	filterPseudo bool
+	// transformer is applied to the filtered nodes, e.g. to remove edges
+	transformer render.Transformer
 }
 
 type topologyStats struct {
@@ -451,6 +519,10 @@ func (r *Registry) AddContainerFilters(newFilters ...APITopologyOption) {
 func (r *Registry) Add(ts ...APITopologyDesc) {
 	r.Lock()
 	defer r.Unlock()
+	r.add(ts...)
+}
+
+func (r *Registry) add(ts ...APITopologyDesc) {
 	for _, t := range ts {
 		t.URL = apiTopologyURL + t.id
 		t.renderer = render.Memoise(t.renderer)
@@ -465,6 +537,55 @@ func (r *Registry) Add(ts ...APITopologyDesc) {
 	}
 }
 
+// customResourceTopology returns the topology of the custom resources of
+// the report topology of that name. The custom resources are configured
+// in the probes, so their topologies are made for each report rather than
+// registered: they are only shown to the tenants whose reports carry them,
+// and go away once no report does.
+func customResourceTopology(rpt report.Report, name string) APITopologyDesc {
+	id := render.CustomResourceAPITopologyID(name)
+	return APITopologyDesc{
+		id:          id,
+		parent:      podsID,
+		renderer:    render.CustomResourceRenderer(name),
+		URL:         apiTopologyURL + id,
+		Name:        rpt.CustomResources[name].LabelPlural,
+		Options:     []APITopologyOptionGroup{},
+		HideIfEmpty: true,
+	}
+}
+
+// customResourceTopologies returns the topologies of the custom resources
+// in the report, sorted by name.
+func customResourceTopologies(rpt report.Report) []APITopologyDesc {
+	names := make([]string, 0, len(rpt.CustomResources))
+	for name := range rpt.CustomResources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	topologies := make([]APITopologyDesc, 0, len(names))
+	for _, name := range names {
+		topologies = append(topologies, customResourceTopology(rpt, name))
+	}
+	return topologies
+}
+
+// getForReport returns the topology with the ID, including the custom
+// resource topologies of the report.
+func (r *Registry) getForReport(rpt report.Report, id string) (APITopologyDesc, bool) {
+	if t, ok := r.get(id); ok {
+		return t, true
+	}
+	if !strings.HasPrefix(id, render.CustomResourceAPITopologyPrefix) {
+		return APITopologyDesc{}, false
+	}
+	name := report.CustomResourcePrefix + strings.TrimPrefix(id, render.CustomResourceAPITopologyPrefix)
+	if _, ok := rpt.CustomResources[name]; !ok {
+		return APITopologyDesc{}, false
+	}
+	return customResourceTopology(rpt, name), true
+}
+
 func (r *Registry) get(name string) (APITopologyDesc, bool) {
 	r.RLock()
 	defer r.RUnlock()
@@ -488,6 +609,18 @@ func (r *Registry) walk(f func(APITopologyDesc)) {
 	}
 }
 
+// walkForReport is walk, with the custom resource topologies of the report
+// added below the pods.
+func (r *Registry) walkForReport(rpt report.Report, f func(APITopologyDesc)) {
+	custom := customResourceTopologies(rpt)
+	r.walk(func(desc APITopologyDesc) {
+		if desc.id == podsID && len(custom) > 0 {
+			desc.SubTopologies = append(append([]APITopologyDesc{}, desc.SubTopologies...), custom...)
+		}
+		f(desc)
+	})
+}
+
 // makeTopologyList returns a handler that yields an APITopologyList.
 func (r *Registry) makeTopologyList(rep Reporter) CtxHandlerFunc {
 	return func(ctx context.Context, w http.ResponseWriter, req *http.Request) {
@@ -506,7 +639,7 @@ func (r *Registry) renderTopologies(ctx context.Context, rpt report.Report, req
 	defer span.Finish()
 	topologies := []APITopologyDesc{}
 	req.ParseForm()
-	r.walk(func(desc APITopologyDesc) {
+	r.walkForReport(rpt, func(desc APITopologyDesc) {
 		renderer, filter, _ := r.RendererForTopology(desc.id, req.Form, rpt)
 		desc.Stats = computeStats(ctx, rpt, renderer, filter)
 		for i, sub := range desc.SubTopologies {
@@ -544,7 +677,7 @@ func computeStats(ctx context.Context, rpt report.Report, renderer render.Render
 
 // RendererForTopology ..
 func (r *Registry) RendererForTopology(topologyID string, values url.Values, rpt report.Report) (render.Renderer, render.Transformer, error) {
-	topology, ok := r.get(topologyID)
+	topology, ok := r.getForReport(rpt, topologyID)
 	if !ok {
 		return nil, nil, fmt.Errorf("topology not found: %s", topologyID)
 	}
@@ -555,7 +688,10 @@ func (r *Registry) RendererForTopology(topologyID string, values url.Values, rpt
 		return topology.renderer, render.FilterUnconnectedPseudo, nil
 	}
 
-	var filters []render.FilterFunc
+	var (
+		filters      []render.FilterFunc
+		transformers []render.Transformer
+	)
 	for _, group := range topology.Options {
 		value := group.Default
 		if vs := values[group.ID]; len(vs) > 0 {
@@ -564,9 +700,13 @@ func (r *Registry) RendererForTopology(topologyID string, values url.Values, rpt
 		if filter := group.filter(value); filter != nil {
 			filters = append(filters, filter)
 		}
+		transformers = append(transformers, group.transformers(value)...)
 	}
 	if len(filters) > 0 {
-		return topology.renderer, render.Transformers([]render.Transformer{render.ComposeFilterFuncs(filters...), render.FilterUnconnectedPseudo}), nil
+		transformers = append([]render.Transformer{render.ComposeFilterFuncs(filters...)}, transformers...)
+	}
+	if len(transformers) > 0 {
+		return topology.renderer, render.Transformers(append(transformers, render.FilterUnconnectedPseudo)), nil
 	}
 	return topology.renderer, render.FilterUnconnectedPseudo, nil
 }
@@ -585,15 +725,15 @@ func (r *Registry) captureRenderer(rep Reporter, f rendererHandler) CtxHandlerFu
 			topologyID = mux.Vars(req)["topology"]
 			timestamp  = deserializeTimestamp(req.URL.Query().Get("timestamp"))
 		)
-		if _, ok := r.get(topologyID); !ok {
-			http.NotFound(w, req)
-			return
-		}
 		rpt, err := rep.Report(ctx, timestamp)
 		if err != nil {
 			respondWith(w, http.StatusInternalServerError, err)
 			return
 		}
+		if _, ok := r.getForReport(rpt, topologyID); !ok {
+			http.NotFound(w, req)
+			return
+		}
 		req.ParseForm()
 		renderer, filter, err := r.RendererForTopology(topologyID, req.Form, rpt)
 		if err != nil {
@@ -250,3 +250,31 @@ func TestAPITopologyAddsKubernetes(t *testing.T) {
 		t.Error("Could not find pods topology")
 	}
 }
+
+func TestRendererForCustomResourceTopology(t *testing.T) {
+	var (
+		topologyRegistry = app.MakeRegistry()
+		rpt              = report.MakeReport()
+		kafkas           = report.MakeCustomResourceTopology("kafkas.kafka.strimzi.io")
+		kafkaID          = report.MakeCustomResourceNodeID(kafkas, "kafka")
+	)
+	kafkaTopology := rpt.CustomResourceTopology("kafkas.kafka.strimzi.io")
+	*kafkaTopology = kafkaTopology.WithLabel("Kafka cluster", "Kafka clusters")
+	kafkaTopology.AddNode(report.MakeNodeWith(kafkaID, map[string]string{kubernetes.Namespace: "kafka"}))
+
+	if _, _, err := topologyRegistry.RendererForTopology("custom-resources-kafkas.kafka.strimzi.io", url.Values{}, report.MakeReport()); err == nil {
+		t.Fatalf("Expected custom resources to be unknown until reported")
+	}
+	renderer, filter, err := topologyRegistry.RendererForTopology("custom-resources-kafkas.kafka.strimzi.io", url.Values{"namespace": []string{"kafka"}}, rpt)
+	if err != nil {
+		t.Fatalf("Topology found error: %s", err)
+	}
+	nodes := render.Render(context.Background(), rpt, renderer, filter).Nodes
+	if _, ok := nodes[kafkaID]; !ok {
+		t.Errorf("Expected the Kafka cluster to be rendered, got %v", nodes)
+	}
+
+	if _, _, err := topologyRegistry.RendererForTopology("custom-resources-kafkas.kafka.strimzi.io", url.Values{}, report.MakeReport()); err == nil {
+		t.Errorf("Expected custom resources to be unknown once no longer reported")
+	}
+}
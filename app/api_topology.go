@@ -1,8 +1,11 @@
 package app
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"context"
@@ -28,6 +31,14 @@ type APITopology struct {
 	Nodes detailed.NodeSummaries `json:"nodes"`
 }
 
+// APITopologyDiff is returned by the /api/topology/{name}/diff handler.
+type APITopologyDiff struct {
+	From  time.Time         `json:"from"`
+	To    time.Time         `json:"to"`
+	Nodes detailed.Diff     `json:"nodes"`
+	Edges detailed.EdgeDiff `json:"edges"`
+}
+
 // APINode is returned by the /api/topology/{name}/{id} handler.
 type APINode struct {
 	Node detailed.Node `json:"node"`
@@ -48,11 +59,39 @@ type rendererHandler func(context.Context, render.Renderer, render.Transformer,
 func handleTopology(ctx context.Context, renderer render.Renderer, transformer render.Transformer, rc detailed.RenderContext, w http.ResponseWriter, r *http.Request) {
 	censorCfg := report.GetCensorConfigFromRequest(r)
 	nodeSummaries := detailed.Summaries(ctx, rc, render.Render(ctx, rc.Report, renderer, transformer).Nodes)
+	nodeSummaries = detailed.CensorNodeSummaries(nodeSummaries, censorCfg)
+	if format := r.URL.Query().Get("format"); format != "" && format != "json" {
+		if !exportTopology(w, format, mux.Vars(r)["topology"], nodeSummaries) {
+			respondWith(w, http.StatusBadRequest, fmt.Errorf("unsupported format: %s", format))
+		}
+		return
+	}
 	respondWith(w, http.StatusOK, APITopology{
-		Nodes: detailed.CensorNodeSummaries(nodeSummaries, censorCfg),
+		Nodes: nodeSummaries,
 	})
 }
 
+// APITopologyEdges is returned by the /api/topology/{name}/edges handler.
+type APITopologyEdges struct {
+	Edges []render.EdgeThroughput `json:"edges"`
+}
+
+// Edges of the full topology with a known throughput, heaviest first.
+func handleTopologyEdges(ctx context.Context, renderer render.Renderer, transformer render.Transformer, rc detailed.RenderContext, w http.ResponseWriter, r *http.Request) {
+	edges := render.RankEdges(render.Render(ctx, rc.Report, renderer, transformer).Nodes)
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			respondWith(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %s", limit))
+			return
+		}
+		if n < len(edges) {
+			edges = edges[:n]
+		}
+	}
+	respondWith(w, http.StatusOK, APITopologyEdges{Edges: edges})
+}
+
 // Individual nodes.
 func handleNode(ctx context.Context, renderer render.Renderer, transformer render.Transformer, rc detailed.RenderContext, w http.ResponseWriter, r *http.Request) {
 	var (
@@ -79,13 +118,121 @@ func handleNode(ctx context.Context, renderer render.Renderer, transformer rende
 		nodes.Nodes[nodeID] = node
 		nodes.Filtered--
 	}
-	if r.Header.Get(report.UserKindHeader) == report.ReadAdminUSer {
-		rawNode := detailed.MakeNodeWithReadOnlyControls(topologyID, r.Header.Get(report.UserKindHeader), rc, nodes.Nodes, node)
-		respondWith(w, http.StatusOK, APINode{Node: detailed.CensorNode(rawNode, censorCfg)})
-	} else {
-		rawNode := detailed.MakeNode(topologyID, rc, nodes.Nodes, node)
-		respondWith(w, http.StatusOK, APINode{Node: detailed.CensorNode(rawNode, censorCfg)})
+	var (
+		readOnly = detailed.ReadOnlyControls(r.Header.Get(report.UserKindHeader))
+		access   = AccessFromContext(ctx)
+		filter   = func(n report.Node, c report.Control) bool {
+			return (readOnly == nil || readOnly(n, c)) && access.AllowNodeControl(n, c.ID)
+		}
+	)
+	rawNode := detailed.MakeNodeWithControlFilter(topologyID, filter, rc, nodes.Nodes, node)
+	respondWith(w, http.StatusOK, APINode{Node: detailed.CensorNode(rawNode, censorCfg)})
+}
+
+// APIEdge is returned by the /api/topology/{name}/edge/{from}/{to} handler.
+type APIEdge struct {
+	Edge detailed.EdgeSummary `json:"edge"`
+}
+
+// Connections behind the edge between two nodes.
+func handleEdge(ctx context.Context, renderer render.Renderer, transformer render.Transformer, rc detailed.RenderContext, w http.ResponseWriter, r *http.Request) {
+	var (
+		vars   = mux.Vars(r)
+		fromID = vars["from"]
+		toID   = vars["to"]
+		nodes  = render.Render(ctx, rc.Report, renderer, transformer).Nodes
+	)
+	from, ok := nodes[fromID]
+	if !ok || !from.Adjacency.Contains(toID) {
+		http.NotFound(w, r)
+		return
+	}
+	to, ok := nodes[toID]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	// Rendered nodes are shared with the render cache, so they are
+	// censored here rather than by censoring the report.
+	censorCfg := report.GetCensorConfigFromRequest(r)
+	from, to = report.CensorRawNode(from, censorCfg), report.CensorRawNode(to, censorCfg)
+	respondWith(w, http.StatusOK, APIEdge{Edge: detailed.MakeEdgeSummary(rc.Report, from, to)})
+}
+
+// Changes to the full topology between two points in time.
+func handleTopologyDiff(ctx context.Context, rep Reporter, w http.ResponseWriter, r *http.Request) {
+	topologyID := mux.Vars(r)["topology"]
+	// Custom resource topologies are only known from the reports rendered
+	// below.
+	if _, ok := topologyRegistry.get(topologyID); !ok && !strings.HasPrefix(topologyID, render.CustomResourceAPITopologyPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		respondWith(w, http.StatusBadRequest, err)
+		return
+	}
+	from, err := parseTimestampParam(r.Form, "from")
+	if err != nil {
+		respondWith(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := parseTimestampParam(r.Form, "to")
+	if err != nil {
+		respondWith(w, http.StatusBadRequest, err)
+		return
+	}
+
+	censorCfg := report.GetCensorConfigFromRequest(r)
+	fromTopo, err := renderSummariesAt(ctx, rep, topologyID, r.Form, from, censorCfg)
+	if err != nil {
+		respondWith(w, http.StatusInternalServerError, err)
+		return
+	}
+	toTopo, err := renderSummariesAt(ctx, rep, topologyID, r.Form, to, censorCfg)
+	if err != nil {
+		respondWith(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	nodes := detailed.TopoDiff(fromTopo, toTopo)
+	nodes.Reset = false
+	respondWith(w, http.StatusOK, APITopologyDiff{
+		From:  from,
+		To:    to,
+		Nodes: nodes,
+		Edges: detailed.TopoEdgeDiff(fromTopo, toTopo),
+	})
+}
+
+// parseTimestampParam parses the mandatory ISO8601 query param name.
+func parseTimestampParam(values url.Values, name string) (time.Time, error) {
+	value := values.Get(name)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("missing parameter '%s'", name)
+	}
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp '%s' for parameter '%s': %v", value, name, err)
+	}
+	return timestamp, nil
+}
+
+// renderSummariesAt renders the topology as of timestamp, through the
+// same pipeline as the full topology handler.
+func renderSummariesAt(ctx context.Context, rep Reporter, topologyID string, values url.Values, timestamp time.Time, censorCfg report.CensorConfig) (detailed.NodeSummaries, error) {
+	rpt, err := rep.Report(ctx, timestamp)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error generating report")
+	}
+	renderer, filter, err := topologyRegistry.RendererForTopology(topologyID, values, rpt)
+	if err != nil {
+		return nil, err
 	}
+	return detailed.CensorNodeSummaries(
+		detailed.Summaries(ctx, RenderContextForReporter(rep, rpt), render.Render(ctx, rpt, renderer, filter).Nodes),
+		censorCfg,
+	), nil
 }
 
 // Websocket for the full topology.
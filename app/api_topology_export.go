@@ -0,0 +1,303 @@
+package app
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/scope/render/detailed"
+	"github.com/weaveworks/scope/report"
+)
+
+// Formats the full topology can be exported in, besides JSON.
+const (
+	dotFormat     = "dot"
+	graphMLFormat = "graphml"
+	mermaidFormat = "mermaid"
+)
+
+type topologyExporter struct {
+	contentType string
+	write       func(io.Writer, string, detailed.NodeSummaries) error
+}
+
+var topologyExporters = map[string]topologyExporter{
+	dotFormat:     {"text/vnd.graphviz; charset=utf-8", writeDOT},
+	graphMLFormat: {"application/graphml+xml; charset=utf-8", writeGraphML},
+	mermaidFormat: {"text/plain; charset=utf-8", writeMermaid},
+}
+
+// Graphviz shape and style of each node shape.
+var dotShapes = map[string][2]string{
+	report.Circle:          {"circle", ""},
+	report.Triangle:        {"triangle", ""},
+	report.Square:          {"square", ""},
+	report.Pentagon:        {"pentagon", ""},
+	report.Hexagon:         {"hexagon", ""},
+	report.Heptagon:        {"septagon", ""},
+	report.Octagon:         {"octagon", ""},
+	report.Cloud:           {"ellipse", ""},
+	report.Cylinder:        {"cylinder", ""},
+	report.DottedCylinder:  {"cylinder", "dashed"},
+	report.StorageSheet:    {"note", ""},
+	report.DottedTriangle:  {"triangle", "dashed"},
+	report.DottedSquare:    {"square", "dashed"},
+	report.Controller:      {"component", ""},
+	report.Replica:         {"box3d", ""},
+	report.Rectangle:       {"rect", ""},
+	report.DottedRectangle: {"rect", "dashed"},
+}
+
+// Mermaid node delimiters of each node shape.
+var mermaidShapes = map[string][2]string{
+	report.Circle:          {"((", "))"},
+	report.Triangle:        {">", "]"},
+	report.Square:          {"[", "]"},
+	report.Pentagon:        {"[/", "\\]"},
+	report.Hexagon:         {"{{", "}}"},
+	report.Heptagon:        {"{{", "}}"},
+	report.Octagon:         {"{{", "}}"},
+	report.Cloud:           {"(", ")"},
+	report.Cylinder:        {"[(", ")]"},
+	report.DottedCylinder:  {"[(", ")]"},
+	report.StorageSheet:    {"[/", "/]"},
+	report.DottedTriangle:  {">", "]"},
+	report.DottedSquare:    {"[", "]"},
+	report.Controller:      {"[[", "]]"},
+	report.Replica:         {"[[", "]]"},
+	report.Rectangle:       {"[", "]"},
+	report.DottedRectangle: {"[", "]"},
+}
+
+// exportTopology writes the node summaries in the given format. It returns
+// false if the format is not known.
+func exportTopology(w http.ResponseWriter, format, topologyID string, nodes detailed.NodeSummaries) bool {
+	exporter, ok := topologyExporters[format]
+	if !ok {
+		return false
+	}
+	w.Header().Set("Content-Type", exporter.contentType)
+	w.Header().Add("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	bw := bufio.NewWriter(w)
+	if err := exporter.write(bw, topologyID, nodes); err != nil {
+		log.Errorf("Error exporting topology %s as %s: %v", topologyID, format, err)
+		return true
+	}
+	if err := bw.Flush(); err != nil {
+		log.Errorf("Error exporting topology %s as %s: %v", topologyID, format, err)
+	}
+	return true
+}
+
+func sortedNodeIDs(nodes detailed.NodeSummaries) []string {
+	ids := make([]string, 0, len(nodes))
+	for id := range nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// edges returns the adjacencies between the given nodes, dropping
+// adjacencies to nodes that were filtered out.
+func edges(nodes detailed.NodeSummaries, ids []string) []detailed.Edge {
+	var result []detailed.Edge
+	for _, id := range ids {
+		for _, target := range nodes[id].Adjacency {
+			if _, ok := nodes[target]; ok {
+				result = append(result, detailed.Edge{Source: id, Target: target})
+			}
+		}
+	}
+	return result
+}
+
+func nodeLabel(n detailed.NodeSummary) string {
+	if n.LabelMinor == "" {
+		return n.Label
+	}
+	return n.Label + "\n" + n.LabelMinor
+}
+
+// writeDOT writes the topology as a Graphviz digraph. Nodes sharing a rank
+// are placed on the same rank.
+func writeDOT(w io.Writer, topologyID string, nodes detailed.NodeSummaries) error {
+	ids := sortedNodeIDs(nodes)
+	ranks := map[string][]string{}
+	fmt.Fprintf(w, "digraph %s {\n", dotQuote(topologyID))
+	for _, id := range ids {
+		n := nodes[id]
+		shape := dotShapes[n.Shape]
+		if shape[0] == "" {
+			shape[0] = "circle"
+		}
+		styles := []string{}
+		if shape[1] != "" {
+			styles = append(styles, shape[1])
+		}
+		if n.Pseudo {
+			styles = append(styles, "dotted")
+		}
+		fmt.Fprintf(w, "  %s [label=%s, shape=%s", dotQuote(id), dotQuote(nodeLabel(n)), shape[0])
+		if n.Stack {
+			fmt.Fprint(w, ", peripheries=2")
+		}
+		if len(styles) > 0 {
+			fmt.Fprintf(w, ", style=%s", dotQuote(strings.Join(styles, ",")))
+		}
+		fmt.Fprintln(w, "];")
+		if n.Rank != "" {
+			ranks[n.Rank] = append(ranks[n.Rank], id)
+		}
+	}
+	rankNames := make([]string, 0, len(ranks))
+	for rank, ids := range ranks {
+		if len(ids) > 1 {
+			rankNames = append(rankNames, rank)
+		}
+	}
+	sort.Strings(rankNames)
+	for _, rank := range rankNames {
+		fmt.Fprint(w, "  { rank=same;")
+		for _, id := range ranks[rank] {
+			fmt.Fprintf(w, " %s;", dotQuote(id))
+		}
+		fmt.Fprintln(w, " }")
+	}
+	for _, e := range edges(nodes, ids) {
+		fmt.Fprintf(w, "  %s -> %s;\n", dotQuote(e.Source), dotQuote(e.Target))
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+var graphMLKeys = []graphMLKey{
+	{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+	{ID: "labelMinor", For: "node", AttrName: "labelMinor", AttrType: "string"},
+	{ID: "rank", For: "node", AttrName: "rank", AttrType: "string"},
+	{ID: "shape", For: "node", AttrName: "shape", AttrType: "string"},
+	{ID: "stack", For: "node", AttrName: "stack", AttrType: "boolean"},
+	{ID: "pseudo", For: "node", AttrName: "pseudo", AttrType: "boolean"},
+}
+
+// writeGraphML writes the topology as a GraphML document, keeping the
+// node attributes as data.
+func writeGraphML(w io.Writer, topologyID string, nodes detailed.NodeSummaries) error {
+	ids := sortedNodeIDs(nodes)
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{ID: topologyID, EdgeDefault: "directed"},
+	}
+	for _, id := range ids {
+		n := nodes[id]
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: id,
+			Data: []graphMLData{
+				{Key: "label", Value: n.Label},
+				{Key: "labelMinor", Value: n.LabelMinor},
+				{Key: "rank", Value: n.Rank},
+				{Key: "shape", Value: n.Shape},
+				{Key: "stack", Value: fmt.Sprint(n.Stack)},
+				{Key: "pseudo", Value: fmt.Sprint(n.Pseudo)},
+			},
+		})
+	}
+	for _, e := range edges(nodes, ids) {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: e.Source, Target: e.Target})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(doc)
+}
+
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "\n", "<br/>")
+
+// writeMermaid writes the topology as a Mermaid flowchart. Mermaid has no
+// notion of ranks; pseudo nodes are drawn with a dashed border.
+func writeMermaid(w io.Writer, topologyID string, nodes detailed.NodeSummaries) error {
+	ids := sortedNodeIDs(nodes)
+	// Mermaid identifiers cannot contain most of the characters in node IDs
+	mermaidIDs := make(map[string]string, len(ids))
+	if _, err := fmt.Fprintf(w, "%%%% %s\ngraph LR\n", topologyID); err != nil {
+		return err
+	}
+	var pseudo []string
+	for i, id := range ids {
+		n := nodes[id]
+		mermaidID := fmt.Sprintf("n%d", i)
+		mermaidIDs[id] = mermaidID
+		shape, ok := mermaidShapes[n.Shape]
+		if !ok {
+			shape = mermaidShapes[report.Circle]
+		}
+		if _, err := fmt.Fprintf(w, "  %s%s\"%s\"%s\n", mermaidID, shape[0], mermaidEscaper.Replace(nodeLabel(n)), shape[1]); err != nil {
+			return err
+		}
+		if n.Pseudo {
+			pseudo = append(pseudo, mermaidID)
+		}
+	}
+	for _, e := range edges(nodes, ids) {
+		if _, err := fmt.Fprintf(w, "  %s --> %s\n", mermaidIDs[e.Source], mermaidIDs[e.Target]); err != nil {
+			return err
+		}
+	}
+	if len(pseudo) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "  classDef pseudo stroke-dasharray: 5 5\n  class %s pseudo\n", strings.Join(pseudo, ","))
+	return err
+}
@@ -1,9 +1,12 @@
 package app_test
 
 import (
+	"encoding/xml"
 	"fmt"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/ugorji/go/codec"
@@ -117,6 +120,93 @@ func TestAPITopologyHosts(t *testing.T) {
 	}
 }
 
+func TestAPITopologyDiff(t *testing.T) {
+	ts := topologyServer()
+	defer ts.Close()
+	is404(t, ts, "/api/topology/foobar/diff?from=2018-01-01T14:00:00Z&to=2018-01-01T14:05:00Z")
+	is400(t, ts, "/api/topology/hosts/diff?to=2018-01-01T14:05:00Z")
+	is400(t, ts, "/api/topology/hosts/diff?from=yesterday&to=2018-01-01T14:05:00Z")
+
+	// The static collector returns the same report at any time, so nothing changes
+	body := getRawJSON(t, ts, "/api/topology/hosts/diff?from=2018-01-01T14:00:00Z&to=2018-01-01T14:05:00Z")
+	var diff app.APITopologyDiff
+	decoder := codec.NewDecoderBytes(body, &codec.JsonHandle{})
+	if err := decoder.Decode(&diff); err != nil {
+		t.Fatalf("JSON parse error: %s", err)
+	}
+	equals(t, 0, len(diff.Nodes.Add))
+	equals(t, 0, len(diff.Nodes.Update))
+	equals(t, 0, len(diff.Nodes.Remove))
+	equals(t, 0, len(diff.Edges.Add))
+	equals(t, 0, len(diff.Edges.Remove))
+	equals(t, time.Date(2018, 1, 1, 14, 5, 0, 0, time.UTC), diff.To.UTC())
+}
+
+func TestAPITopologyEdges(t *testing.T) {
+	ts := topologyServer()
+	defer ts.Close()
+	is400(t, ts, "/api/topology/hosts/edges?limit=many")
+
+	// The fixture has no connection counters, so no edge has a known throughput
+	body := getRawJSON(t, ts, "/api/topology/hosts/edges?limit=10")
+	var edges app.APITopologyEdges
+	decoder := codec.NewDecoderBytes(body, &codec.JsonHandle{})
+	if err := decoder.Decode(&edges); err != nil {
+		t.Fatalf("JSON parse error: %s", err)
+	}
+	equals(t, 0, len(edges.Edges))
+}
+
+func TestAPIEdge(t *testing.T) {
+	ts := topologyServer()
+	defer ts.Close()
+	is404(t, ts, "/api/topology/hosts/edge/"+url.QueryEscape(fixture.ServerHostNodeID)+"/"+url.QueryEscape(fixture.ClientHostNodeID))
+	is404(t, ts, "/api/topology/hosts/edge/"+url.QueryEscape(fixture.ClientHostNodeID)+"/foobar")
+
+	body := getRawJSON(t, ts, "/api/topology/hosts/edge/"+url.QueryEscape(fixture.ClientHostNodeID)+"/"+url.QueryEscape(fixture.ServerHostNodeID))
+	var edge app.APIEdge
+	decoder := codec.NewDecoderBytes(body, &codec.JsonHandle{})
+	if err := decoder.Decode(&edge); err != nil {
+		t.Fatalf("JSON parse error: %s", err)
+	}
+	equals(t, fixture.ClientHostNodeID, edge.Edge.Source.ID)
+	equals(t, fixture.ServerHostNodeID, edge.Edge.Target.ID)
+	equals(t, 2, edge.Edge.Count)
+	equals(t, 1, len(edge.Edge.Ports))
+	equals(t, fixture.ServerPort, edge.Edge.Ports[0].Port)
+}
+
+func TestAPITopologyExport(t *testing.T) {
+	ts := topologyServer()
+	defer ts.Close()
+	is400(t, ts, "/api/topology/hosts?format=png")
+
+	edge := fmt.Sprintf("%q -> %q;", fixture.ClientHostNodeID, fixture.ServerHostNodeID)
+	dot := string(is200(t, ts, "/api/topology/hosts?format=dot"))
+	assert(t, strings.HasPrefix(dot, `digraph "hosts" {`), "unexpected DOT header: %s", dot)
+	assert(t, strings.Contains(dot, edge), "expected DOT edge %s in %s", edge, dot)
+	assert(t, strings.Contains(dot, "shape=circle"), "expected host shape in %s", dot)
+
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	ok(t, xml.Unmarshal(is200(t, ts, "/api/topology/hosts?format=graphml"), &doc))
+	equals(t, len(expected.RenderedHosts), len(doc.Graph.Nodes))
+	assert(t, len(doc.Graph.Edges) > 0, "expected GraphML edges")
+
+	mermaid := string(is200(t, ts, "/api/topology/hosts?format=mermaid"))
+	assert(t, strings.Contains(mermaid, "graph LR\n"), "unexpected Mermaid output: %s", mermaid)
+	assert(t, strings.Contains(mermaid, `(("client<br/>`), "expected host shape in %s", mermaid)
+}
+
 // Basic websocket test
 func TestAPITopologyWebsocket(t *testing.T) {
 	ts := topologyServer()
@@ -0,0 +1,499 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/syslog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/scope/common/xfer"
+)
+
+// Types of audit records
+const (
+	AuditControl   = "control"
+	AuditPipeOpen  = "pipe_open"
+	AuditPipeClose = "pipe_close"
+)
+
+// Outcomes of audited controls
+const (
+	AuditOK       = "ok"
+	AuditRejected = "rejected"
+	AuditFailed   = "failed"
+)
+
+const (
+	auditQueueSize    = 1024
+	defaultAuditLimit = 100
+	webhookTimeout    = 5 * time.Second
+	// auditPipeTTL is how long the control which opened a pipe is
+	// remembered after the pipe was last opened: by then the pipe router
+	// has timed the pipe out and forgotten it, unless it's still in use.
+	auditPipeTTL = pipeTimeout + gcTimeout
+)
+
+var auditRecordsDropped = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "scope",
+	Name:      "audit_records_dropped_total",
+	Help:      "Total count of audit records not written to the sinks, because they fell behind.",
+})
+
+func init() {
+	prometheus.MustRegister(auditRecordsDropped)
+}
+
+// AuditRecord records who ran which control on which node, or used which
+// pipe, and how it went.
+type AuditRecord struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Tenant  string    `json:"tenant,omitempty"`
+	User    string    `json:"user,omitempty"`
+	ProbeID string    `json:"probe_id,omitempty"`
+	NodeID  string    `json:"node_id,omitempty"`
+	// Namespace is the Kubernetes namespace of the node, if any
+	Namespace string            `json:"namespace,omitempty"`
+	Control   string            `json:"control,omitempty"`
+	Args      map[string]string `json:"args,omitempty"`
+	Outcome   string            `json:"outcome,omitempty"`
+	Error     string            `json:"error,omitempty"`
+	// LatencyMS is how long the control took to run
+	LatencyMS int64 `json:"latency_ms,omitempty"`
+
+	// Pipe specific fields. BytesIn are sent from the UI to the probe,
+	// BytesOut from the probe to the UI.
+	PipeID   string `json:"pipe_id,omitempty"`
+	BytesIn  int64  `json:"bytes_in,omitempty"`
+	BytesOut int64  `json:"bytes_out,omitempty"`
+}
+
+// AuditSink durably stores audit records
+type AuditSink interface {
+	Write(AuditRecord) error
+	Close() error
+}
+
+// AuditQuery selects audit records. Empty fields match all records, but
+// for the tenant, which always has to match.
+type AuditQuery struct {
+	Tenant  string
+	User    string
+	NodeID  string
+	Control string
+	Type    string
+	Since   time.Time
+	Limit   int
+
+	// namespaceVisible, if set, selects the records by their namespace
+	namespaceVisible func(string) bool
+}
+
+func (q AuditQuery) matches(rec AuditRecord) bool {
+	return rec.Tenant == q.Tenant &&
+		(q.User == "" || rec.User == q.User) &&
+		(q.NodeID == "" || rec.NodeID == q.NodeID) &&
+		(q.Control == "" || rec.Control == q.Control) &&
+		(q.Type == "" || rec.Type == q.Type) &&
+		!rec.Time.Before(q.Since) &&
+		(q.namespaceVisible == nil || q.namespaceVisible(rec.Namespace))
+}
+
+// AuditLog records control and pipe invocations. The latest records are
+// kept in memory to be queried, and all of them are written to the sinks
+// in the background.
+type AuditLog struct {
+	tenantIDer func(context.Context) (string, error)
+	sinks      []AuditSink
+	queue      chan AuditRecord
+	done       chan struct{}
+
+	// queueMtx is held for reading while records are queued, and for
+	// writing to close the queue.
+	queueMtx sync.RWMutex
+	closed   bool
+
+	mtx     sync.Mutex
+	records []AuditRecord // ring buffer
+	next    int
+	full    bool
+	pipes   map[string]auditPipe // controls which opened pipes, by pipe ID
+}
+
+// auditPipe is the control which opened a pipe, and when the pipe was last
+// opened.
+type auditPipe struct {
+	opener AuditRecord
+	opened time.Time
+}
+
+// NewAuditLog makes a new AuditLog keeping the latest capacity records in
+// memory. Users are identified by their identity, and the tenants records
+// belong to by tenantIDer, if given.
+func NewAuditLog(capacity int, tenantIDer func(context.Context) (string, error), sinks ...AuditSink) *AuditLog {
+	if capacity < 1 {
+		capacity = 1
+	}
+	a := &AuditLog{
+		tenantIDer: tenantIDer,
+		sinks:      sinks,
+		queue:      make(chan AuditRecord, auditQueueSize),
+		done:       make(chan struct{}),
+		records:    make([]AuditRecord, capacity),
+		pipes:      map[string]auditPipe{},
+	}
+	go a.loop()
+	return a
+}
+
+func (a *AuditLog) loop() {
+	defer close(a.done)
+	for rec := range a.queue {
+		for _, sink := range a.sinks {
+			if err := sink.Write(rec); err != nil {
+				log.Errorf("Error writing audit record: %v", err)
+			}
+		}
+	}
+}
+
+// Close writes the pending records to the sinks and closes them.
+func (a *AuditLog) Close() {
+	a.queueMtx.Lock()
+	a.closed = true
+	close(a.queue)
+	a.queueMtx.Unlock()
+	<-a.done
+	for _, sink := range a.sinks {
+		if err := sink.Close(); err != nil {
+			log.Errorf("Error closing audit sink: %v", err)
+		}
+	}
+}
+
+// Record adds a record to the log, identifying the user and tenant of the
+// request in ctx. When the sinks fall behind, the record is only kept in
+// memory rather than holding up the request, and counted as dropped.
+func (a *AuditLog) Record(ctx context.Context, rec AuditRecord) {
+	if rec.Time.IsZero() {
+		rec.Time = time.Now()
+	}
+	if rec.User == "" {
+		rec.User = a.user(ctx)
+	}
+	if rec.Tenant == "" {
+		rec.Tenant = a.tenant(ctx)
+	}
+
+	a.mtx.Lock()
+	a.records[a.next] = rec
+	a.next = (a.next + 1) % len(a.records)
+	if a.next == 0 {
+		a.full = true
+	}
+	a.mtx.Unlock()
+
+	a.queueMtx.RLock()
+	defer a.queueMtx.RUnlock()
+	if a.closed {
+		return
+	}
+	select {
+	case a.queue <- rec:
+	default:
+		auditRecordsDropped.Inc()
+		log.Errorf("Audit sinks too slow, not writing record of %s %s by %q", rec.Type, rec.Control, rec.User)
+	}
+}
+
+func (a *AuditLog) user(ctx context.Context) string {
+	if id, ok := IdentityFromContext(ctx); ok {
+		return id.User
+	}
+	return ""
+}
+
+func (a *AuditLog) tenant(ctx context.Context) string {
+	if a.tenantIDer != nil {
+		if tenantID, err := a.tenantIDer(ctx); err == nil {
+			return tenantID
+		}
+	}
+	return ""
+}
+
+// RecordControl records the outcome of a control request on a node in a
+// namespace. Pipes opened by the control are remembered, so that their
+// records say where they came from.
+func (a *AuditLog) RecordControl(ctx context.Context, probeID, namespace string, req xfer.Request, res xfer.Response, outcome string, latency time.Duration) {
+	rec := AuditRecord{
+		Type:      AuditControl,
+		Tenant:    a.tenant(ctx),
+		User:      a.user(ctx),
+		ProbeID:   probeID,
+		NodeID:    req.NodeID,
+		Namespace: namespace,
+		Control:   req.Control,
+		Args:      req.ControlArgs,
+		Outcome:   outcome,
+		Error:     res.Error,
+		LatencyMS: int64(latency / time.Millisecond),
+		PipeID:    res.Pipe,
+	}
+	if res.Pipe != "" {
+		now := time.Now()
+		a.mtx.Lock()
+		// Pipes which are never closed would be remembered forever
+		for id, p := range a.pipes {
+			if now.Sub(p.opened) >= auditPipeTTL {
+				delete(a.pipes, id)
+			}
+		}
+		a.pipes[res.Pipe] = auditPipe{opener: rec, opened: now}
+		a.mtx.Unlock()
+	}
+	a.Record(ctx, rec)
+}
+
+// RecordPipe records the opening or closing of a pipe by the UI.
+func (a *AuditLog) RecordPipe(ctx context.Context, recordType, pipeID string, bytesIn, bytesOut int64) {
+	a.mtx.Lock()
+	pipe, ok := a.pipes[pipeID]
+	switch {
+	case recordType == AuditPipeClose:
+		delete(a.pipes, pipeID)
+	case ok:
+		pipe.opened = time.Now()
+		a.pipes[pipeID] = pipe
+	}
+	a.mtx.Unlock()
+
+	rec := AuditRecord{
+		Type:     recordType,
+		PipeID:   pipeID,
+		BytesIn:  bytesIn,
+		BytesOut: bytesOut,
+	}
+	if ok {
+		opener := pipe.opener
+		rec.ProbeID, rec.NodeID, rec.Namespace, rec.Control = opener.ProbeID, opener.NodeID, opener.Namespace, opener.Control
+	}
+	a.Record(ctx, rec)
+}
+
+// Query returns the records in memory matching q, newest first.
+func (a *AuditLog) Query(q AuditQuery) []AuditRecord {
+	if q.Limit <= 0 {
+		q.Limit = defaultAuditLimit
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	n := a.next
+	if a.full {
+		n = len(a.records)
+	}
+	result := []AuditRecord{}
+	for i := 1; i <= n && len(result) < q.Limit; i++ {
+		rec := a.records[(a.next-i+len(a.records))%len(a.records)]
+		if q.matches(rec) {
+			result = append(result, rec)
+		}
+	}
+	return result
+}
+
+// RegisterAuditRoutes registers the route to query the audit log. Only
+// admins may query it, and only for the records of their tenant on the
+// nodes of the namespaces they can see.
+func RegisterAuditRoutes(router *mux.Router, a *AuditLog) {
+	router.Methods("GET").
+		Name("api_audit").
+		Path("/api/audit").
+		HandlerFunc(requestContextDecorator(handleAudit(a)))
+}
+
+func handleAudit(a *AuditLog) CtxHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+		if !AccessFromContext(ctx).IsAdmin() {
+			respondWith(w, http.StatusForbidden, "not allowed to read the audit log")
+			return
+		}
+		values := r.URL.Query()
+		q := AuditQuery{
+			Tenant:  a.tenant(ctx),
+			User:    values.Get("user"),
+			NodeID:  values.Get("node"),
+			Control: values.Get("control"),
+			Type:    values.Get("type"),
+		}
+		if since := values.Get("since"); since != "" {
+			t, err := time.Parse(time.RFC3339, since)
+			if err != nil {
+				respondWith(w, http.StatusBadRequest, err)
+				return
+			}
+			q.Since = t
+		}
+		if limit := values.Get("limit"); limit != "" {
+			l, err := strconv.Atoi(limit)
+			if err != nil {
+				respondWith(w, http.StatusBadRequest, err)
+				return
+			}
+			q.Limit = l
+		}
+		access := AccessFromContext(ctx)
+		q.namespaceVisible = func(namespace string) bool {
+			return namespace == "" || access.CanSeeNamespace(namespace)
+		}
+		respondWith(w, http.StatusOK, struct {
+			Records []AuditRecord `json:"records"`
+		}{a.Query(q)})
+	}
+}
+
+// countingReadWriter counts the bytes read from and written to an end of
+// a pipe.
+type countingReadWriter struct {
+	io.ReadWriter
+	read, written int64
+}
+
+func (c *countingReadWriter) Read(p []byte) (int, error) {
+	n, err := c.ReadWriter.Read(p)
+	atomic.AddInt64(&c.read, int64(n))
+	return n, err
+}
+
+func (c *countingReadWriter) Write(p []byte) (int, error) {
+	n, err := c.ReadWriter.Write(p)
+	atomic.AddInt64(&c.written, int64(n))
+	return n, err
+}
+
+// NewAuditSink makes a sink from a URL. file:///path/to/audit.jsonl appends
+// JSON lines to a file, syslog:// writes to the local syslog, syslog://host:port
+// or syslog+tcp://host:port to a remote one, and http(s) URLs are webhooks
+// each record is posted to as JSON.
+func NewAuditSink(sinkURL string) (AuditSink, error) {
+	u, err := url.Parse(sinkURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "file":
+		return NewFileAuditSink(u.Path)
+	case "syslog", "syslog+udp", "syslog+tcp":
+		return NewSyslogAuditSink(u)
+	case "http", "https":
+		return NewWebhookAuditSink(sinkURL), nil
+	}
+	return nil, fmt.Errorf("Invalid audit sink '%s'", sinkURL)
+}
+
+type fileAuditSink struct {
+	mtx sync.Mutex
+	f   *os.File
+}
+
+// NewFileAuditSink makes a sink appending records to a file, as JSON lines.
+func NewFileAuditSink(path string) (AuditSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &fileAuditSink{f: f}, nil
+}
+
+func (s *fileAuditSink) Write(rec AuditRecord) error {
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	_, err = s.f.Write(append(buf, '\n'))
+	return err
+}
+
+func (s *fileAuditSink) Close() error {
+	return s.f.Close()
+}
+
+type syslogAuditSink struct {
+	w *syslog.Writer
+}
+
+// NewSyslogAuditSink makes a sink writing records to syslog, as JSON.
+func NewSyslogAuditSink(u *url.URL) (AuditSink, error) {
+	network := ""
+	switch {
+	case u.Scheme == "syslog+tcp":
+		network = "tcp"
+	case u.Host != "":
+		network = "udp"
+	}
+	w, err := syslog.Dial(network, u.Host, syslog.LOG_NOTICE|syslog.LOG_AUTH, "scope-audit")
+	if err != nil {
+		return nil, err
+	}
+	return &syslogAuditSink{w: w}, nil
+}
+
+func (s *syslogAuditSink) Write(rec AuditRecord) error {
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.w.Notice(string(buf))
+}
+
+func (s *syslogAuditSink) Close() error {
+	return s.w.Close()
+}
+
+type webhookAuditSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookAuditSink makes a sink posting each record as JSON to a URL.
+func NewWebhookAuditSink(url string) AuditSink {
+	return &webhookAuditSink{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (s *webhookAuditSink) Write(rec AuditRecord) error {
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("audit webhook %s returned %s", s.url, resp.Status)
+	}
+	return nil
+}
+
+func (s *webhookAuditSink) Close() error {
+	return nil
+}
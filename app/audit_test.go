@@ -0,0 +1,143 @@
+package app_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/ugorji/go/codec"
+
+	"github.com/weaveworks/scope/app"
+	"github.com/weaveworks/scope/common/xfer"
+	"github.com/weaveworks/scope/report"
+)
+
+func TestAuditControls(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.jsonl")
+	sink, err := app.NewAuditSink("file://" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tenantIDer := func(ctx context.Context) (string, error) {
+		return ctx.Value(app.RequestCtxKey).(*http.Request).Header.Get("X-Scope-OrgID"), nil
+	}
+	audit := app.NewAuditLog(10, tenantIDer, sink)
+	policy, err := app.ParsePolicy([]byte(`
+roles:
+- name: admin
+  controls: ["*"]
+  namespaces: ["*"]
+  admin: true
+- name: ops
+  controls: ["*"]
+  namespaces: ["*"]
+bindings:
+- role: admin
+  users: ["alice"]
+- role: ops
+  users: ["bob", "carol"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cr := app.NewLocalControlRouter()
+	cr.Register(context.Background(), "probe", func(req xfer.Request) xfer.Response {
+		if req.Control == "fail" {
+			return xfer.ResponseErrorf("no can do")
+		}
+		return xfer.Response{Pipe: "pipe"}
+	})
+	router := mux.NewRouter()
+	// Arguments are only passed to controls defined in the reports
+	rpt := report.MakeReport()
+	rpt.Container.AddNode(report.MakeNode("node"))
+	rpt.Container.Controls.AddControl(report.Control{
+		ID:     "exec",
+		Params: []report.ControlParam{{Name: "command", Type: report.ControlParamString}},
+	})
+	app.RegisterControlRoutes(router, cr, app.StaticCollector(rpt), audit)
+	app.RegisterAuditRoutes(router, audit)
+	server := httptest.NewServer(app.RBAC{Policy: policy, UserHeader: "X-Scope-User"}.Wrap(router))
+	defer server.Close()
+
+	do := func(method, path, body, user, tenant string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Scope-User", user)
+		req.Header.Set("X-Scope-OrgID", tenant)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	do("POST", "/api/control/probe/node/exec", `{"command": "sh"}`, "alice", "acme").Body.Close()
+	do("POST", "/api/control/probe/node/fail", "", "bob", "acme").Body.Close()
+	do("POST", "/api/control/probe/node/exec", "", "carol", "other").Body.Close()
+
+	resp := do("GET", "/api/audit", "", "bob", "acme")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected the audit log to be forbidden to non-admins, got %d", resp.StatusCode)
+	}
+	resp = do("GET", "/api/audit?node=node", "", "alice", "acme")
+	defer resp.Body.Close()
+	var result struct {
+		Records []app.AuditRecord `json:"records"`
+	}
+	if err := codec.NewDecoder(resp.Body, &codec.JsonHandle{}).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	// Only the records of the tenant
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %v", result.Records)
+	}
+	// Newest first
+	failed, ok := result.Records[0], result.Records[1]
+	if failed.User != "bob" || failed.Control != "fail" || failed.Outcome != app.AuditFailed || failed.Error != "no can do" {
+		t.Errorf("Unexpected record of failed control: %+v", failed)
+	}
+	if ok.User != "alice" || ok.Tenant != "acme" || ok.Control != "exec" || ok.Outcome != app.AuditOK || ok.Args["command"] != "sh" || ok.PipeID != "pipe" {
+		t.Errorf("Unexpected record of control: %+v", ok)
+	}
+
+	if records := audit.Query(app.AuditQuery{Tenant: "acme", User: "alice"}); len(records) != 1 {
+		t.Errorf("Expected 1 record of alice, got %v", records)
+	}
+
+	// Closing the log flushes the records to the sinks
+	audit.Close()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var controls []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec app.AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		controls = append(controls, rec.User+":"+rec.Control)
+	}
+	if want := "alice:exec bob:fail carol:exec"; strings.Join(controls, " ") != want {
+		t.Errorf("Expected %q in the audit file, got %q", want, strings.Join(controls, " "))
+	}
+}
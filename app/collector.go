@@ -154,6 +154,14 @@ func (c *collector) Report(_ context.Context, timestamp time.Time) (report.Repor
 	return rpt, nil
 }
 
+// LookupControl finds the definition of a control of a node in the added
+// reports, without merging them. It implements ControlLookup.
+func (c *collector) LookupControl(_ context.Context, nodeID, controlID string) (report.Control, report.Node, bool) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return findControl(c.reports, nodeID, controlID)
+}
+
 // HasReports indicates whether the collector contains reports between
 // timestamp-app.window and timestamp.
 func (c *collector) HasReports(ctx context.Context, timestamp time.Time) (bool, error) {
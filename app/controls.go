@@ -1,8 +1,10 @@
 package app
 
 import (
+	"fmt"
 	"net/http"
 	"net/rpc"
+	"time"
 
 	"context"
 	"github.com/gorilla/mux"
@@ -10,10 +12,14 @@ import (
 	"github.com/ugorji/go/codec"
 
 	"github.com/weaveworks/scope/common/xfer"
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
 )
 
 // RegisterControlRoutes registers the various control routes with a http mux.
-func RegisterControlRoutes(router *mux.Router, cr ControlRouter) {
+// The arguments of control requests are validated against the params of the
+// controls in the reports of rep, and recorded in audit.
+func RegisterControlRoutes(router *mux.Router, cr ControlRouter, rep Reporter, audit *AuditLog) {
 	router.
 		Methods("GET").
 		Path("/api/control/ws").
@@ -22,12 +28,12 @@ func RegisterControlRoutes(router *mux.Router, cr ControlRouter) {
 		Methods("POST").
 		Name("api_control_probeid_nodeid_control").
 		MatcherFunc(URLMatcher("/api/control/{probeID}/{nodeID}/{control}")).
-		HandlerFunc(requestContextDecorator(handleControl(cr)))
+		HandlerFunc(requestContextDecorator(handleControl(cr, rep, audit)))
 }
 
 // handleControl routes control requests from the client to the appropriate
 // probe.  Its is blocking.
-func handleControl(cr ControlRouter) CtxHandlerFunc {
+func handleControl(cr ControlRouter, rep Reporter, audit *AuditLog) CtxHandlerFunc {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 		var (
 			vars        = mux.Vars(r)
@@ -46,23 +52,105 @@ func handleControl(cr ControlRouter) CtxHandlerFunc {
 			}
 		}
 
-		result, err := cr.Handle(ctx, probeID, xfer.Request{
+		req := xfer.Request{
 			NodeID:      nodeID,
 			Control:     control,
 			ControlArgs: controlArgs,
-		})
+		}
+		c, node, ok := lookupControl(ctx, rep, nodeID, control)
+		namespace, _ := render.KubernetesNamespaceOf(node)
+		if !AccessFromContext(ctx).AllowNodeControl(node, control) {
+			audit.RecordControl(ctx, probeID, namespace, req, xfer.ResponseErrorf("forbidden"), AuditRejected, 0)
+			respondWith(w, http.StatusForbidden, fmt.Sprintf("not allowed to use %s on %s", control, nodeID))
+			return
+		}
+		if !ok && len(controlArgs) > 0 {
+			// Arguments can't be validated without the definition of the
+			// control, e.g. for nodes not yet in the latest reports.
+			err := fmt.Errorf("unknown control %s on %s", control, nodeID)
+			audit.RecordControl(ctx, probeID, namespace, req, xfer.ResponseError(err), AuditRejected, 0)
+			respondWith(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if ok {
+			args, err := c.ValidateArgs(controlArgs)
+			if err != nil {
+				audit.RecordControl(ctx, probeID, namespace, req, xfer.ResponseError(err), AuditRejected, 0)
+				respondWith(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			req.ControlArgs = args
+		}
+
+		start := time.Now()
+		result, err := cr.Handle(ctx, probeID, req)
 		if err != nil {
+			audit.RecordControl(ctx, probeID, namespace, req, xfer.ResponseError(err), AuditFailed, time.Since(start))
 			respondWith(w, http.StatusBadRequest, err.Error())
 			return
 		}
 		if result.Error != "" {
+			audit.RecordControl(ctx, probeID, namespace, req, result, AuditFailed, time.Since(start))
 			respondWith(w, http.StatusBadRequest, result.Error)
 			return
 		}
+		audit.RecordControl(ctx, probeID, namespace, req, result, AuditOK, time.Since(start))
 		respondWith(w, http.StatusOK, result)
 	}
 }
 
+// ControlLookup is implemented by Reporters which can find the definition
+// of a control of a node without merging a full report.
+type ControlLookup interface {
+	LookupControl(ctx context.Context, nodeID, controlID string) (report.Control, report.Node, bool)
+}
+
+// lookupControl finds the definition of a control of a node in the latest
+// reports, preferring that of the topology of the node, which it returns
+// too. Controls which aren't found are only routed to the probe without
+// arguments.
+func lookupControl(ctx context.Context, rep Reporter, nodeID, controlID string) (report.Control, report.Node, bool) {
+	if l, ok := rep.(ControlLookup); ok {
+		return l.LookupControl(ctx, nodeID, controlID)
+	}
+	rpt, err := rep.Report(ctx, time.Now())
+	if err != nil {
+		log.Warnf("Error getting report to validate control %s: %v", controlID, err)
+		return report.Control{}, report.Node{}, false
+	}
+	return findControl([]report.Report{rpt}, nodeID, controlID)
+}
+
+// findControl finds the definition of a control of a node in the reports,
+// newest last, as lookupControl.
+func findControl(reports []report.Report, nodeID, controlID string) (report.Control, report.Node, bool) {
+	var (
+		result report.Control
+		found  bool
+	)
+	for i := len(reports) - 1; i >= 0; i-- {
+		var (
+			node      report.Node
+			foundNode bool
+		)
+		reports[i].WalkTopologies(func(t *report.Topology) {
+			c, ok := t.Controls[controlID]
+			if !ok || foundNode {
+				return
+			}
+			if n, ok := t.Nodes[nodeID]; ok {
+				result, node, found, foundNode = c, n, true, true
+			} else if !found {
+				result, found = c, true
+			}
+		})
+		if foundNode {
+			return result, node, true
+		}
+	}
+	return result, report.Node{}, found
+}
+
 // handleProbeWS accepts websocket connections from the probe and registers
 // them in the control router, such that HandleControl calls can find them.
 func handleProbeWS(cr ControlRouter) CtxHandlerFunc {
@@ -1,10 +1,12 @@
 package app_test
 
 import (
+	"context"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -15,11 +17,12 @@ import (
 	"github.com/weaveworks/scope/app"
 	"github.com/weaveworks/scope/common/xfer"
 	"github.com/weaveworks/scope/probe/appclient"
+	"github.com/weaveworks/scope/report"
 )
 
 func TestControl(t *testing.T) {
 	router := mux.NewRouter()
-	app.RegisterControlRoutes(router, app.NewLocalControlRouter())
+	app.RegisterControlRoutes(router, app.NewLocalControlRouter(), app.StaticCollector(report.MakeReport()), app.NewAuditLog(10, nil))
 	server := httptest.NewServer(router)
 	defer server.Close()
 
@@ -77,3 +80,98 @@ func TestControl(t *testing.T) {
 		t.Fatalf("'%s' != 'foo'", response.Value)
 	}
 }
+
+func TestControlArgsValidation(t *testing.T) {
+	minReplicas := int64(0)
+	rpt := report.MakeReport()
+	rpt.Deployment.AddNode(report.MakeNode("nodeid"))
+	rpt.Deployment.Controls.AddControl(report.Control{
+		ID: "scale",
+		Params: []report.ControlParam{
+			{Name: "replicas", Type: report.ControlParamInt, Required: true, Min: &minReplicas},
+			{Name: "strategy", Type: report.ControlParamEnum, Options: []string{"fast", "slow"}, Default: "slow"},
+		},
+	})
+
+	cr := app.NewLocalControlRouter()
+	var received map[string]string
+	cr.Register(context.Background(), "foo", func(req xfer.Request) xfer.Response {
+		received = req.ControlArgs
+		return xfer.Response{}
+	})
+	router := mux.NewRouter()
+	app.RegisterControlRoutes(router, cr, app.StaticCollector(rpt), app.NewAuditLog(10, nil))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		body   string
+		status int
+		args   map[string]string
+	}{
+		{`{"replicas": "3"}`, http.StatusOK, map[string]string{"replicas": "3", "strategy": "slow"}},
+		{`{"replicas": "03", "strategy": "fast"}`, http.StatusOK, map[string]string{"replicas": "3", "strategy": "fast"}},
+		{`{}`, http.StatusBadRequest, nil},
+		{`{"replicas": "-1"}`, http.StatusBadRequest, nil},
+		{`{"replicas": "three"}`, http.StatusBadRequest, nil},
+		{`{"replicas": "3", "strategy": "reckless"}`, http.StatusBadRequest, nil},
+		{`{"replicas": "3", "force": "true"}`, http.StatusBadRequest, nil},
+	} {
+		received = nil
+		resp, err := http.Post(server.URL+"/api/control/foo/nodeid/scale", "application/json", strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.body, tc.status, resp.StatusCode)
+		}
+		if !reflect.DeepEqual(received, tc.args) {
+			t.Errorf("%s: expected the probe to receive %v, got %v", tc.body, tc.args, received)
+		}
+	}
+}
+
+func TestControlArgsValidationFromCollector(t *testing.T) {
+	rpt := report.MakeReport()
+	rpt.Deployment.AddNode(report.MakeNode("nodeid"))
+	rpt.Deployment.Controls.AddControl(report.Control{
+		ID: "scale",
+		Params: []report.ControlParam{
+			{Name: "replicas", Type: report.ControlParamInt, Required: true},
+		},
+	})
+	collector := app.NewCollector(time.Minute)
+	collector.Add(context.Background(), report.MakeReport(), nil)
+	collector.Add(context.Background(), rpt, nil)
+
+	cr := app.NewLocalControlRouter()
+	cr.Register(context.Background(), "foo", func(req xfer.Request) xfer.Response {
+		return xfer.Response{}
+	})
+	router := mux.NewRouter()
+	app.RegisterControlRoutes(router, cr, collector, app.NewAuditLog(10, nil))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		control, body string
+		status        int
+	}{
+		{"scale", `{"replicas": "3"}`, http.StatusOK},
+		{"scale", `{}`, http.StatusBadRequest},
+		// without its definition, the arguments of a control can't be
+		// validated
+		{"unknown", `{"replicas": "3"}`, http.StatusBadRequest},
+		{"unknown", `{}`, http.StatusOK},
+	} {
+		resp, err := http.Post(server.URL+"/api/control/foo/nodeid/"+tc.control, "application/json", strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s %s: expected status %d, got %d", tc.control, tc.body, tc.status, resp.StatusCode)
+		}
+	}
+}
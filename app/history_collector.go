@@ -0,0 +1,367 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	log "github.com/sirupsen/logrus"
+	"github.com/weaveworks/common/mtime"
+
+	"github.com/weaveworks/scope/report"
+)
+
+const (
+	historyFileExt       = ".msgpack.gz"
+	historyTmpPrefix     = "."
+	historyReportsCached = 32
+)
+
+// HistoryConfig describes how a history collector stores reports on disk.
+type HistoryConfig struct {
+	// Path is the directory the reports are written to.
+	Path string
+	// Retention is how long reports are kept before they are deleted.
+	Retention time.Duration
+	// Resolution is the downsampling policy: all reports received within
+	// one Resolution are merged and stored as a single file.
+	Resolution time.Duration
+}
+
+// historyCollector is a Collector that keeps the reports of the last
+// window in memory, like the local collector, and additionally persists
+// downsampled reports to disk so that past timestamps can be queried.
+type historyCollector struct {
+	Collector // live reports, inside the window
+
+	cfg    HistoryConfig
+	window time.Duration
+	merger Merger
+	cache  gcache.Cache
+
+	mtx   sync.Mutex
+	index historyIndex
+	// pending is the bucket being filled, merged as reports arrive so that
+	// only one report per bucket is held in memory.
+	pending      *report.Report
+	pendingCount int
+	pendingStart time.Time
+	// flushing is the bucket being written to disk, which is served from
+	// memory until it is indexed.
+	flushing      *report.Report
+	flushingStart time.Time
+}
+
+// NewHistoryCollector returns a collector which stores a downsampled history
+// of the reports it receives in cfg.Path, loading any reports already there.
+func NewHistoryCollector(cfg HistoryConfig, window time.Duration) (Collector, error) {
+	if cfg.Resolution <= 0 {
+		return nil, fmt.Errorf("history resolution must be positive, got %v", cfg.Resolution)
+	}
+	if err := os.MkdirAll(cfg.Path, 0755); err != nil {
+		return nil, err
+	}
+	index, err := loadHistoryIndex(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	c := &historyCollector{
+		Collector: NewCollector(window),
+		cfg:       cfg,
+		window:    window,
+		merger:    NewFastMerger(),
+		cache:     gcache.New(historyReportsCached).LRU().Build(),
+		index:     index,
+	}
+	c.prune(mtime.Now())
+	return c, nil
+}
+
+// Add adds a report to the live collector, and merges it into the pending
+// downsampling bucket. Once a bucket spans the configured resolution it is
+// handed off to be written to disk in the background, so that the probe
+// posting the report doesn't wait on the disk. It implements Adder.
+func (c *historyCollector) Add(ctx context.Context, rpt report.Report, buf []byte) error {
+	if err := c.Collector.Add(ctx, rpt, buf); err != nil {
+		return err
+	}
+
+	c.mtx.Lock()
+	now := mtime.Now()
+	// While a bucket is still being written, the next one keeps growing
+	// rather than queueing up writes.
+	flush := c.pending != nil && now.Sub(c.pendingStart) >= c.cfg.Resolution && c.flushing == nil
+	if flush {
+		c.flushing, c.flushingStart = c.pending, c.pendingStart
+		c.pending = nil
+	}
+	if c.pending == nil {
+		// Merge into a fresh report: the live collector holds on to rpt.
+		pending := report.MakeReport()
+		c.pending, c.pendingCount, c.pendingStart = &pending, 0, now
+	}
+	c.pending.UnsafeMerge(rpt.Upgrade())
+	c.pendingCount++
+	c.mtx.Unlock()
+
+	if flush {
+		go func() {
+			if err := c.flush(); err != nil {
+				log.Errorf("Error storing historic report: %v", err)
+			}
+			c.prune(now)
+		}()
+	}
+	return nil
+}
+
+// Report returns a merged report over the window ending at timestamp. Recent
+// timestamps are served from memory, older ones from disk. It implements
+// Reporter.
+func (c *historyCollector) Report(ctx context.Context, timestamp time.Time) (report.Report, error) {
+	if c.isLive(timestamp) {
+		return c.Collector.Report(ctx, timestamp)
+	}
+	reports, err := c.reportsAt(ctx, timestamp)
+	if err != nil {
+		return report.MakeReport(), err
+	}
+	return c.merger.Merge(reports), nil
+}
+
+// LookupControl looks the control up in the live collector. It implements
+// ControlLookup.
+func (c *historyCollector) LookupControl(ctx context.Context, nodeID, controlID string) (report.Control, report.Node, bool) {
+	if l, ok := c.Collector.(ControlLookup); ok {
+		return l.LookupControl(ctx, nodeID, controlID)
+	}
+	return lookupControl(ctx, c.Collector, nodeID, controlID)
+}
+
+// HasReports indicates whether the collector contains reports between
+// timestamp-app.window and timestamp.
+func (c *historyCollector) HasReports(ctx context.Context, timestamp time.Time) (bool, error) {
+	if c.isLive(timestamp) {
+		return c.Collector.HasReports(ctx, timestamp)
+	}
+	start, end := c.bucketRange(timestamp)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.hasInMemory(start, end) {
+		return true, nil
+	}
+	return len(c.index.between(start, end)) > 0, nil
+}
+
+// HasHistoricReports indicates whether the collector contains reports
+// older than now-app.window.
+func (c *historyCollector) HasHistoricReports() bool {
+	return true
+}
+
+// AdminSummary returns a string with some internal information about
+// the report, which may be useful to troubleshoot.
+func (c *historyCollector) AdminSummary(ctx context.Context, timestamp time.Time) (string, error) {
+	summary, err := c.Collector.AdminSummary(ctx, timestamp)
+	if err != nil {
+		return "", err
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	var b strings.Builder
+	b.WriteString(summary)
+	fmt.Fprintf(&b, "history: %d stored reports in %s", len(c.index), c.cfg.Path)
+	if len(c.index) > 0 {
+		fmt.Fprintf(&b, " from %v to %v",
+			c.index[0].Format(time.StampMilli), c.index[len(c.index)-1].Format(time.StampMilli))
+	}
+	pending := c.pendingCount
+	if c.flushing != nil {
+		pending++
+	}
+	fmt.Fprintf(&b, ", %d pending\n", pending)
+	return b.String(), nil
+}
+
+// isLive tells whether the in-memory collector covers timestamp.
+func (c *historyCollector) isLive(timestamp time.Time) bool {
+	return !timestamp.Before(mtime.Now().Add(-c.window))
+}
+
+// bucketRange returns the range of bucket start times which overlap the
+// window ending at timestamp.
+func (c *historyCollector) bucketRange(timestamp time.Time) (time.Time, time.Time) {
+	return timestamp.Add(-c.window - c.cfg.Resolution), timestamp
+}
+
+func (c *historyCollector) reportsAt(ctx context.Context, timestamp time.Time) ([]report.Report, error) {
+	start, end := c.bucketRange(timestamp)
+	c.mtx.Lock()
+	timestamps := c.index.between(start, end)
+	pending := c.inMemory(start, end)
+	c.mtx.Unlock()
+
+	reports := make([]report.Report, 0, len(timestamps)+len(pending))
+	for _, ts := range timestamps {
+		rpt, err := c.load(ctx, ts)
+		if os.IsNotExist(err) {
+			// pruned since we looked at the index
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		reports = append(reports, rpt)
+	}
+	return append(reports, pending...), nil
+}
+
+// hasInMemory tells whether a bucket not yet on disk starts in (start, end].
+// Must be called with c.mtx held.
+func (c *historyCollector) hasInMemory(start, end time.Time) bool {
+	return (c.flushing != nil && c.flushingStart.After(start) && !c.flushingStart.After(end)) ||
+		(c.pending != nil && c.pendingStart.After(start) && !c.pendingStart.After(end))
+}
+
+// inMemory returns copies of the buckets not yet on disk which start in
+// (start, end]. The pending bucket keeps being merged into, hence the copies.
+// Must be called with c.mtx held.
+func (c *historyCollector) inMemory(start, end time.Time) []report.Report {
+	var reports []report.Report
+	if c.flushing != nil && c.flushingStart.After(start) && !c.flushingStart.After(end) {
+		reports = append(reports, *c.flushing)
+	}
+	if c.pending != nil && c.pendingStart.After(start) && !c.pendingStart.After(end) {
+		reports = append(reports, c.pending.Copy())
+	}
+	return reports
+}
+
+func (c *historyCollector) load(ctx context.Context, ts time.Time) (report.Report, error) {
+	if cached, err := c.cache.Get(ts.UnixNano()); err == nil {
+		return cached.(report.Report), nil
+	}
+	rpt, err := report.MakeFromFile(ctx, c.filename(ts))
+	if err != nil {
+		return report.MakeReport(), err
+	}
+	upgraded := rpt.Upgrade()
+	c.cache.Set(ts.UnixNano(), upgraded)
+	return upgraded, nil
+}
+
+func (c *historyCollector) filename(ts time.Time) string {
+	return filepath.Join(c.cfg.Path, fmt.Sprintf("%d%s", ts.UnixNano(), historyFileExt))
+}
+
+// flush writes the bucket being flushed to disk. The file is written under a
+// temporary name first so that a crash never leaves a truncated report
+// behind. Must be called without c.mtx held, so that readers aren't blocked
+// by the write.
+func (c *historyCollector) flush() error {
+	c.mtx.Lock()
+	rpt, ts := c.flushing, c.flushingStart
+	c.mtx.Unlock()
+
+	err := c.write(*rpt, ts)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.flushing = nil
+	if err != nil {
+		return err
+	}
+	c.index.add(ts)
+	return nil
+}
+
+func (c *historyCollector) write(rpt report.Report, ts time.Time) error {
+	path := c.filename(ts)
+	tmp := filepath.Join(c.cfg.Path, historyTmpPrefix+filepath.Base(path))
+	if err := rpt.WriteToFile(tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// prune deletes the reports older than the retention period. Must be called
+// without c.mtx held.
+func (c *historyCollector) prune(now time.Time) {
+	if c.cfg.Retention <= 0 {
+		return
+	}
+	c.mtx.Lock()
+	removed := c.index.removeBefore(now.Add(-c.cfg.Retention))
+	for _, ts := range removed {
+		c.cache.Remove(ts.UnixNano())
+	}
+	c.mtx.Unlock()
+
+	for _, ts := range removed {
+		if err := os.Remove(c.filename(ts)); err != nil && !os.IsNotExist(err) {
+			log.Warningf("Error removing historic report: %v", err)
+		}
+	}
+}
+
+// historyIndex is the sorted list of timestamps of the reports on disk.
+type historyIndex []time.Time
+
+func loadHistoryIndex(path string) (historyIndex, error) {
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	index := historyIndex{}
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, historyFileExt) {
+			continue
+		}
+		if strings.HasPrefix(name, historyTmpPrefix) {
+			// left over from an interrupted flush
+			os.Remove(filepath.Join(path, name))
+			continue
+		}
+		ts, err := timestampFromFilepath(name)
+		if err != nil {
+			log.Warningf("Ignoring file in history directory: %v", err)
+			continue
+		}
+		index = append(index, ts)
+	}
+	sort.Slice(index, func(i, j int) bool { return index[i].Before(index[j]) })
+	return index, nil
+}
+
+func (idx *historyIndex) add(ts time.Time) {
+	i := sort.Search(len(*idx), func(i int) bool { return !(*idx)[i].Before(ts) })
+	if i < len(*idx) && (*idx)[i].Equal(ts) {
+		return
+	}
+	*idx = append(*idx, time.Time{})
+	copy((*idx)[i+1:], (*idx)[i:])
+	(*idx)[i] = ts
+}
+
+// between returns the timestamps in (start, end].
+func (idx historyIndex) between(start, end time.Time) []time.Time {
+	from := sort.Search(len(idx), func(i int) bool { return idx[i].After(start) })
+	to := sort.Search(len(idx), func(i int) bool { return idx[i].After(end) })
+	return append([]time.Time{}, idx[from:to]...)
+}
+
+// removeBefore drops, and returns, the timestamps before t.
+func (idx *historyIndex) removeBefore(t time.Time) []time.Time {
+	i := sort.Search(len(*idx), func(i int) bool { return !(*idx)[i].Before(t) })
+	removed := append([]time.Time{}, (*idx)[:i]...)
+	*idx = (*idx)[i:]
+	return removed
+}
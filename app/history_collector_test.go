@@ -0,0 +1,121 @@
+package app_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/weaveworks/common/mtime"
+	"github.com/weaveworks/scope/app"
+	"github.com/weaveworks/scope/report"
+)
+
+func TestHistoryCollector(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scope-history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	mtime.NowForce(now)
+	defer mtime.NowReset()
+
+	ctx := context.Background()
+	window := 10 * time.Second
+	cfg := app.HistoryConfig{Path: dir, Retention: time.Hour, Resolution: time.Minute}
+	c, err := app.NewHistoryCollector(cfg, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.HasHistoricReports() {
+		t.Error("history collector should have historic reports")
+	}
+
+	r1 := report.MakeReport()
+	r1.Endpoint.AddNode(report.MakeNode("foo"))
+	c.Add(ctx, r1, nil)
+
+	// r2 starts a new bucket, which flushes r1 to disk
+	mtime.NowForce(now.Add(2 * time.Minute))
+	r2 := report.MakeReport()
+	r2.Endpoint.AddNode(report.MakeNode("bar"))
+	c.Add(ctx, r2, nil)
+
+	// Half an hour later, the live collector has nothing...
+	mtime.NowForce(now.Add(30 * time.Minute))
+	if has, _ := c.HasReports(ctx, mtime.Now()); has {
+		t.Error("expected no live reports")
+	}
+
+	// ...but both reports can be found in the history.
+	for _, tc := range []struct {
+		at   time.Time
+		want string
+	}{
+		{now.Add(5 * time.Second), "foo"},
+		{now.Add(2*time.Minute + 5*time.Second), "bar"},
+	} {
+		if has, err := c.HasReports(ctx, tc.at); err != nil || !has {
+			t.Errorf("expected historic report at %v: %v", tc.at, err)
+		}
+		have, err := c.Report(ctx, tc.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := have.Endpoint.Nodes[tc.want]; !ok || len(have.Endpoint.Nodes) != 1 {
+			t.Errorf("expected only %q at %v, got %v", tc.want, tc.at, have.Endpoint.Nodes)
+		}
+	}
+
+	// A new collector picks up the stored reports, once they are written
+	waitForHistoryFiles(t, dir, 1)
+	c2, err := app.NewHistoryCollector(cfg, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has, _ := c2.HasReports(ctx, now.Add(5*time.Second)); !has {
+		t.Error("expected reports to be loaded from disk")
+	}
+
+	// Reports outside the retention period are deleted
+	mtime.NowForce(now.Add(2 * time.Hour))
+	c3, err := app.NewHistoryCollector(cfg, window)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if has, _ := c3.HasReports(ctx, now.Add(5*time.Second)); has {
+		t.Error("expected reports to be pruned")
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("expected history directory to be empty, got %d files", len(files))
+	}
+}
+
+// waitForHistoryFiles waits for the background flush to have written n
+// reports to dir.
+func waitForHistoryFiles(t *testing.T, dir string, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		written := 0
+		for _, f := range files {
+			if !strings.HasPrefix(f.Name(), ".") {
+				written++
+			}
+		}
+		if written >= n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d reports in %s, got %d", n, dir, written)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,89 @@
+package app
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// ServerTLSConfig returns the TLS configuration of an app serving with a
+// certificate. With a client CA, clients may present certificates signed
+// by it, which ProbeCertAuth requires of probes.
+func ServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pem, err := ioutil.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", clientCAFile)
+		}
+		config.ClientCAs = pool
+		// Users' browsers don't have certificates
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return config, nil
+}
+
+// ClientCertIdentity returns the common name of the verified client
+// certificate of a request.
+func ClientCertIdentity(r *http.Request) (string, bool) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return r.TLS.VerifiedChains[0][0].Subject.CommonName, true
+}
+
+// ProbeCertAuth is a middleware refusing the requests of probes, to post
+// reports and to connect controls and pipes, without a verified client
+// certificate.
+type ProbeCertAuth struct{}
+
+// Wrap implements middleware.Interface
+func (ProbeCertAuth) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := ClientCertIdentity(r); !ok && isProbeRequest(r) {
+			http.Error(w, "A client certificate is required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func isProbeRequest(r *http.Request) bool {
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/api/report":
+		return true
+	case r.URL.Path == "/api/control/ws":
+		return true
+	case strings.HasPrefix(r.URL.Path, "/api/pipe/") && strings.HasSuffix(r.URL.Path, "/probe"):
+		return true
+	}
+	return false
+}
+
+// IsProbeRoute tells whether probes use a route: those of isProbeRequest,
+// and the ones to get the details of the app and to close pipes.
+func IsProbeRoute(r *http.Request) bool {
+	switch {
+	case isProbeRequest(r):
+		return true
+	case r.Method == "GET" && r.URL.Path == "/api":
+		return true
+	case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/api/pipe/"):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,163 @@
+package app_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/scope/app"
+	"github.com/weaveworks/scope/report"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+func newTestCert(t *testing.T, cn string, parent *testCert, isCA bool) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+func (c *testCert) write(t *testing.T, dir, name string) (string, string) {
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func TestProbeCertAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scope-mtls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, "ca", nil, true)
+	caFile, _ := ca.write(t, dir, "ca")
+	certFile, keyFile := newTestCert(t, "app", ca, false).write(t, dir, "app")
+	probeCert := newTestCert(t, "probe-1", ca, false)
+	otherCA := newTestCert(t, "other", nil, true)
+	strangerCert := newTestCert(t, "stranger", otherCA, false)
+
+	tlsConfig, err := app.ServerTLSConfig(certFile, keyFile, caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reports are stood in for, to only see who posted them
+	collector := app.NewCollector(time.Minute)
+	router := mux.NewRouter()
+	router.Methods("POST").Path("/api/report").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := app.ClientCertIdentity(r)
+		w.Write([]byte(identity))
+	})
+	app.RegisterTopologyRoutes(router, collector, map[string]bool{})
+	server := httptest.NewUnstartedServer(app.ProbeCertAuth{}.Wrap(router))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+	}
+	post := func(c *http.Client) (int, string) {
+		resp, err := c.Post(server.URL+"/api/report", "application/msgpack", nil)
+		if err != nil {
+			return 0, ""
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	if status, _ := post(client()); status != http.StatusUnauthorized {
+		t.Errorf("expected reports without certificate to be refused, got %d", status)
+	}
+	if status, _ := post(client(strangerCert.tlsCertificate())); status == http.StatusOK {
+		t.Errorf("expected reports with a certificate of another CA to be refused")
+	}
+	if status, identity := post(client(probeCert.tlsCertificate())); status != http.StatusOK || identity != "probe-1" {
+		t.Errorf("expected reports with a certificate to be accepted, got %d %q", status, identity)
+	}
+
+	// Users don't need certificates
+	rpt := report.MakeReport()
+	rpt.Host.AddNode(report.MakeNodeWith(report.MakeHostNodeID("host"), map[string]string{
+		report.ControlProbeID: "4321",
+		report.ProbeIdentity:  "probe-1",
+	}))
+	collector.Add(context.Background(), rpt, nil)
+	resp, err := client().Get(server.URL + "/api/probes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var probes []struct {
+		ID       string `json:"id"`
+		Identity string `json:"identity"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&probes); err != nil {
+		t.Fatal(err)
+	}
+	if len(probes) != 1 || probes[0].ID != "4321" || probes[0].Identity != "probe-1" {
+		t.Errorf("expected the probe to be identified by its certificate, got %v", probes)
+	}
+}
@@ -0,0 +1,480 @@
+package app
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/securecookie"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+)
+
+// Paths of the OpenID Connect login endpoints, relative to the app URL.
+const (
+	OIDCLoginPath    = "/oidc/login"
+	OIDCCallbackPath = "/oidc/callback"
+	OIDCLogoutPath   = "/oidc/logout"
+
+	sessionCookie   = "scope_session"
+	oidcStateCookie = "scope_oidc_state"
+	oidcStateTTL    = 10 * time.Minute
+	oidcLeeway      = time.Minute
+	oidcKeysRefresh = time.Minute
+)
+
+// OIDCConfig configures OpenID Connect login.
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// AppURL is the URL users reach the app at, which the issuer redirects
+	// them back to after login.
+	AppURL string
+	Scopes []string
+	// UsernameClaim and GroupsClaim are the claims of ID tokens giving the
+	// identity of users. Users without the username claim are named by
+	// their subject.
+	UsernameClaim string
+	GroupsClaim   string
+	// CookieSecret signs and encrypts session cookies. Without one, a
+	// random secret is used, and sessions don't survive restarts.
+	CookieSecret string
+	SessionTTL   time.Duration
+	// ProbeCredentials tells whether a request carries the credentials of
+	// a probe, which doesn't log in.
+	ProbeCredentials func(*http.Request) bool
+}
+
+// OIDC is a middleware logging users in with an OpenID Connect issuer.
+// Logged in users have a session cookie giving their identity to the
+// requests they make.
+type OIDC struct {
+	OIDCConfig
+	oauth2        oauth2.Config
+	client        *http.Client
+	basePath      string
+	secure        bool
+	jwksURL       string
+	endSessionURL string
+	sessions      *securecookie.SecureCookie
+	states        *securecookie.SecureCookie
+
+	mtx         sync.Mutex
+	keys        map[string]interface{}
+	keysFetched time.Time
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+type oidcSession struct {
+	User   string
+	Groups []string
+	Expiry time.Time
+}
+
+type oidcState struct {
+	State    string
+	Nonce    string
+	Redirect string
+}
+
+// NewOIDC makes a new OIDC middleware, discovering the endpoints of the
+// issuer.
+func NewOIDC(ctx context.Context, config OIDCConfig) (*OIDC, error) {
+	appURL, err := url.Parse(config.AppURL)
+	if err != nil || appURL.Host == "" {
+		return nil, fmt.Errorf("invalid app URL %q", config.AppURL)
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "email"
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	if config.SessionTTL <= 0 {
+		config.SessionTTL = 12 * time.Hour
+	}
+	scopes := []string{"openid"}
+	for _, scope := range config.Scopes {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	o := &OIDC{
+		OIDCConfig: config,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		basePath:   strings.TrimSuffix(appURL.Path, "/"),
+		secure:     appURL.Scheme == "https",
+	}
+	var discovery oidcDiscovery
+	if err := o.getJSON(ctx, strings.TrimSuffix(config.IssuerURL, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("discovering OIDC issuer: %v", err)
+	}
+	if discovery.Issuer != config.IssuerURL && discovery.Issuer != strings.TrimSuffix(config.IssuerURL, "/") {
+		return nil, fmt.Errorf("OIDC issuer %q doesn't match its configuration %q", config.IssuerURL, discovery.Issuer)
+	}
+	o.IssuerURL = discovery.Issuer
+	o.jwksURL = discovery.JWKSURI
+	o.endSessionURL = discovery.EndSessionEndpoint
+	o.oauth2 = oauth2.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+		RedirectURL: strings.TrimSuffix(config.AppURL, "/") + OIDCCallbackPath,
+		Scopes:      scopes,
+	}
+
+	var hashKey, blockKey []byte
+	if config.CookieSecret == "" {
+		log.Warnf("No OIDC cookie secret given, sessions won't survive restarts")
+		hashKey, blockKey = securecookie.GenerateRandomKey(32), securecookie.GenerateRandomKey(32)
+	} else {
+		hash, block := sha256.Sum256([]byte("hash:"+config.CookieSecret)), sha256.Sum256([]byte("block:"+config.CookieSecret))
+		hashKey, blockKey = hash[:], block[:]
+	}
+	o.sessions = securecookie.New(hashKey, blockKey).MaxAge(int(config.SessionTTL / time.Second))
+	o.states = securecookie.New(hashKey, blockKey).MaxAge(int(oidcStateTTL / time.Second))
+	return o, nil
+}
+
+// Wrap implements middleware.Interface
+func (o *OIDC) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case OIDCLoginPath:
+			o.login(w, r)
+			return
+		case OIDCCallbackPath:
+			o.callback(w, r)
+			return
+		case OIDCLogoutPath:
+			o.logout(w, r)
+			return
+		}
+		if s, ok := o.session(r); ok {
+			ctx := WithIdentity(r.Context(), Identity{User: s.User, Groups: s.Groups})
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+		// Probe credentials only let probes use their own routes
+		if o.ProbeCredentials != nil && IsProbeRoute(r) && o.ProbeCredentials(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		// Send browsers to log in, and refuse API requests
+		if r.Method == "GET" && strings.Contains(r.Header.Get("Accept"), "text/html") {
+			http.Redirect(w, r, o.basePath+OIDCLoginPath+"?redirect="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
+
+func (o *OIDC) login(w http.ResponseWriter, r *http.Request) {
+	redirect := r.URL.Query().Get("redirect")
+	// Only redirect within the app after login
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		redirect = "/"
+	}
+	state := oidcState{
+		State:    base64.RawURLEncoding.EncodeToString(securecookie.GenerateRandomKey(24)),
+		Nonce:    base64.RawURLEncoding.EncodeToString(securecookie.GenerateRandomKey(24)),
+		Redirect: redirect,
+	}
+	encoded, err := o.states.Encode(oidcStateCookie, state)
+	if err != nil {
+		respondWith(w, http.StatusInternalServerError, err)
+		return
+	}
+	o.setCookie(w, oidcStateCookie, encoded, oidcStateTTL)
+	http.Redirect(w, r, o.oauth2.AuthCodeURL(state.State, oauth2.SetAuthURLParam("nonce", state.Nonce)), http.StatusFound)
+}
+
+func (o *OIDC) callback(w http.ResponseWriter, r *http.Request) {
+	var state oidcState
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil || o.states.Decode(oidcStateCookie, cookie.Value, &state) != nil || state.State != r.URL.Query().Get("state") {
+		http.Error(w, "Invalid login state, please try again", http.StatusBadRequest)
+		return
+	}
+	o.setCookie(w, oidcStateCookie, "", -1)
+	if e := r.URL.Query().Get("error"); e != "" {
+		http.Error(w, fmt.Sprintf("Login failed: %s %s", e, r.URL.Query().Get("error_description")), http.StatusUnauthorized)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), oauth2.HTTPClient, o.client)
+	token, err := o.oauth2.Exchange(ctx, r.URL.Query().Get("code"))
+	if err != nil {
+		log.Warnf("Error exchanging OIDC code: %v", err)
+		http.Error(w, "Login failed", http.StatusUnauthorized)
+		return
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	id, err := o.verify(ctx, rawIDToken, state.Nonce)
+	if err != nil {
+		log.Warnf("Invalid OIDC ID token: %v", err)
+		http.Error(w, "Login failed", http.StatusUnauthorized)
+		return
+	}
+
+	encoded, err := o.sessions.Encode(sessionCookie, oidcSession{
+		User:   id.User,
+		Groups: id.Groups,
+		Expiry: time.Now().Add(o.SessionTTL),
+	})
+	if err != nil {
+		respondWith(w, http.StatusInternalServerError, err)
+		return
+	}
+	o.setCookie(w, sessionCookie, encoded, o.SessionTTL)
+	log.Infof("%s logged in", id.User)
+	http.Redirect(w, r, o.basePath+state.Redirect, http.StatusFound)
+}
+
+func (o *OIDC) logout(w http.ResponseWriter, r *http.Request) {
+	// Logging out changes state, so other sites mustn't be able to link to it
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	o.setCookie(w, sessionCookie, "", -1)
+	redirect := o.basePath + "/"
+	if o.endSessionURL != "" {
+		sep := "?"
+		if strings.Contains(o.endSessionURL, "?") {
+			sep = "&"
+		}
+		redirect = o.endSessionURL + sep + url.Values{
+			"client_id":                {o.ClientID},
+			"post_logout_redirect_uri": {strings.TrimSuffix(o.AppURL, "/") + "/"},
+		}.Encode()
+	}
+	http.Redirect(w, r, redirect, http.StatusSeeOther)
+}
+
+func (o *OIDC) session(r *http.Request) (oidcSession, bool) {
+	var s oidcSession
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil || o.sessions.Decode(sessionCookie, cookie.Value, &s) != nil {
+		return s, false
+	}
+	return s, time.Now().Before(s.Expiry)
+}
+
+func (o *OIDC) setCookie(w http.ResponseWriter, name, value string, ttl time.Duration) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     o.basePath + "/",
+		HttpOnly: true,
+		Secure:   o.secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if ttl < 0 {
+		cookie.MaxAge = -1
+	} else {
+		cookie.MaxAge = int(ttl / time.Second)
+	}
+	http.SetCookie(w, cookie)
+}
+
+// verify checks the signature and the claims of an ID token, and returns
+// the identity it gives.
+func (o *OIDC) verify(ctx context.Context, rawIDToken, nonce string) (Identity, error) {
+	if rawIDToken == "" {
+		return Identity{}, fmt.Errorf("no ID token")
+	}
+	claims := jwt.MapClaims{}
+	parser := jwt.Parser{
+		ValidMethods:         []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"},
+		SkipClaimsValidation: true,
+	}
+	if _, err := parser.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return o.key(ctx, kid)
+	}); err != nil {
+		return Identity{}, err
+	}
+
+	if iss, _ := claims["iss"].(string); iss != o.IssuerURL {
+		return Identity{}, fmt.Errorf("issued by %q", iss)
+	}
+	if !audienceContains(claims["aud"], o.ClientID) {
+		return Identity{}, fmt.Errorf("not issued to %s", o.ClientID)
+	}
+	exp, _ := claims["exp"].(float64)
+	if time.Now().Add(-oidcLeeway).After(time.Unix(int64(exp), 0)) {
+		return Identity{}, fmt.Errorf("expired")
+	}
+	if n, _ := claims["nonce"].(string); n != nonce {
+		return Identity{}, fmt.Errorf("invalid nonce")
+	}
+
+	user, _ := claims[o.UsernameClaim].(string)
+	if user == "" {
+		user, _ = claims["sub"].(string)
+	}
+	if user == "" {
+		return Identity{}, fmt.Errorf("no subject")
+	}
+	id := Identity{User: user}
+	switch groups := claims[o.GroupsClaim].(type) {
+	case string:
+		id.Groups = []string{groups}
+	case []interface{}:
+		for _, g := range groups {
+			if g, ok := g.(string); ok {
+				id.Groups = append(id.Groups, g)
+			}
+		}
+	}
+	return id, nil
+}
+
+func audienceContains(aud interface{}, clientID string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == clientID
+	case []interface{}:
+		for _, a := range aud {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// key returns the signing key of the issuer with an ID, fetching them again
+// when it isn't known, as issuers rotate their keys.
+func (o *OIDC) key(ctx context.Context, kid string) (interface{}, error) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if key, ok := o.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(o.keysFetched) < oidcKeysRefresh {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := o.getJSON(ctx, o.jwksURL, &jwks); err != nil {
+		return nil, err
+	}
+	o.keysFetched = time.Now()
+	o.keys = map[string]interface{}{}
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			log.Warnf("Ignoring OIDC signing key %q: %v", k.Kid, err)
+			continue
+		}
+		o.keys[k.Kid] = key
+	}
+	if key, ok := o.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (o *OIDC) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := o.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// jsonWebKey is a public key of a JSON Web Key Set, as in RFC 7517.
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
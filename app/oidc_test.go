@@ -0,0 +1,208 @@
+package app_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/weaveworks/scope/app"
+)
+
+// mockIssuer is an OpenID Connect issuer logging in whoever it is told to,
+// without asking.
+type mockIssuer struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+	codes  map[string]string // code -> nonce
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockIssuer{key: key, codes: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/keys",
+			"end_session_endpoint":   m.URL + "/logout",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "test",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		code := fmt.Sprintf("code%d", len(m.codes))
+		m.codes[code] = q.Get("nonce")
+		http.Redirect(w, r, q.Get("redirect_uri")+"?"+url.Values{"code": {code}, "state": {q.Get("state")}}.Encode(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, _ := r.BasicAuth(); id != "scope" || secret != "secret" {
+			http.Error(w, "invalid client", http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+		nonce, ok := m.codes[r.PostForm.Get("code")]
+		if !ok {
+			http.Error(w, "invalid code", http.StatusBadRequest)
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss":   m.URL,
+			"aud":   "scope",
+			"sub":   "1234",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": nonce,
+		}
+		for k, v := range m.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "test"
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     idToken,
+		})
+	})
+	m.Server = httptest.NewServer(mux)
+	return m
+}
+
+func TestOIDC(t *testing.T) {
+	issuer := newMockIssuer(t)
+	defer issuer.Close()
+
+	// The app server's URL must be known to configure OIDC
+	var handler http.Handler
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	oidc, err := app.NewOIDC(context.Background(), app.OIDCConfig{
+		IssuerURL:    issuer.URL,
+		ClientID:     "scope",
+		ClientSecret: "secret",
+		AppURL:       server.URL,
+		CookieSecret: "cookies",
+		ProbeCredentials: func(r *http.Request) bool {
+			return r.Header.Get("Authorization") == "Scope-Probe token=probe"
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler = oidc.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, _ := app.IdentityFromContext(r.Context())
+		fmt.Fprintf(w, "%s %s %s", r.URL.Path, id.User, strings.Join(id.Groups, ","))
+	}))
+
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Jar: jar}
+	do := func(method, path string, header http.Header) (int, string) {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	get := func(path string, header http.Header) (int, string) {
+		return do("GET", path, header)
+	}
+	browser := http.Header{"Accept": {"text/html"}}
+
+	if status, _ := get("/api/topology", nil); status != http.StatusUnauthorized {
+		t.Errorf("expected API requests without session to be refused, got %d", status)
+	}
+	probe := http.Header{"Authorization": {"Scope-Probe token=probe"}}
+	if status, body := do("POST", "/api/report", probe); status != http.StatusOK || body != "/api/report  " {
+		t.Errorf("expected probes to be let through, got %d %q", status, body)
+	}
+	if status, _ := do("POST", "/api/report", http.Header{"Authorization": {"Scope-Probe token=nope"}}); status != http.StatusUnauthorized {
+		t.Errorf("expected probes with the wrong token to be refused, got %d", status)
+	}
+	if status, _ := get("/api/topology", probe); status != http.StatusUnauthorized {
+		t.Errorf("expected probe credentials to be refused outside of probe routes, got %d", status)
+	}
+
+	issuer.claims = jwt.MapClaims{"email": "alice@example.com", "groups": []string{"admins", "developers"}}
+	if status, body := get("/some/page", browser); status != http.StatusOK || body != "/some/page alice@example.com admins,developers" {
+		t.Errorf("expected to be logged in and sent back, got %d %q", status, body)
+	}
+	if status, body := get("/api/topology", nil); status != http.StatusOK || body != "/api/topology alice@example.com admins,developers" {
+		t.Errorf("expected the session to identify API requests, got %d %q", status, body)
+	}
+
+	// Logging out takes a POST, and sends users to the issuer to log out
+	// there too
+	if status, _ := get(app.OIDCLogoutPath, nil); status != http.StatusMethodNotAllowed {
+		t.Errorf("expected logging out with GET to be refused, got %d", status)
+	}
+	if status, _ := get("/api/topology", nil); status != http.StatusOK {
+		t.Errorf("expected to be still logged in, got %d", status)
+	}
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	req, _ := http.NewRequest("POST", server.URL+app.OIDCLogoutPath, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if location := resp.Header.Get("Location"); !strings.HasPrefix(location, issuer.URL+"/logout?") {
+		t.Errorf("expected to be sent to the issuer to log out, got %q", location)
+	}
+	if status, _ := get("/api/topology", nil); status != http.StatusUnauthorized {
+		t.Errorf("expected to be logged out, got %d", status)
+	}
+
+	// Logins from elsewhere are refused
+	if status, _ := get(app.OIDCCallbackPath+"?code=code0&state=forged", nil); status != http.StatusBadRequest {
+		t.Errorf("expected a callback without login state to be refused, got %d", status)
+	}
+	// ID tokens must be meant for the app
+	client.CheckRedirect = nil
+	issuer.claims = jwt.MapClaims{"email": "mallory@example.com", "aud": "other"}
+	if status, _ := get("/", browser); status != http.StatusUnauthorized {
+		t.Errorf("expected ID tokens for other clients to be refused, got %d", status)
+	}
+}
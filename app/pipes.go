@@ -2,6 +2,7 @@ package app
 
 import (
 	"net/http"
+	"sync/atomic"
 
 	"context"
 	"github.com/gorilla/mux"
@@ -10,8 +11,9 @@ import (
 	"github.com/weaveworks/scope/common/xfer"
 )
 
-// RegisterPipeRoutes registers the pipe routes
-func RegisterPipeRoutes(router *mux.Router, pr PipeRouter) {
+// RegisterPipeRoutes registers the pipe routes. The use of pipes by the UI is
+// recorded in audit.
+func RegisterPipeRoutes(router *mux.Router, pr PipeRouter, audit *AuditLog) {
 	router.Methods("GET").
 		Name("api_pipe_pipeid_check").
 		Path("/api/pipe/{pipeID}/check").
@@ -20,12 +22,12 @@ func RegisterPipeRoutes(router *mux.Router, pr PipeRouter) {
 	router.Methods("GET").
 		Name("api_pipe_pipeid").
 		Path("/api/pipe/{pipeID}").
-		HandlerFunc(requestContextDecorator(handlePipeWs(pr, UIEnd)))
+		HandlerFunc(requestContextDecorator(handlePipeWs(pr, UIEnd, audit)))
 
 	router.Methods("GET").
 		Name("api_pipe_pipeid_probe").
 		Path("/api/pipe/{pipeID}/probe").
-		HandlerFunc(requestContextDecorator(handlePipeWs(pr, ProbeEnd)))
+		HandlerFunc(requestContextDecorator(handlePipeWs(pr, ProbeEnd, nil)))
 
 	router.Methods("DELETE", "POST").
 		Name("api_pipe_pipeid").
@@ -47,7 +49,7 @@ func checkPipe(pr PipeRouter) CtxHandlerFunc {
 	}
 }
 
-func handlePipeWs(pr PipeRouter, end End) CtxHandlerFunc {
+func handlePipeWs(pr PipeRouter, end End, audit *AuditLog) CtxHandlerFunc {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 		id := mux.Vars(r)["pipeID"]
 		pipe, endIO, err := pr.Get(ctx, id, end)
@@ -66,6 +68,16 @@ func handlePipeWs(pr PipeRouter, end End) CtxHandlerFunc {
 		}
 		defer conn.Close()
 
+		if audit != nil {
+			counted := &countingReadWriter{ReadWriter: endIO}
+			audit.RecordPipe(ctx, AuditPipeOpen, id, 0, 0)
+			defer func() {
+				// What the UI sends is written to its end
+				audit.RecordPipe(ctx, AuditPipeClose, id, atomic.LoadInt64(&counted.written), atomic.LoadInt64(&counted.read))
+			}()
+			endIO = counted
+		}
+
 		if err := pipe.CopyToWebsocket(endIO, conn); err != nil && !xfer.IsExpectedWSCloseError(err) {
 			log.Errorf("Error copying to pipe %s (%d) websocket: %v", id, end, err)
 		}
@@ -25,7 +25,7 @@ import (
 func TestPipeTimeout(t *testing.T) {
 	router := mux.NewRouter()
 	pr := NewLocalPipeRouter().(*localPipeRouter)
-	RegisterPipeRoutes(router, pr)
+	RegisterPipeRoutes(router, pr, NewAuditLog(10, nil))
 	pr.Stop() // we don't want the loop running in the background
 
 	mtime.NowForce(time.Now())
@@ -70,7 +70,8 @@ func (a adapter) PipeClose(_, pipeID string) error {
 func TestPipeClose(t *testing.T) {
 	router := mux.NewRouter()
 	pr := NewLocalPipeRouter()
-	RegisterPipeRoutes(router, pr)
+	audit := NewAuditLog(10, nil)
+	RegisterPipeRoutes(router, pr, audit)
 	defer pr.Stop()
 
 	server := httptest.NewServer(router)
@@ -140,4 +141,13 @@ func TestPipeClose(t *testing.T) {
 	test.Poll(t, 2*time.Second, true, func() interface{} {
 		return pipe.Closed()
 	})
+
+	// The UI sent 20 bytes and received 11
+	test.Poll(t, 2*time.Second, "20/11", func() interface{} {
+		records := audit.Query(AuditQuery{Type: AuditPipeClose})
+		if len(records) == 0 {
+			return nil
+		}
+		return fmt.Sprintf("%d/%d", records[0].BytesIn, records[0].BytesOut)
+	})
 }
@@ -0,0 +1,439 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bluele/gcache"
+	"sigs.k8s.io/yaml"
+
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
+)
+
+const (
+	identityCtxKey contextKey = contextKey("identity")
+	accessCtxKey   contextKey = contextKey("access")
+)
+
+// Identity is the user making a request, and the groups they belong to.
+type Identity struct {
+	User   string
+	Groups []string
+}
+
+// WithIdentity returns a context carrying the identity of the user of a
+// request.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityCtxKey, id)
+}
+
+// IdentityFromContext returns the identity of the user of the request in
+// ctx, as set by WithIdentity once verified.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityCtxKey).(Identity)
+	return id, ok
+}
+
+// BasicAuthIdentity gives requests the identity of their basic
+// authentication user. It must only wrap handlers behind the check of
+// their credentials.
+func BasicAuthIdentity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, _, ok := r.BasicAuth(); ok && username != "" {
+			r = r.WithContext(WithIdentity(r.Context(), Identity{User: username}))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Role grants the use of controls on the nodes of some namespaces. Controls
+// and namespaces are glob patterns, as in path.Match, so "*" matches all of
+// them and "docker_*" all Docker controls. Admin roles may also read the
+// audit log.
+type Role struct {
+	Name       string   `json:"name"`
+	Controls   []string `json:"controls"`
+	Namespaces []string `json:"namespaces"`
+	Admin      bool     `json:"admin,omitempty"`
+}
+
+// RoleBinding gives a role to users and to the members of groups.
+type RoleBinding struct {
+	Role   string   `json:"role"`
+	Users  []string `json:"users,omitempty"`
+	Groups []string `json:"groups,omitempty"`
+}
+
+// Policy decides which controls users may use, and which Kubernetes
+// namespaces they may see. Nodes outside of namespaces are visible to all.
+type Policy struct {
+	Roles    []Role        `json:"roles"`
+	Bindings []RoleBinding `json:"bindings"`
+	// DefaultRole is the role of users without bindings, and of requests
+	// without identity. Without one, they see no namespace and can't use
+	// any control.
+	DefaultRole string `json:"defaultRole,omitempty"`
+
+	roles map[string]Role
+}
+
+// LoadPolicy reads a policy from a YAML or JSON file.
+func LoadPolicy(filename string) (*Policy, error) {
+	buf, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePolicy(buf)
+}
+
+// ParsePolicy parses a YAML or JSON policy.
+func ParsePolicy(buf []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(buf, &p); err != nil {
+		return nil, err
+	}
+	p.roles = map[string]Role{}
+	for _, role := range p.Roles {
+		if role.Name == "" {
+			return nil, fmt.Errorf("roles need a name")
+		}
+		for _, pattern := range append(append([]string{}, role.Controls...), role.Namespaces...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("role %s: invalid pattern %q", role.Name, pattern)
+			}
+		}
+		p.roles[role.Name] = role
+	}
+	for _, binding := range p.Bindings {
+		if _, ok := p.roles[binding.Role]; !ok {
+			return nil, fmt.Errorf("binding to unknown role %q", binding.Role)
+		}
+	}
+	if _, ok := p.roles[p.DefaultRole]; p.DefaultRole != "" && !ok {
+		return nil, fmt.Errorf("unknown default role %q", p.DefaultRole)
+	}
+	return &p, nil
+}
+
+// Access returns what the policy allows an identity to do.
+func (p *Policy) Access(id Identity) *Access {
+	groups := map[string]struct{}{}
+	for _, g := range id.Groups {
+		groups[g] = struct{}{}
+	}
+	access := &Access{Identity: id}
+	for _, binding := range p.Bindings {
+		if id.User != "" && contains(binding.Users, id.User) || containsAny(binding.Groups, groups) {
+			access.roles = append(access.roles, p.roles[binding.Role])
+		}
+	}
+	if len(access.roles) == 0 && p.DefaultRole != "" {
+		access.roles = append(access.roles, p.roles[p.DefaultRole])
+	}
+	return access
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values []string, set map[string]struct{}) bool {
+	for _, v := range values {
+		if _, ok := set[v]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Access is what an identity may do, by the union of its roles. A nil
+// Access allows everything, for apps without a policy.
+type Access struct {
+	Identity Identity
+	roles    []Role
+}
+
+// AccessFromContext returns the access of the user of the request in ctx,
+// or nil without a policy.
+func AccessFromContext(ctx context.Context) *Access {
+	access, _ := ctx.Value(accessCtxKey).(*Access)
+	return access
+}
+
+// CanSeeNamespace tells whether the nodes of a Kubernetes namespace are
+// visible.
+func (a *Access) CanSeeNamespace(namespace string) bool {
+	if a == nil {
+		return true
+	}
+	for _, role := range a.roles {
+		if matchesAny(role.Namespaces, namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanSee tells whether a node is visible, by its namespace.
+func (a *Access) CanSee(n report.Node) bool {
+	namespace, ok := render.KubernetesNamespaceOf(n)
+	return !ok || a.CanSeeNamespace(namespace)
+}
+
+// IsAdmin tells whether any of the roles is an admin one.
+func (a *Access) IsAdmin() bool {
+	if a == nil {
+		return true
+	}
+	for _, role := range a.roles {
+		if role.Admin {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowControl tells whether a control may be used on a node in a
+// namespace. Nodes known to be outside of namespaces have an empty one.
+func (a *Access) AllowControl(controlID, namespace string) bool {
+	if a == nil {
+		return true
+	}
+	for _, role := range a.roles {
+		if matchesAny(role.Controls, controlID) && (namespace == "" || matchesAny(role.Namespaces, namespace)) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowNodeControl tells whether a control may be used on a node. Nodes
+// which weren't found have an empty ID; as their namespace is unknown,
+// Kubernetes and Docker controls are only allowed on them to roles
+// allowing them in all namespaces.
+func (a *Access) AllowNodeControl(n report.Node, controlID string) bool {
+	if a == nil {
+		return true
+	}
+	if n.ID == "" && isNamespacedControl(controlID) {
+		for _, role := range a.roles {
+			if matchesAny(role.Controls, controlID) && contains(role.Namespaces, "*") {
+				return true
+			}
+		}
+		return false
+	}
+	namespace, _ := render.KubernetesNamespaceOf(n)
+	return a.AllowControl(controlID, namespace)
+}
+
+// isNamespacedControl tells whether a control is used on nodes which can
+// be in Kubernetes namespaces.
+func isNamespacedControl(controlID string) bool {
+	return strings.HasPrefix(controlID, "kubernetes_") || strings.HasPrefix(controlID, "docker_")
+}
+
+// visibility returns a key which is the same for all accesses seeing the
+// same namespaces.
+func (a *Access) visibility() string {
+	patterns := []string{}
+	for _, role := range a.roles {
+		patterns = append(patterns, role.Namespaces...)
+	}
+	sort.Strings(patterns)
+	h := fnv.New64a()
+	for _, p := range patterns {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// filteredReports caches filtered reports, by the ID given them by
+// FilterReport.
+var filteredReports = gcache.New(filteredReportsCached).LRU().Build()
+
+const filteredReportsCached = 20
+
+// FilterReport removes the nodes of the namespaces which aren't visible
+// from a report, along with the processes, endpoints and images of the
+// containers and pods hidden. The report is copied rather than modified,
+// as reports are shared between requests. Copies are identified by the
+// report and the namespaces visible, so that their renderings can be
+// cached, and are cached themselves.
+func (a *Access) FilterReport(rpt report.Report) report.Report {
+	if a == nil {
+		return rpt
+	}
+	id := rpt.ID + "-" + a.visibility()
+	if cached, err := filteredReports.Get(id); err == nil {
+		return cached.(report.Report)
+	}
+	rpt = rpt.Copy()
+	rpt.ID = id
+	hidden := map[string]report.Nodes{}
+	rpt.WalkNamedTopologies(func(name string, t *report.Topology) {
+		for nodeID, n := range t.Nodes {
+			if !a.CanSee(n) {
+				if name == report.Container || name == report.Pod {
+					if hidden[name] == nil {
+						hidden[name] = report.Nodes{}
+					}
+					hidden[name][nodeID] = n
+				}
+				delete(t.Nodes, nodeID)
+			}
+		}
+	})
+	hideChildren(&rpt, hidden[report.Container], hidden[report.Pod])
+	filteredReports.Set(id, rpt)
+	return rpt
+}
+
+// hideChildren removes the processes and endpoints of hidden containers
+// and pods from a report, and the images only used by hidden containers.
+func hideChildren(rpt *report.Report, containers, pods report.Nodes) {
+	if len(containers) == 0 && len(pods) == 0 {
+		return
+	}
+	addresses := map[string]struct{}{}
+	hiddenImages := map[string]struct{}{}
+	for _, n := range containers {
+		for _, id := range render.MapContainer2IP(n) {
+			addresses[id] = struct{}{}
+		}
+		if imageID, ok := n.Latest.Lookup(report.DockerImageID); ok {
+			hiddenImages[report.MakeContainerImageNodeID(imageID)] = struct{}{}
+		}
+	}
+	for _, n := range pods {
+		for _, id := range render.MapPod2IP(n) {
+			addresses[id] = struct{}{}
+		}
+	}
+	for _, n := range rpt.Container.Nodes {
+		if imageID, ok := n.Latest.Lookup(report.DockerImageID); ok {
+			delete(hiddenImages, report.MakeContainerImageNodeID(imageID))
+		}
+	}
+	for id := range hiddenImages {
+		delete(rpt.ContainerImage.Nodes, id)
+	}
+
+	processes := map[string]struct{}{}
+	for id, n := range rpt.Process.Nodes {
+		if containerID, ok := n.Latest.Lookup(report.DockerContainerID); ok {
+			if _, ok := containers[report.MakeContainerNodeID(containerID)]; ok {
+				processes[id] = struct{}{}
+				delete(rpt.Process.Nodes, id)
+			}
+		}
+	}
+	for id, n := range rpt.Endpoint.Nodes {
+		if pid, ok := n.Latest.Lookup(report.PID); ok {
+			if _, ok := processes[report.MakeProcessNodeID(report.ExtractHostID(n), pid)]; ok {
+				delete(rpt.Endpoint.Nodes, id)
+				continue
+			}
+		}
+		scope, address, port, ok := report.ParseEndpointNodeID(id)
+		if !ok {
+			continue
+		}
+		for _, addr := range []string{
+			report.MakeScopedEndpointNodeID(scope, address, ""),
+			report.MakeScopedEndpointNodeID("", address, ""),
+			report.MakeScopedEndpointNodeID(scope, address, port),
+			report.MakeScopedEndpointNodeID("", address, port),
+		} {
+			if _, ok := addresses[addr]; ok {
+				delete(rpt.Endpoint.Nodes, id)
+				break
+			}
+		}
+	}
+}
+
+// RBAC is a middleware giving requests the access their identity has by
+// the policy. Identities are those verified already, by OIDC login or
+// basic authentication, or else taken from a header set by a trusted
+// authenticating proxy, if configured. Requests without one get the
+// default role.
+type RBAC struct {
+	Policy       *Policy
+	UserHeader   string
+	GroupsHeader string
+}
+
+// Wrap implements middleware.Interface
+func (m RBAC) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if _, ok := ctx.Value(identityCtxKey).(Identity); !ok && m.UserHeader != "" {
+			if user := r.Header.Get(m.UserHeader); user != "" {
+				id := Identity{User: user}
+				if m.GroupsHeader != "" {
+					for _, g := range strings.Split(r.Header.Get(m.GroupsHeader), ",") {
+						if g = strings.TrimSpace(g); g != "" {
+							id.Groups = append(id.Groups, g)
+						}
+					}
+				}
+				ctx = WithIdentity(ctx, id)
+			}
+		}
+		id, _ := IdentityFromContext(ctx)
+		ctx = context.WithValue(ctx, accessCtxKey, m.Policy.Access(id))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// errForbidden is returned to users without access
+var errForbidden = errors.New("forbidden")
+
+// AccessReporter hides the nodes of the namespaces users may not see from
+// the reports of a Reporter, and its summary from all but admins.
+type AccessReporter struct {
+	Reporter
+}
+
+// Report implements Reporter
+func (r AccessReporter) Report(ctx context.Context, timestamp time.Time) (report.Report, error) {
+	rpt, err := r.Reporter.Report(ctx, timestamp)
+	if err != nil {
+		return rpt, err
+	}
+	return AccessFromContext(ctx).FilterReport(rpt), nil
+}
+
+// AdminSummary implements Reporter, for admins only
+func (r AccessReporter) AdminSummary(ctx context.Context, timestamp time.Time) (string, error) {
+	if !AccessFromContext(ctx).IsAdmin() {
+		return "", errForbidden
+	}
+	return r.Reporter.AdminSummary(ctx, timestamp)
+}
@@ -0,0 +1,244 @@
+package app_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/scope/app"
+	"github.com/weaveworks/scope/common/xfer"
+	"github.com/weaveworks/scope/report"
+)
+
+const testPolicy = `
+roles:
+- name: admin
+  controls: ["*"]
+  namespaces: ["*"]
+  admin: true
+- name: dev
+  controls: ["docker_*", "kubernetes_get_logs"]
+  namespaces: ["dev", "staging-*"]
+- name: viewer
+  controls: []
+  namespaces: ["dev"]
+bindings:
+- role: admin
+  users: ["alice"]
+- role: dev
+  groups: ["developers"]
+defaultRole: viewer
+`
+
+func TestPolicyAccess(t *testing.T) {
+	policy, err := app.ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		id                  app.Identity
+		control, namespace  string
+		allowed, canSeeProd bool
+	}{
+		{app.Identity{User: "alice"}, "kubernetes_delete_pod", "prod", true, true},
+		{app.Identity{User: "bob", Groups: []string{"developers"}}, "docker_stop_container", "dev", true, false},
+		{app.Identity{User: "bob", Groups: []string{"developers"}}, "docker_stop_container", "staging-2", true, false},
+		{app.Identity{User: "bob", Groups: []string{"developers"}}, "docker_stop_container", "prod", false, false},
+		{app.Identity{User: "bob", Groups: []string{"developers"}}, "kubernetes_delete_pod", "dev", false, false},
+		{app.Identity{User: "bob", Groups: []string{"developers"}}, "docker_stop_container", "", true, false},
+		{app.Identity{User: "carol"}, "kubernetes_get_logs", "dev", false, false},
+		{app.Identity{}, "kubernetes_get_logs", "", false, false},
+	} {
+		access := policy.Access(tc.id)
+		if have := access.AllowControl(tc.control, tc.namespace); have != tc.allowed {
+			t.Errorf("%v: expected %s in %q to be allowed: %v", tc.id, tc.control, tc.namespace, tc.allowed)
+		}
+		if have := access.CanSeeNamespace("prod"); have != tc.canSeeProd {
+			t.Errorf("%v: expected prod to be visible: %v", tc.id, tc.canSeeProd)
+		}
+		if have, want := access.IsAdmin(), tc.id.User == "alice"; have != want {
+			t.Errorf("%v: expected to be an admin: %v", tc.id, want)
+		}
+	}
+
+	var none *app.Access
+	if !none.AllowControl("kubernetes_delete_pod", "prod") || !none.CanSeeNamespace("prod") || !none.IsAdmin() {
+		t.Errorf("expected everything to be allowed without a policy")
+	}
+
+	for _, invalid := range []string{
+		"bindings: [{role: nobody, users: [alice]}]",
+		"roles: [{name: broken, controls: ['[']}]",
+		"defaultRole: nobody",
+	} {
+		if _, err := app.ParsePolicy([]byte(invalid)); err == nil {
+			t.Errorf("expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestRBAC(t *testing.T) {
+	policy, err := app.ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rbac := app.RBAC{Policy: policy, UserHeader: "X-Forwarded-User", GroupsHeader: "X-Forwarded-Groups"}
+
+	rpt := report.MakeReport()
+	rpt.Pod.AddNode(report.MakeNodeWith(report.MakePodNodeID("dev-pod"), map[string]string{
+		report.KubernetesNamespace: "dev",
+		report.ControlProbeID:      "foo",
+	}))
+	rpt.Pod.AddNode(report.MakeNodeWith(report.MakePodNodeID("prod-pod"), map[string]string{
+		report.KubernetesNamespace: "prod",
+		report.ControlProbeID:      "foo",
+	}))
+	rpt.Host.AddNode(report.MakeNode(report.MakeHostNodeID("host")))
+	rpt.Pod.Controls.AddControl(report.Control{ID: "kubernetes_get_logs"})
+	rpt.Host.Controls.AddControl(report.Control{ID: "host_exec"})
+
+	cr := app.NewLocalControlRouter()
+	handled := 0
+	cr.Register(context.Background(), "foo", func(req xfer.Request) xfer.Response {
+		handled++
+		return xfer.Response{}
+	})
+	router := mux.NewRouter()
+	app.RegisterControlRoutes(router, cr, app.StaticCollector(rpt), app.NewAuditLog(10, nil))
+	router.Path("/api/report").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filtered, _ := app.AccessReporter{Reporter: app.StaticCollector(rpt)}.Report(r.Context(), time.Now())
+		for _, t := range []report.Topology{filtered.Pod, filtered.Host} {
+			for id := range t.Nodes {
+				w.Header().Add("X-Nodes", id)
+			}
+		}
+	})
+	server := httptest.NewServer(rbac.Wrap(router))
+	defer server.Close()
+
+	do := func(method, path, user, groups string) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+path, nil)
+		if user != "" {
+			req.Header.Set("X-Forwarded-User", user)
+			req.Header.Set("X-Forwarded-Groups", groups)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	devPod, prodPod := report.MakePodNodeID("dev-pod"), report.MakePodNodeID("prod-pod")
+	for _, tc := range []struct {
+		user, groups, nodeID string
+		status               int
+	}{
+		{"alice", "", prodPod, http.StatusOK},
+		{"bob", "qa, developers", devPod, http.StatusOK},
+		{"bob", "qa, developers", prodPod, http.StatusForbidden},
+		{"carol", "", devPod, http.StatusForbidden},
+		{"", "", devPod, http.StatusForbidden},
+		// The namespace of unknown nodes isn't known
+		{"bob", "developers", report.MakePodNodeID("unknown-pod"), http.StatusForbidden},
+		{"alice", "", report.MakePodNodeID("unknown-pod"), http.StatusOK},
+	} {
+		handled = 0
+		resp := do("POST", "/api/control/foo/"+tc.nodeID+"/kubernetes_get_logs", tc.user, tc.groups)
+		if resp.StatusCode != tc.status {
+			t.Errorf("%s on %s: expected status %d, got %d", tc.user, tc.nodeID, tc.status, resp.StatusCode)
+		}
+		if want := tc.status == http.StatusOK; (handled == 1) != want {
+			t.Errorf("%s on %s: expected the probe to handle the control: %v", tc.user, tc.nodeID, want)
+		}
+	}
+
+	if have, want := do("GET", "/api/report", "bob", "developers").Header["X-Nodes"], []string{devPod, report.MakeHostNodeID("host")}; !reflect.DeepEqual(have, want) {
+		t.Errorf("expected nodes %v, got %v", want, have)
+	}
+	if have := len(rpt.Pod.Nodes); have != 2 {
+		t.Errorf("expected the report not to be modified, got %d pods", have)
+	}
+
+	// Basic authentication users are only trusted once verified
+	req, _ := http.NewRequest("POST", server.URL+"/api/control/foo/"+prodPod+"/kubernetes_get_logs", nil)
+	req.SetBasicAuth("alice", "guess")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected unverified basic authentication users to be forbidden, got %d", resp.StatusCode)
+	}
+}
+
+func TestFilterReport(t *testing.T) {
+	policy, err := app.ParsePolicy([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		rpt             = report.MakeReport()
+		hostID          = "host"
+		devContainer    = report.MakeContainerNodeID("dev")
+		prodContainer   = report.MakeContainerNodeID("prod")
+		devProcess      = report.MakeProcessNodeID(hostID, "1")
+		prodProcess     = report.MakeProcessNodeID(hostID, "2")
+		prodEndpoint    = report.MakeEndpointNodeID(hostID, "", "10.0.0.2", "80")
+		prodPodEndpoint = report.MakeEndpointNodeID(hostID, "", "10.0.1.2", "80")
+		devEndpoint     = report.MakeEndpointNodeID(hostID, "", "10.0.0.1", "80")
+		sharedImage     = report.MakeContainerImageNodeID("shared")
+		prodImage       = report.MakeContainerImageNodeID("prod")
+	)
+	namespaceLabel := report.DockerLabelPrefix + "io.kubernetes.pod.namespace"
+	rpt.Container.AddNode(report.MakeNodeWith(devContainer, map[string]string{namespaceLabel: "dev", report.DockerImageID: "shared"}))
+	rpt.Container.AddNode(report.MakeNodeWith(prodContainer, map[string]string{namespaceLabel: "prod", report.DockerImageID: "prod"}))
+	rpt.Container.AddNode(report.MakeNodeWith(report.MakeContainerNodeID("prod-2"), map[string]string{namespaceLabel: "prod", report.DockerImageID: "shared"}))
+	rpt.Pod.AddNode(report.MakeNodeWith(report.MakePodNodeID("prod-pod"), map[string]string{
+		report.KubernetesNamespace: "prod",
+		report.KubernetesIP:        "10.0.1.2",
+	}))
+	rpt.ContainerImage.AddNode(report.MakeNode(sharedImage))
+	rpt.ContainerImage.AddNode(report.MakeNode(prodImage))
+	rpt.Process.AddNode(report.MakeNodeWith(devProcess, map[string]string{report.DockerContainerID: "dev"}))
+	rpt.Process.AddNode(report.MakeNodeWith(prodProcess, map[string]string{report.DockerContainerID: "prod"}))
+	rpt.Endpoint.AddNode(report.MakeNodeWith(devEndpoint, map[string]string{report.PID: "1", report.HostNodeID: report.MakeHostNodeID(hostID)}))
+	rpt.Endpoint.AddNode(report.MakeNodeWith(prodEndpoint, map[string]string{report.PID: "2", report.HostNodeID: report.MakeHostNodeID(hostID)}))
+	rpt.Endpoint.AddNode(report.MakeNode(prodPodEndpoint))
+
+	access := policy.Access(app.Identity{User: "bob", Groups: []string{"developers"}})
+	filtered := access.FilterReport(rpt)
+	for _, tc := range []struct {
+		topology report.Topology
+		want     []string
+	}{
+		{filtered.Container, []string{devContainer}},
+		{filtered.Process, []string{devProcess}},
+		{filtered.Endpoint, []string{devEndpoint}},
+		{filtered.ContainerImage, []string{sharedImage}},
+	} {
+		have := []string{}
+		for id := range tc.topology.Nodes {
+			have = append(have, id)
+		}
+		if !reflect.DeepEqual(have, tc.want) {
+			t.Errorf("expected nodes %v, got %v", tc.want, have)
+		}
+	}
+
+	// Accesses seeing the same namespaces get the same report
+	again := policy.Access(app.Identity{User: "dave", Groups: []string{"developers"}}).FilterReport(rpt)
+	if again.ID != filtered.ID {
+		t.Errorf("expected the same report ID for the same namespaces, got %s and %s", filtered.ID, again.ID)
+	}
+	if other := policy.Access(app.Identity{User: "alice"}).FilterReport(rpt); other.ID == filtered.ID {
+		t.Errorf("expected another report ID for other namespaces")
+	}
+}
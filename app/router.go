@@ -102,6 +102,15 @@ func RegisterTopologyRoutes(router *mux.Router, r Reporter, capabilities map[str
 	get.Handle("/api/topology/{topology}/ws",
 		requestContextDecorator(captureReporter(r, handleWebsocket))). // NB not gzip!
 		Name("api_topology_topology_ws")
+	get.Handle("/api/topology/{topology}/diff",
+		gzipHandler(requestContextDecorator(captureReporter(r, handleTopologyDiff)))).
+		Name("api_topology_topology_diff")
+	get.Handle("/api/topology/{topology}/edges",
+		gzipHandler(requestContextDecorator(topologyRegistry.captureRenderer(r, handleTopologyEdges)))).
+		Name("api_topology_topology_edges")
+	get.MatcherFunc(URLMatcher("/api/topology/{topology}/edge/{from}/{to}")).Handler(
+		gzipHandler(requestContextDecorator(topologyRegistry.captureRenderer(r, handleEdge)))).
+		Name("api_topology_topology_edge")
 	get.MatcherFunc(URLMatcher("/api/topology/{topology}/{id}")).Handler(
 		gzipHandler(requestContextDecorator(topologyRegistry.captureRenderer(r, handleNode)))).
 		Name("api_topology_topology_id")
@@ -143,8 +152,21 @@ func RegisterReportPostHandler(a Adder, router *mux.Router) {
 			return
 		}
 
+		// Probes authenticated by certificate are known by its name, and
+		// others can't claim one
+		identity, hasIdentity := ClientCertIdentity(r)
+		rewrite := hasIdentity
+		for id, n := range rpt.Host.Nodes {
+			if hasIdentity {
+				rpt.Host.Nodes[id] = n.WithLatests(map[string]string{report.ProbeIdentity: identity})
+			} else if _, ok := n.Latest.Lookup(report.ProbeIdentity); ok {
+				rpt.Host.Nodes[id] = withoutLatest(n, report.ProbeIdentity)
+				rewrite = true
+			}
+		}
+
 		// a.Add(..., buf) assumes buf is gzip'd msgpack
-		if !isMsgpack {
+		if !isMsgpack || rewrite {
 			buf, _ = rpt.WriteBinary()
 		}
 
@@ -157,13 +179,29 @@ func RegisterReportPostHandler(a Adder, router *mux.Router) {
 	}))
 }
 
+// withoutLatest returns the node without a latest value.
+func withoutLatest(n report.Node, key string) report.Node {
+	latest := report.MakeStringLatestMap()
+	n.Latest.ForEach(func(k string, timestamp time.Time, v string) {
+		if k != key {
+			latest = latest.Set(k, timestamp, v)
+		}
+	})
+	n.Latest = latest
+	return n
+}
+
 // RegisterAdminRoutes registers routes for admin calls with a http mux.
 func RegisterAdminRoutes(router *mux.Router, reporter Reporter) {
 	get := router.Methods("GET").Subrouter()
 	get.Handle("/admin/summary", requestContextDecorator(func(ctx context.Context, w http.ResponseWriter, r *http.Request) {
 		summary, err := reporter.AdminSummary(ctx, time.Now())
-		if err != nil {
+		if err == errForbidden {
+			respondWith(w, http.StatusForbidden, err)
+			return
+		} else if err != nil {
 			respondWith(w, http.StatusBadRequest, err)
+			return
 		}
 		fmt.Fprintln(w, summary)
 	}))
@@ -0,0 +1,24 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/weaveworks/scope/report"
+)
+
+func TestWithoutLatest(t *testing.T) {
+	n := report.MakeNodeWith(report.MakeHostNodeID("host"), map[string]string{
+		report.ControlProbeID: "probe",
+		report.ProbeIdentity:  "forged",
+	})
+	stripped := withoutLatest(n, report.ProbeIdentity)
+	if _, ok := stripped.Latest.Lookup(report.ProbeIdentity); ok {
+		t.Errorf("expected the probe identity to be removed, got %v", stripped.Latest)
+	}
+	if probeID, _ := stripped.Latest.Lookup(report.ControlProbeID); probeID != "probe" {
+		t.Errorf("expected the other values to be kept, got %v", stripped.Latest)
+	}
+	if _, ok := n.Latest.Lookup(report.ProbeIdentity); !ok {
+		t.Errorf("expected the node not to be modified")
+	}
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/render/detailed"
+	"github.com/weaveworks/scope/report"
+)
+
+const (
+	topologyMetricsNamespace = "scope"
+	topologyMetricsCached    = 16
+)
+
+var (
+	invalidMetricNameChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+	topologyMetricLabels   = []string{"topology", "node_id", "label", "host", "pod", "namespace", "metric"}
+
+	topologyNodesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(topologyMetricsNamespace, "topology", "nodes"),
+		"Number of nodes in the rendered topology.",
+		[]string{"topology"}, nil,
+	)
+	topologyEdgesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(topologyMetricsNamespace, "topology", "edges"),
+		"Number of edges in the rendered topology.",
+		[]string{"topology"}, nil,
+	)
+	topologyEdgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(topologyMetricsNamespace, "topology", "edge"),
+		"Edge between two nodes of the rendered topology; always 1.",
+		[]string{"topology", "source_id", "source_label", "target_id", "target_label"}, nil,
+	)
+)
+
+// RegisterTopologyMetricsRoute registers /api/metrics, which exposes the
+// latest sample of every metric of the rendered topologies' nodes, and their
+// edge counts, in the Prometheus exposition format. With edges, every edge
+// between rendered nodes is a series of its own too.
+func RegisterTopologyMetricsRoute(router *mux.Router, r Reporter, edges bool) {
+	cache := gcache.New(topologyMetricsCached).LRU().Build()
+	router.Methods("GET").Path("/api/metrics").Handler(requestContextDecorator(func(ctx context.Context, w http.ResponseWriter, req *http.Request) {
+		// Reports depend on the tenant of the request, which collectors
+		// find in its context.
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(&topologyMetricsCollector{
+			ctx:      ctx,
+			reporter: r,
+			registry: topologyRegistry,
+			cache:    cache,
+			edges:    edges,
+		})
+		promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorLog: log.StandardLogger()}).ServeHTTP(w, req)
+	}))
+}
+
+// topologyMetricsCollector is an unchecked prometheus.Collector: the set of
+// metrics depends on the current report, so it cannot be described upfront.
+type topologyMetricsCollector struct {
+	ctx      context.Context
+	reporter Reporter
+	registry *Registry
+	// cache holds the metrics of the latest reports by ID, as reports
+	// change less often than they are scraped.
+	cache gcache.Cache
+	edges bool
+}
+
+// Describe implements prometheus.Collector.
+func (c *topologyMetricsCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (c *topologyMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	rpt, err := c.reporter.Report(c.ctx, time.Now())
+	if err != nil {
+		log.Errorf("Error getting report for topology metrics: %v", err)
+		return
+	}
+	if cached, err := c.cache.Get(rpt.ID); err == nil {
+		for _, m := range cached.([]prometheus.Metric) {
+			ch <- m
+		}
+		return
+	}
+	var metrics []prometheus.Metric
+	descs := map[string]*prometheus.Desc{}
+	c.registry.walkForReport(rpt, func(desc APITopologyDesc) {
+		metrics = c.collectTopology(metrics, descs, rpt, desc.id)
+		for _, sub := range desc.SubTopologies {
+			metrics = c.collectTopology(metrics, descs, rpt, sub.id)
+		}
+	})
+	c.cache.Set(rpt.ID, metrics)
+	for _, m := range metrics {
+		ch <- m
+	}
+}
+
+func (c *topologyMetricsCollector) collectTopology(metrics []prometheus.Metric, descs map[string]*prometheus.Desc, rpt report.Report, topologyID string) []prometheus.Metric {
+	renderer, filter, err := c.registry.RendererForTopology(topologyID, url.Values{}, rpt)
+	if err != nil {
+		log.Errorf("Error rendering topology metrics for %s: %v", topologyID, err)
+		return metrics
+	}
+	nodes := render.Render(c.ctx, rpt, renderer, filter).Nodes
+
+	labels := make(map[string]string, len(nodes))
+	for id, n := range nodes {
+		if summary, ok := detailed.MakeBasicNodeSummary(rpt, n); ok {
+			labels[id] = summary.Label
+		} else {
+			labels[id] = id
+		}
+	}
+
+	edges := 0
+	for id, n := range nodes {
+		var host, pod, namespace string
+		if len(n.Metrics) > 0 {
+			host, pod, namespace = parentLabels(rpt, n)
+		}
+		for metricID, metric := range n.Metrics {
+			sample, ok := metric.LastSample()
+			if !ok {
+				continue
+			}
+			metrics = append(metrics, prometheus.MustNewConstMetric(metricDesc(descs, metricID), prometheus.GaugeValue, sample.Value,
+				topologyID, id, labels[id], host, pod, namespace, metricID))
+		}
+		for _, target := range n.Adjacency {
+			targetLabel, ok := labels[target]
+			if !ok {
+				continue
+			}
+			edges++
+			if c.edges {
+				metrics = append(metrics, prometheus.MustNewConstMetric(topologyEdgeDesc, prometheus.GaugeValue, 1,
+					topologyID, id, labels[id], target, targetLabel))
+			}
+		}
+	}
+	return append(metrics,
+		prometheus.MustNewConstMetric(topologyNodesDesc, prometheus.GaugeValue, float64(len(nodes)), topologyID),
+		prometheus.MustNewConstMetric(topologyEdgesDesc, prometheus.GaugeValue, float64(edges), topologyID))
+}
+
+// metricDesc returns the descriptor for a node metric, named after the
+// metric ID, e.g. scope_node_process_cpu_usage_percent. IDs which differ only
+// in characters invalid in metric names, like a.b and a-b, share a descriptor
+// and are told apart by the metric label, which holds the raw ID.
+func metricDesc(descs map[string]*prometheus.Desc, metricID string) *prometheus.Desc {
+	name := prometheus.BuildFQName(topologyMetricsNamespace, "node", invalidMetricNameChars.ReplaceAllString(metricID, "_"))
+	if desc, ok := descs[name]; ok {
+		return desc
+	}
+	desc := prometheus.NewDesc(name, "Latest sample of a node metric of a rendered node; the metric label is its ID.", topologyMetricLabels, nil)
+	descs[name] = desc
+	return desc
+}
+
+// parentLabels returns the labels of the host and pod the node belongs to,
+// and its namespace, when known.
+func parentLabels(rpt report.Report, n report.Node) (host, pod, namespace string) {
+	for _, parent := range detailed.Parents(rpt, n) {
+		switch parent.TopologyID {
+		case hostsID:
+			host = parent.Label
+		case podsID:
+			pod = parent.Label
+		}
+	}
+	namespace, _ = n.Latest.Lookup(report.KubernetesNamespace)
+	return host, pod, namespace
+}
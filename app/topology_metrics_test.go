@@ -0,0 +1,107 @@
+package app_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/weaveworks/scope/app"
+	"github.com/weaveworks/scope/report"
+	"github.com/weaveworks/scope/test/fixture"
+)
+
+func TestTopologyMetrics(t *testing.T) {
+	router := mux.NewRouter().SkipClean(true)
+	app.RegisterTopologyMetricsRoute(router, app.StaticCollector(fixture.Report), true)
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	body := string(is200(t, ts, "/api/metrics"))
+	if again := string(is200(t, ts, "/api/metrics")); again != body {
+		t.Errorf("expected the metrics of the same report to be the same")
+	}
+	for _, want := range []string{
+		`scope_topology_nodes{topology="hosts"}`,
+		`scope_topology_edges{topology="containers"}`,
+		fmt.Sprintf(`scope_node_%s{host="",label="client",metric=%q,namespace="",node_id=%q,pod="",topology="hosts"} 0.07`,
+			report.HostCPUUsage, report.HostCPUUsage, fixture.ClientHostNodeID),
+		fmt.Sprintf(`scope_topology_edge{source_id=%q,source_label="client",target_id=%q,target_label="server",topology="hosts"} 1`,
+			fixture.ClientHostNodeID, fixture.ServerHostNodeID),
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected output to contain %s", want)
+		}
+	}
+}
+
+func TestTopologyMetricsSanitizedNames(t *testing.T) {
+	rpt := fixture.Report.Copy()
+	host := rpt.Host.Nodes[fixture.ClientHostNodeID]
+	rpt.Host.Nodes[fixture.ClientHostNodeID] = host.WithMetrics(report.Metrics{
+		"a.b": report.MakeSingletonMetric(fixture.Now, 1),
+		"a-b": report.MakeSingletonMetric(fixture.Now, 2),
+	})
+	router := mux.NewRouter().SkipClean(true)
+	app.RegisterTopologyMetricsRoute(router, app.StaticCollector(rpt), false)
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	// Both IDs map to scope_node_a_b, and must not fail the scrape
+	body := string(is200(t, ts, "/api/metrics"))
+	for _, want := range []string{
+		fmt.Sprintf(`scope_node_a_b{host="",label="client",metric="a.b",namespace="",node_id=%q,pod="",topology="hosts"} 1`, fixture.ClientHostNodeID),
+		fmt.Sprintf(`scope_node_a_b{host="",label="client",metric="a-b",namespace="",node_id=%q,pod="",topology="hosts"} 2`, fixture.ClientHostNodeID),
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected output to contain %s", want)
+		}
+	}
+}
+
+// orgReporter only has reports for one tenant, identified by header.
+type orgReporter struct {
+	app.Reporter
+	orgID string
+}
+
+func (r orgReporter) Report(ctx context.Context, timestamp time.Time) (report.Report, error) {
+	if req, ok := ctx.Value(app.RequestCtxKey).(*http.Request); !ok || req.Header.Get("X-Scope-OrgID") != r.orgID {
+		return report.MakeReport(), nil
+	}
+	return r.Reporter.Report(ctx, timestamp)
+}
+
+func TestTopologyMetricsPerTenant(t *testing.T) {
+	router := mux.NewRouter().SkipClean(true)
+	app.RegisterTopologyMetricsRoute(router, orgReporter{Reporter: app.StaticCollector(fixture.Report), orgID: "org"}, false)
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	scrape := func(orgID string) string {
+		req, _ := http.NewRequest("GET", ts.URL+"/api/metrics", nil)
+		req.Header.Set("X-Scope-OrgID", orgID)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return string(body)
+	}
+	hosts := fmt.Sprintf(`node_id=%q`, fixture.ClientHostNodeID)
+	if body := scrape("org"); !strings.Contains(body, hosts) || !strings.Contains(body, `scope_topology_edges{topology="hosts"}`) {
+		t.Errorf("expected the metrics of the tenant")
+	} else if strings.Contains(body, "scope_topology_edge{") {
+		t.Errorf("expected no series per edge unless asked for")
+	}
+	if body := scrape("other"); strings.Contains(body, hosts) {
+		t.Errorf("expected no metrics of other tenants")
+	}
+}
@@ -2,6 +2,8 @@ package appclient
 
 import (
 	"compress/gzip"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -216,3 +218,20 @@ func TestStop(t *testing.T) {
 	// Let the server go so that the test can end
 	close(stopHanging)
 }
+
+func TestProbeConfigTLS(t *testing.T) {
+	cert := tls.Certificate{Certificate: [][]byte{[]byte("cert")}}
+	rootCAs := x509.NewCertPool()
+
+	transport := ProbeConfig{}.getHTTPTransport("app")
+	if transport.TLSClientConfig.RootCAs != certPool || len(transport.TLSClientConfig.Certificates) != 0 {
+		t.Errorf("expected the system CAs and no client certificate by default")
+	}
+	transport = ProbeConfig{ClientCert: &cert, RootCAs: rootCAs}.getHTTPTransport("app")
+	if transport.TLSClientConfig.RootCAs != rootCAs {
+		t.Errorf("expected the given CAs to verify the app")
+	}
+	if certs := transport.TLSClientConfig.Certificates; len(certs) != 1 || string(certs[0].Certificate[0]) != "cert" {
+		t.Errorf("expected the client certificate to be presented, got %v", certs)
+	}
+}
@@ -36,6 +36,12 @@ type ProbeConfig struct {
 	ProbeVersion string
 	ProbeID      string
 	Insecure     bool
+	// ClientCert is the certificate the probe authenticates with to apps
+	// serving TLS, if any.
+	ClientCert *tls.Certificate
+	// RootCAs verify the certificates of apps, instead of the CAs of the
+	// system.
+	RootCAs *x509.CertPool
 }
 
 func (pc ProbeConfig) authorizeHeaders(headers http.Header) {
@@ -65,10 +71,17 @@ func (pc ProbeConfig) getHTTPTransport(hostname string) *http.Transport {
 	if pc.Insecure {
 		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	} else {
+		rootCAs := certPool
+		if pc.RootCAs != nil {
+			rootCAs = pc.RootCAs
+		}
 		transport.TLSClientConfig = &tls.Config{
-			RootCAs:    certPool,
+			RootCAs:    rootCAs,
 			ServerName: hostname,
 		}
 	}
+	if pc.ClientCert != nil {
+		transport.TLSClientConfig.Certificates = []tls.Certificate{*pc.ClientCert}
+	}
 	return transport
 }
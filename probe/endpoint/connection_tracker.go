@@ -9,12 +9,16 @@ import (
 
 	log "github.com/sirupsen/logrus"
 	"github.com/typetypetype/conntrack"
+	"github.com/weaveworks/common/mtime"
 
 	"github.com/weaveworks/scope/probe/endpoint/procspy"
 	"github.com/weaveworks/scope/probe/process"
 	"github.com/weaveworks/scope/report"
 )
 
+// udpNodeInfo marks the endpoints of udp flows.
+var udpNodeInfo = map[string]string{Protocol: UDP}
+
 type connectionTracker struct {
 	conf            ReporterConfig
 	flowWalker      flowWalker // Interface
@@ -23,17 +27,31 @@ type connectionTracker struct {
 
 	// time of the previous ebpf failure, or zero if it didn't fail
 	ebpfLastFailureTime time.Time
+
+	// where the byte and packet counters come from, or why there are none
+	counters string
 }
 
 func newConnectionTracker(conf ReporterConfig) connectionTracker {
 	ct := connectionTracker{
 		conf:            conf,
 		reverseResolver: newReverseResolver(),
+		counters:        countersSource(conf),
 	}
 	if conf.UseEbpfConn {
 		et, err := newEbpfTracker()
 		if err == nil {
 			ct.ebpfTracker = et
+			// The eBPF tracker only tracks tcp connections, and doesn't
+			// count bytes and packets; take udp flows and counters from
+			// conntrack.
+			if conf.UseConntrack && (conf.TrackUDP || isConntrackAccountingEnabled(conf.ProcRoot)) {
+				ct.flowWalker = newConntrackFlowWalker(conf.UseConntrack, conf.ProcRoot, conf.BufferSize, false /* natOnly */, conf.TrackUDP)
+			}
+			// and the processes of udp sockets from /proc.
+			if conf.TrackUDP && conf.WalkProc && conf.Scanner == nil {
+				ct.conf.Scanner = procspy.NewConnectionScanner(conf.ProcessCache, conf.SpyProcs, true)
+			}
 			go ct.getInitialState()
 			return ct
 		}
@@ -43,6 +61,19 @@ func newConnectionTracker(conf ReporterConfig) connectionTracker {
 	return ct
 }
 
+// countersSource tells where the byte and packet counters of connections
+// come from. Whether connections are tracked with eBPF or not, only
+// conntrack accounting counts them.
+func countersSource(conf ReporterConfig) string {
+	if !conf.UseConntrack {
+		return CountersNoConntrack
+	}
+	if !isConntrackAccountingEnabled(conf.ProcRoot) {
+		return CountersNoAccounting
+	}
+	return CountersConntrack
+}
+
 func flowToTuple(f conntrack.Conn) (ft fourTuple) {
 	if f.Orig.Dst.Equal(f.Reply.Src) {
 		return makeFourTuple(f.Orig.Src, f.Orig.Dst, uint16(f.Orig.SrcPort), uint16(f.Orig.DstPort))
@@ -54,16 +85,19 @@ func flowToTuple(f conntrack.Conn) (ft fourTuple) {
 func (t *connectionTracker) useProcfs() {
 	t.ebpfTracker = nil
 	if t.conf.WalkProc && t.conf.Scanner == nil {
-		t.conf.Scanner = procspy.NewConnectionScanner(t.conf.ProcessCache, t.conf.SpyProcs)
+		t.conf.Scanner = procspy.NewConnectionScanner(t.conf.ProcessCache, t.conf.SpyProcs, t.conf.TrackUDP)
 	}
 	if t.flowWalker == nil {
-		t.flowWalker = newConntrackFlowWalker(t.conf.UseConntrack, t.conf.ProcRoot, t.conf.BufferSize, false /* natOnly */)
+		t.flowWalker = newConntrackFlowWalker(t.conf.UseConntrack, t.conf.ProcRoot, t.conf.BufferSize, false /* natOnly */, t.conf.TrackUDP)
 	}
 }
 
 // ReportConnections calls trackers according to the configuration.
 func (t *connectionTracker) ReportConnections(rpt *report.Report) {
 	hostNodeID := report.MakeHostNodeID(t.conf.HostID)
+	rpt.Host.AddNode(report.MakeNodeWith(hostNodeID, map[string]string{
+		report.HostConnectionCounters: t.counters,
+	}))
 
 	if t.ebpfTracker != nil {
 		if !t.ebpfTracker.isDead() {
@@ -96,15 +130,22 @@ func (t *connectionTracker) ReportConnections(rpt *report.Report) {
 	}
 
 	// consult the flowWalker for short-lived (conntracked) connections
+	now := mtime.Now()
 	seenTuples := map[string]fourTuple{}
+	var udpFlows []fourTuple
 	t.flowWalker.walkFlows(func(f conntrack.Conn, alive bool) {
 		tuple := flowToTuple(f)
 		seenTuples[tuple.key()] = tuple
-		t.addConnection(rpt, false, tuple, 0, nil, nil)
+		if f.Orig.Proto == udpProto {
+			udpFlows = append(udpFlows, tuple)
+			t.addConnection(rpt, false, tuple, 0, udpNodeInfo, udpNodeInfo, flowMetrics(f, now))
+			return
+		}
+		t.addConnection(rpt, false, tuple, 0, nil, nil, flowMetrics(f, now))
 	})
 
 	if t.conf.WalkProc && t.conf.Scanner != nil {
-		t.performWalkProc(rpt, hostNodeID, seenTuples)
+		t.performWalkProc(rpt, hostNodeID, seenTuples, udpFlows, false)
 	}
 }
 
@@ -128,12 +169,25 @@ func (t *connectionTracker) existingFlows() map[string]fourTuple {
 	return seenTuples
 }
 
-func (t *connectionTracker) performWalkProc(rpt *report.Report, hostNodeID string, seenTuples map[string]fourTuple) error {
+// performWalkProc adds the connections found in /proc to the report, or
+// only the udp ones with udpOnly, when the eBPF tracker tracks tcp.
+func (t *connectionTracker) performWalkProc(rpt *report.Report, hostNodeID string, seenTuples map[string]fourTuple, udpFlows []fourTuple, udpOnly bool) error {
 	conns, err := t.conf.Scanner.Connections()
 	if err != nil {
 		return err
 	}
+	udpSockets := udpSocketMap{}
 	for conn := conns.Next(); conn != nil; conn = conns.Next() {
+		udp := conn.Transport == procspy.TransportUDP
+		if udpOnly && !udp {
+			continue
+		}
+		if udp && conn.RemotePort == 0 {
+			// Unconnected udp socket; only useful to find the process
+			// of the udp flows seen by conntrack.
+			udpSockets.add(conn)
+			continue
+		}
 		tuple, namespaceID, incoming := connectionTuple(conn, seenTuples)
 		var toNodeInfo, fromNodeInfo map[string]string
 		if conn.Proc.PID > 0 {
@@ -142,11 +196,64 @@ func (t *connectionTracker) performWalkProc(rpt *report.Report, hostNodeID strin
 				report.HostNodeID: hostNodeID,
 			}
 		}
-		t.addConnection(rpt, incoming, tuple, namespaceID, fromNodeInfo, toNodeInfo)
+		if udp {
+			if fromNodeInfo == nil {
+				fromNodeInfo = map[string]string{}
+			}
+			fromNodeInfo[Protocol] = UDP
+			toNodeInfo = udpNodeInfo
+		}
+		t.addConnection(rpt, incoming, tuple, namespaceID, fromNodeInfo, toNodeInfo, nil)
+	}
+
+	for _, tuple := range udpFlows {
+		t.addUDPSocketProcess(rpt, hostNodeID, udpSockets, tuple.fromAddr[:], tuple.fromPort)
+		t.addUDPSocketProcess(rpt, hostNodeID, udpSockets, tuple.toAddr[:], tuple.toPort)
 	}
 	return nil
 }
 
+// udpSocketMap maps the local addresses of unconnected udp sockets to
+// their processes.
+type udpSocketMap map[string]procspy.Proc
+
+func udpSocketKey(addr net.IP, port uint16) string {
+	if addr.IsUnspecified() {
+		addr = nil
+	}
+	return net.JoinHostPort(addr.String(), strconv.Itoa(int(port)))
+}
+
+func (m udpSocketMap) add(conn *procspy.Connection) {
+	if conn.Proc.PID > 0 {
+		m[udpSocketKey(conn.LocalAddress, conn.LocalPort)] = conn.Proc
+	}
+}
+
+// lookup finds the socket bound to addr:port, or to any address and port.
+// The latter can be wrong for flows which are only forwarded by this host.
+func (m udpSocketMap) lookup(addr net.IP, port uint16) (procspy.Proc, bool) {
+	if proc, ok := m[udpSocketKey(addr, port)]; ok {
+		return proc, true
+	}
+	proc, ok := m[udpSocketKey(nil, port)]
+	return proc, ok
+}
+
+// addUDPSocketProcess associates the endpoint of a udp flow with the
+// process owning the socket it was sent from or to, if any.
+func (t *connectionTracker) addUDPSocketProcess(rpt *report.Report, hostNodeID string, sockets udpSocketMap, addr net.IP, port uint16) {
+	proc, ok := sockets.lookup(addr, port)
+	if !ok {
+		return
+	}
+	rpt.Endpoint.AddNode(t.makeEndpointNode(0, addr, port, map[string]string{
+		process.PID:       strconv.FormatUint(uint64(proc.PID), 10),
+		report.HostNodeID: hostNodeID,
+		Protocol:          UDP,
+	}))
+}
+
 // getInitialState runs conntrack and proc parsing synchronously only
 // once to initialize ebpfTracker
 func (t *connectionTracker) getInitialState() {
@@ -155,7 +262,9 @@ func (t *connectionTracker) getInitialState() {
 	processCache = process.NewCachingWalker(walker)
 	processCache.Tick()
 
-	scanner := procspy.NewSyncConnectionScanner(processCache, t.conf.SpyProcs)
+	// The eBPF tracker only tracks tcp connections; udp sockets are read
+	// from /proc with each report, by performEbpfTrack.
+	scanner := procspy.NewSyncConnectionScanner(processCache, t.conf.SpyProcs, false)
 
 	// Consult conntrack to get the initial state
 	seenTuples := t.existingFlows()
@@ -177,6 +286,24 @@ func (t *connectionTracker) getInitialState() {
 }
 
 func (t *connectionTracker) performEbpfTrack(rpt *report.Report, hostNodeID string) error {
+	counters := map[string]report.Metrics{}
+	udpTuples := map[string]fourTuple{}
+	var udpFlows []fourTuple
+	if t.flowWalker != nil {
+		now := mtime.Now()
+		t.flowWalker.walkFlows(func(f conntrack.Conn, alive bool) {
+			tuple := flowToTuple(f)
+			if f.Orig.Proto == udpProto {
+				udpTuples[tuple.key()] = tuple
+				udpFlows = append(udpFlows, tuple)
+				t.addConnection(rpt, false, tuple, 0, udpNodeInfo, udpNodeInfo, flowMetrics(f, now))
+				return
+			}
+			if metrics := flowMetrics(f, now); metrics != nil {
+				counters[tuple.key()] = metrics
+			}
+		})
+	}
 	t.ebpfTracker.walkConnections(func(e ebpfConnection) {
 		var toNodeInfo, fromNodeInfo map[string]string
 		if e.pid > 0 {
@@ -185,12 +312,19 @@ func (t *connectionTracker) performEbpfTrack(rpt *report.Report, hostNodeID stri
 				report.HostNodeID: hostNodeID,
 			}
 		}
-		t.addConnection(rpt, e.incoming, e.tuple, e.networkNamespace, fromNodeInfo, toNodeInfo)
+		t.addConnection(rpt, e.incoming, e.tuple, e.networkNamespace, fromNodeInfo, toNodeInfo, counters[e.tuple.key()])
 	})
+	if t.conf.Scanner != nil {
+		return t.performWalkProc(rpt, hostNodeID, udpTuples, udpFlows, true)
+	}
 	return nil
 }
 
-func (t *connectionTracker) addConnection(rpt *report.Report, incoming bool, ft fourTuple, namespaceID uint32, extraFromNode, extraToNode map[string]string) {
+// addConnection adds the endpoints of a connection to the report. The
+// connection's byte and packet counters, if any, are attached to the
+// endpoint the connection is made from, keyed by the endpoint it is made
+// to.
+func (t *connectionTracker) addConnection(rpt *report.Report, incoming bool, ft fourTuple, namespaceID uint32, extraFromNode, extraToNode map[string]string, counters report.Metrics) {
 	if incoming {
 		ft = reverse(ft)
 		extraFromNode, extraToNode = extraToNode, extraFromNode
@@ -201,6 +335,13 @@ func (t *connectionTracker) addConnection(rpt *report.Report, incoming bool, ft
 		toAddr   = net.IP(ft.toAddr[:])
 		toNode   = t.makeEndpointNode(namespaceID, toAddr, ft.toPort, extraToNode)
 	)
+	if counters != nil {
+		flowCounters := make(report.Metrics, len(counters))
+		for key, metric := range counters {
+			flowCounters[report.MakeFlowCounterKey(key, toNode.ID)] = metric
+		}
+		fromNode = fromNode.WithMetrics(flowCounters)
+	}
 	rpt.Endpoint.AddNode(fromNode.WithAdjacent(toNode.ID))
 	rpt.Endpoint.AddNode(toNode)
 	t.addDNS(rpt, fromAddr.String())
@@ -208,7 +349,9 @@ func (t *connectionTracker) addConnection(rpt *report.Report, incoming bool, ft
 }
 
 func (t *connectionTracker) makeEndpointNode(namespaceID uint32, addr net.IP, port uint16, extra map[string]string) report.Node {
-	node := report.MakeNodeWith(report.MakeEndpointNodeIDB(t.conf.HostID, namespaceID, addr, port), nil)
+	// tcp and udp endpoints on the same address and port are different nodes
+	id := report.MakeProtocolEndpointNodeID(report.MakeEndpointNodeIDB(t.conf.HostID, namespaceID, addr, port), extra[Protocol])
+	node := report.MakeNodeWith(id, nil)
 	if extra != nil {
 		node = node.WithLatests(extra)
 	}
@@ -6,20 +6,30 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/armon/go-metrics"
 	log "github.com/sirupsen/logrus"
 	"github.com/typetypetype/conntrack"
+
+	"github.com/weaveworks/scope/report"
 )
 
 const (
 	// From https://www.kernel.org/doc/Documentation/networking/nf_conntrack-sysctl.txt
 	eventsPath = "sys/net/netfilter/nf_conntrack_events"
+	acctPath   = "sys/net/netfilter/nf_conntrack_acct"
 	timeWait   = "TIME_WAIT"
 	tcpClose   = "CLOSE"
 	tcpProto   = 6
+	udpProto   = 17
+
+	// conntrack only sends events when the state of a flow changes, so
+	// the counters of long-lived flows have to be refreshed periodically,
+	// from a dump of the table.
+	countersRefreshInterval = 3 * time.Second
 )
 
 // flowWalker is something that maintains flows, and provides an accessor
@@ -42,11 +52,13 @@ type conntrackWalker struct {
 	bufferedFlows []conntrack.Conn          // flows coming out of activeFlows spend 1 walk cycle here
 	bufferSize    int
 	natOnly       bool
+	udp           bool // also track udp flows
+	accounting    bool // refresh the byte and packet counters of active flows
 	quit          chan struct{}
 }
 
 // newConntracker creates and starts a new conntracker.
-func newConntrackFlowWalker(useConntrack bool, procRoot string, bufferSize int, natOnly bool) flowWalker {
+func newConntrackFlowWalker(useConntrack bool, procRoot string, bufferSize int, natOnly, udp bool) flowWalker {
 	if !useConntrack {
 		return nilFlowWalker{}
 	} else if err := IsConntrackSupported(procRoot); err != nil {
@@ -57,9 +69,14 @@ func newConntrackFlowWalker(useConntrack bool, procRoot string, bufferSize int,
 		activeFlows: map[uint32]conntrack.Conn{},
 		bufferSize:  bufferSize,
 		natOnly:     natOnly,
+		udp:         udp,
+		accounting:  !natOnly && isConntrackAccountingEnabled(procRoot),
 		quit:        make(chan struct{}),
 	}
 	go result.loop()
+	if result.accounting {
+		go result.refreshLoop()
+	}
 	return result
 }
 
@@ -77,6 +94,40 @@ var IsConntrackSupported = func(procRoot string) error {
 	return nil
 }
 
+// isConntrackAccountingEnabled tells whether the kernel counts the bytes and
+// packets of conntrack flows.
+func isConntrackAccountingEnabled(procRoot string) bool {
+	contents, err := ioutil.ReadFile(filepath.Join(procRoot, acctPath))
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(contents)) == "1"
+}
+
+// flowMetrics returns the byte and packet counters of a flow, summed over
+// both directions, or nil if the flow has no counters (when conntrack
+// accounting is disabled).
+func flowMetrics(f conntrack.Conn, t time.Time) report.Metrics {
+	// The conntrack library stores the packet count in the *PktLen fields
+	// and the byte count in the *PktCount fields.
+	packets := f.OrigPktLen + f.ReplyPktLen
+	bytes := f.OrigPktCount + f.ReplyPktCount
+	if packets == 0 && bytes == 0 {
+		return nil
+	}
+	return report.Metrics{
+		Bytes:   report.MakeSingletonMetric(t, float64(bytes)),
+		Packets: report.MakeSingletonMetric(t, float64(packets)),
+	}
+}
+
+// withCounters returns f with the counters of other.
+func withCounters(f, other conntrack.Conn) conntrack.Conn {
+	f.OrigPktLen, f.OrigPktCount = other.OrigPktLen, other.OrigPktCount
+	f.ReplyPktLen, f.ReplyPktCount = other.ReplyPktLen, other.ReplyPktCount
+	return f
+}
+
 func (c *conntrackWalker) loop() {
 	// conntrack can sometimes fail with ENOBUFS, when there is a particularly
 	// high connection rate.  In these cases just retry in a loop, so we can
@@ -107,10 +158,16 @@ func (c *conntrackWalker) clearFlows() {
 }
 
 func (c *conntrackWalker) relevant(f conntrack.Conn) bool {
-	// For now, we're only interested in tcp connections - there is too much udp
-	// traffic going on (every container talking to dns, for example) to
-	// render nicely. TODO: revisit this.
-	if f.Orig.Proto != tcpProto {
+	// By default, we're only interested in tcp connections - there is too
+	// much udp traffic going on (every container talking to dns, for
+	// example) to render nicely, so udp flows have to be asked for.
+	switch f.Orig.Proto {
+	case tcpProto:
+	case udpProto:
+		if !c.udp {
+			return false
+		}
+	default:
 		return false
 	}
 	return !(c.natOnly && (f.Status&conntrack.IPS_NAT_MASK) == 0)
@@ -165,6 +222,40 @@ func (c *conntrackWalker) run() {
 	}
 }
 
+// refreshLoop refreshes the counters of the active flows until the walker
+// is stopped. Dumping the conntrack table takes a while on busy hosts, so
+// it's done apart from the event loop, not to delay the handling of events.
+func (c *conntrackWalker) refreshLoop() {
+	ticker := time.NewTicker(countersRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.refreshCounters()
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// refreshCounters updates the counters of the active flows from a dump of
+// the conntrack table. The table is dumped without holding the lock.
+func (c *conntrackWalker) refreshCounters() {
+	flows, err := conntrack.ConnectionsSize(c.bufferSize)
+	if err != nil {
+		log.Errorf("conntrack Connections error: %v", err)
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	for _, flow := range flows {
+		// Events handled since the dump may carry newer counters already
+		if active, ok := c.activeFlows[flow.CtId]; ok && flow.OrigPktCount+flow.ReplyPktCount >= active.OrigPktCount+active.ReplyPktCount {
+			c.activeFlows[flow.CtId] = withCounters(active, flow)
+		}
+	}
+}
+
 func (c *conntrackWalker) stop() {
 	c.Lock()
 	defer c.Unlock()
@@ -188,6 +279,10 @@ func (c *conntrackWalker) handleFlow(f conntrack.Conn) {
 	case f.MsgType == conntrack.NfctMsgDestroy:
 		if active, ok := c.activeFlows[f.CtId]; ok {
 			delete(c.activeFlows, f.CtId)
+			if c.accounting {
+				// destroy events carry the final counters
+				active = withCounters(active, f)
+			}
 			c.bufferedFlows = append(c.bufferedFlows, active)
 		}
 	}
@@ -55,9 +55,13 @@ func toMapping(f conntrack.Conn) *endpointMapping {
 func (n natMapper) applyNAT(rpt report.Report, scope string) {
 	n.flowWalker.walkFlows(func(f conntrack.Conn, _ bool) {
 		mapping := toMapping(f)
+		var protocol string
+		if f.Orig.Proto == udpProto {
+			protocol = UDP
+		}
 
-		realEndpointID := report.MakeEndpointNodeIDB(scope, 0, mapping.originalIP, mapping.originalPort)
-		copyEndpointID := report.MakeEndpointNodeIDB(scope, 0, mapping.rewrittenIP, mapping.rewrittenPort)
+		realEndpointID := report.MakeProtocolEndpointNodeID(report.MakeEndpointNodeIDB(scope, 0, mapping.originalIP, mapping.originalPort), protocol)
+		copyEndpointID := report.MakeProtocolEndpointNodeID(report.MakeEndpointNodeIDB(scope, 0, mapping.rewrittenIP, mapping.rewrittenPort), protocol)
 
 		node, ok := rpt.Endpoint.Nodes[realEndpointID]
 		if !ok {
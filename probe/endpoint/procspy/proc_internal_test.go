@@ -65,7 +65,7 @@ func TestWalkProcPid(t *testing.T) {
 	walker := process.NewWalker(procRoot, false)
 	ticker := time.NewTicker(time.Millisecond)
 	defer ticker.Stop()
-	pWalker := newPidWalker(walker, ticker.C, 1)
+	pWalker := newPidWalker(walker, ticker.C, 1, false)
 	have, err := pWalker.walk(&buf)
 	if err != nil {
 		t.Fatal(err)
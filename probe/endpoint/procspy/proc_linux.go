@@ -38,14 +38,16 @@ func tcp6FileExists() bool {
 
 type pidWalker struct {
 	walker      process.Walker
+	udp         bool             // Also read UDP sockets
 	tickc       <-chan time.Time // Rate-limit clock. Sets the pace when traversing namespaces and /proc/PID/fd/* files.
 	stopc       chan struct{}    // Abort walk
 	fdBlockSize uint64           // Maximum number of /proc/PID/fd/* files to stat() per tick
 }
 
-func newPidWalker(walker process.Walker, tickc <-chan time.Time, fdBlockSize uint64) pidWalker {
+func newPidWalker(walker process.Walker, tickc <-chan time.Time, fdBlockSize uint64, udp bool) pidWalker {
 	w := pidWalker{
 		walker:      walker,
+		udp:         udp,
 		tickc:       tickc,
 		fdBlockSize: fdBlockSize,
 		stopc:       make(chan struct{}),
@@ -119,10 +121,31 @@ func ReadTCPFiles(pid int, buf *bytes.Buffer) (int64, error) {
 	return read + read6, errRead6
 }
 
+// ReadUDPFiles reads the proc files udp and udp6 for a pid
+func ReadUDPFiles(pid int, buf *bytes.Buffer) (int64, error) {
+	var (
+		errRead  error
+		errRead6 error
+		read     int64
+		read6    int64
+	)
+
+	dirName := strconv.Itoa(pid)
+	read, errRead = readFile(filepath.Join(procRoot, dirName, "/net/udp"), buf)
+	if ipv6IsSupported {
+		read6, errRead6 = readFile(filepath.Join(procRoot, dirName, "/net/udp6"), buf)
+	}
+
+	if errRead != nil {
+		return read + read6, errRead
+	}
+	return read + read6, errRead6
+}
+
 // Read the connections for a group of processes living in the same namespace,
-// which are found (identically) in /proc/PID/net/tcp{,6} for any of the
-// processes.
-func readProcessConnections(buf *bytes.Buffer, namespaceProcs []*process.Process) (bool, error) {
+// which are found (identically) in /proc/PID/net/tcp{,6} (and udp{,6}) for
+// any of the processes.
+func readProcessConnections(buf *bytes.Buffer, namespaceProcs []*process.Process, udp bool) (bool, error) {
 	var (
 		read int64
 		err  error
@@ -133,6 +156,13 @@ func readProcessConnections(buf *bytes.Buffer, namespaceProcs []*process.Process
 			// try next process
 			continue
 		}
+		if udp {
+			var readUDP int64
+			if readUDP, err = ReadUDPFiles(p.PID, buf); err != nil {
+				log.Debugf("Error reading UDP sockets of process %d: %v", p.PID, err)
+			}
+			read += readUDP
+		}
 		// Return after succeeding on any process
 		// (proc/PID/net/tcp and proc/PID/net/tcp6 are identical for all the processes in the same namespace)
 		return read > 0, nil
@@ -148,7 +178,7 @@ func readProcessConnections(buf *bytes.Buffer, namespaceProcs []*process.Process
 // walkNamespace does the work of walk for a single namespace
 func (w pidWalker) walkNamespace(namespaceID uint32, buf *bytes.Buffer, sockets map[uint64]*Proc, namespaceProcs []*process.Process) error {
 
-	if found, err := readProcessConnections(buf, namespaceProcs); err != nil || !found {
+	if found, err := readProcessConnections(buf, namespaceProcs, w.udp); err != nil || !found {
 		return err
 	}
 
@@ -171,7 +201,7 @@ func (w pidWalker) walkNamespace(namespaceID uint32, buf *bytes.Buffer, sockets
 			fdBlockCount = 0
 			// read the connections again to
 			// avoid the race between between /net/tcp{,6} and /proc/PID/fd/*
-			if found, err := readProcessConnections(buf, namespaceProcs[i:]); err != nil || !found {
+			if found, err := readProcessConnections(buf, namespaceProcs[i:], w.udp); err != nil || !found {
 				return err
 			}
 		}
@@ -5,13 +5,20 @@ import (
 	"net"
 )
 
-// Used to check whether we are parsing a header line
-var slHeader = []byte("sl")
+var (
+	// Used to check whether we are parsing a header line
+	slHeader = []byte("sl")
+	// Only the header of /proc/net/udp{,6} has a drops column
+	udpHeaderColumn = []byte("drops")
+)
 
-// ProcNet is an iterator to parse /proc/net/tcp{,6} files.
+// ProcNet is an iterator to parse /proc/net/tcp{,6} and /proc/net/udp{,6}
+// files. The protocol of the lines is taken from the preceding header, so
+// the contents of several files can be parsed at once.
 type ProcNet struct {
 	b                       []byte
 	c                       Connection
+	udp                     bool
 	bytesLocal, bytesRemote [16]byte
 	seen                    map[uint64]struct{}
 }
@@ -40,16 +47,23 @@ again:
 
 	sl, b = nextField(b) // 'sl' column
 	if bytes.Equal(sl, slHeader) {
-		// Skip header
+		// Skip header, noting which protocol the following lines are for
+		line := b
+		if i := bytes.IndexByte(line, '\n'); i != -1 {
+			line = line[:i]
+		}
+		p.udp = bytes.Contains(line, udpHeaderColumn)
 		p.b = nextLine(b)
 		goto again
 	}
 	local, b = nextField(b)
 	remote, b = nextField(b)
 	state, b = nextField(b)
-	switch parseHex(state) {
-	// Only process established or half-closed connections
-	case tcpEstablished, tcpFinWait1, tcpFinWait2, tcpCloseWait:
+	switch st := parseHex(state); {
+	// Only process established or half-closed connections, and UDP
+	// sockets whether connected or not
+	case p.udp && (st == tcpEstablished || st == tcpClose):
+	case !p.udp && (st == tcpEstablished || st == tcpFinWait1 || st == tcpFinWait2 || st == tcpCloseWait):
 	default:
 		p.b = nextLine(b)
 		goto again
@@ -64,6 +78,10 @@ again:
 	p.c.LocalAddress, p.c.LocalPort = scanAddressNA(local, &p.bytesLocal)
 	p.c.RemoteAddress, p.c.RemotePort = scanAddressNA(remote, &p.bytesRemote)
 	p.c.Inode = parseDec(inode)
+	p.c.Transport = ""
+	if p.udp {
+		p.c.Transport = TransportUDP
+	}
 	p.b = nextLine(b)
 	if _, alreadySeen := p.seen[p.c.Inode]; alreadySeen {
 		goto again
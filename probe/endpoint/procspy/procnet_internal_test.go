@@ -160,3 +160,53 @@ func TestProcNetFiltersDuplicates(t *testing.T) {
 	}
 
 }
+
+func TestProcNetUDP(t *testing.T) {
+	// tcp lines followed by the contents of /proc/net/udp: a DNS server
+	// socket, and a connected socket
+	testString := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: A12CF62E:E4D7 57FC1EC0:01BB 01 00000000:00000000 02:000006FA 00000000  1000        0 639474 2 ffff88007e75a740 48 4 26 10 -1
+   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  1: 00000000:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 17432 2 ffff8800a6aaf040 0
+  2: 0100007F:D8A3 0100007F:1F90 01 00000000:00000000 00:00000000 00000000  1000        0 17440 2 ffff8800a6aaf740 0
+  3: 0100007F:D8A4 0100007F:1F90 0A 00000000:00000000 00:00000000 00000000  1000        0 17441 2 ffff8800a6aaf840 0
+`
+	p := NewProcNet([]byte(testString))
+	expected := []Connection{
+		{
+			LocalAddress:  net.IP([]byte{0x2e, 0xf6, 0x2c, 0xa1}),
+			LocalPort:     0xe4d7,
+			RemoteAddress: net.IP([]byte{0xc0, 0x1e, 0xfc, 0x57}),
+			RemotePort:    0x01bb,
+			Inode:         639474,
+		},
+		{
+			Transport:     TransportUDP,
+			LocalAddress:  net.IP([]byte{0, 0, 0, 0}),
+			LocalPort:     53,
+			RemoteAddress: net.IP([]byte{0, 0, 0, 0}),
+			RemotePort:    0,
+			Inode:         17432,
+		},
+		{
+			Transport:     TransportUDP,
+			LocalAddress:  net.IP([]byte{0x7f, 0, 0, 0x01}),
+			LocalPort:     0xd8a3,
+			RemoteAddress: net.IP([]byte{0x7f, 0, 0, 0x01}),
+			RemotePort:    8080,
+			Inode:         17440,
+		},
+	}
+	for _, want := range expected {
+		have := p.Next()
+		if have == nil {
+			t.Fatalf("Expected\n%+v\n", want)
+		}
+		if !reflect.DeepEqual(*have, want) {
+			t.Errorf("Got\n%+v\nExpected\n%+v\n", *have, want)
+		}
+	}
+	if got := p.Next(); got != nil {
+		t.Errorf("p.Next() wasn't empty")
+	}
+}
@@ -34,12 +34,12 @@ type backgroundReader struct {
 
 // starts a rate-limited background goroutine to read the expensive files from
 // proc.
-func newBackgroundReader(walker process.Walker) reader {
+func newBackgroundReader(walker process.Walker, udp bool) reader {
 	br := &backgroundReader{
 		stopc:         make(chan struct{}),
 		latestSockets: map[uint64]*Proc{},
 	}
-	go br.loop(walker)
+	go br.loop(walker, udp)
 	return br
 }
 
@@ -61,7 +61,7 @@ func (br *backgroundReader) getWalkedProcPid(buf *bytes.Buffer) (map[uint64]*Pro
 	return br.latestSockets, err
 }
 
-func (br *backgroundReader) loop(walker process.Walker) {
+func (br *backgroundReader) loop(walker process.Walker, udp bool) {
 	var (
 		begin           time.Time                      // when we started the last performWalk
 		tickc           = time.After(time.Millisecond) // fire immediately
@@ -69,7 +69,7 @@ func (br *backgroundReader) loop(walker process.Walker) {
 		rateLimitPeriod = initialRateLimitPeriod
 		restInterval    time.Duration
 		ticker          = time.NewTicker(rateLimitPeriod)
-		pWalker         = newPidWalker(walker, ticker.C, fdBlockSize)
+		pWalker         = newPidWalker(walker, ticker.C, fdBlockSize, udp)
 	)
 
 	for {
@@ -113,7 +113,7 @@ type foregroundReader struct {
 }
 
 // reads synchronously files from /proc
-func newForegroundReader(walker process.Walker) reader {
+func newForegroundReader(walker process.Walker, udp bool) reader {
 	fr := &foregroundReader{
 		stopc:         make(chan struct{}),
 		latestSockets: map[uint64]*Proc{},
@@ -121,7 +121,7 @@ func newForegroundReader(walker process.Walker) reader {
 	var (
 		walkc   = make(chan walkResult)
 		ticker  = time.NewTicker(time.Millisecond) // fire every millisecond
-		pWalker = newPidWalker(walker, ticker.C, fdBlockSize)
+		pWalker = newPidWalker(walker, ticker.C, fdBlockSize, udp)
 	)
 
 	go performWalk(pWalker, walkc)
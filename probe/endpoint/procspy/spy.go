@@ -1,5 +1,5 @@
-// Package procspy lists TCP connections, and optionally tries to find the
-// owning processes. Works on Linux (via /proc) and Darwin (via `lsof -i` and
+// Package procspy lists TCP connections, and optionally UDP sockets, and
+// tries to find the owning processes. Works on Linux (via /proc) and Darwin (via `lsof -i` and
 // `netstat`). You'll need root to use Processes().
 package procspy
 
@@ -13,9 +13,16 @@ const (
 	tcpFinWait1    = 4
 	tcpFinWait2    = 5
 	tcpCloseWait   = 8
+	tcpClose       = 7 // state of unconnected UDP sockets
+
+	// TransportUDP is the Transport of UDP sockets. The Transport of TCP
+	// connections read from /proc is left empty.
+	TransportUDP = "udp"
 )
 
-// Connection is a (TCP) connection. The Proc struct might not be filled in.
+// Connection is a (TCP) connection, or a UDP socket. UDP sockets which are
+// not connected have an unspecified remote address and a zero remote port.
+// The Proc struct might not be filled in.
 type Connection struct {
 	Transport     string
 	LocalAddress  net.IP
@@ -13,13 +13,14 @@ const (
 	lsofBinary    = "lsof"
 )
 
-// NewConnectionScanner creates a new Darwin ConnectionScanner
-func NewConnectionScanner(_ process.Walker, processes bool) ConnectionScanner {
+// NewConnectionScanner creates a new Darwin ConnectionScanner. Only TCP
+// connections are supported.
+func NewConnectionScanner(_ process.Walker, processes, _ bool) ConnectionScanner {
 	return &darwinScanner{processes}
 }
 
 // NewSyncConnectionScanner creates a new synchronous Darwin ConnectionScanner
-func NewSyncConnectionScanner(_ process.Walker, processes bool) ConnectionScanner {
+func NewSyncConnectionScanner(_ process.Walker, processes, _ bool) ConnectionScanner {
 	return &darwinScanner{processes}
 }
 
@@ -37,26 +37,28 @@ func (c *pnConnIter) Next() *Connection {
 	return n
 }
 
-// NewConnectionScanner creates a new Linux ConnectionScanner
-func NewConnectionScanner(walker process.Walker, processes bool) ConnectionScanner {
-	scanner := &linuxScanner{}
+// NewConnectionScanner creates a new Linux ConnectionScanner. If udp is
+// true, UDP sockets are returned too.
+func NewConnectionScanner(walker process.Walker, processes, udp bool) ConnectionScanner {
+	scanner := &linuxScanner{udp: udp}
 	if processes {
-		scanner.r = newBackgroundReader(walker)
+		scanner.r = newBackgroundReader(walker, udp)
 	}
 	return scanner
 }
 
 // NewSyncConnectionScanner creates a new synchronous Linux ConnectionScanner
-func NewSyncConnectionScanner(walker process.Walker, processes bool) ConnectionScanner {
-	scanner := &linuxScanner{}
+func NewSyncConnectionScanner(walker process.Walker, processes, udp bool) ConnectionScanner {
+	scanner := &linuxScanner{udp: udp}
 	if processes {
-		scanner.r = newForegroundReader(walker)
+		scanner.r = newForegroundReader(walker, udp)
 	}
 	return scanner
 }
 
 type linuxScanner struct {
-	r reader
+	r   reader
+	udp bool
 }
 
 func (s *linuxScanner) Connections() (ConnIter, error) {
@@ -77,6 +79,12 @@ func (s *linuxScanner) Connections() (ConnIter, error) {
 		if ipv6IsSupported {
 			readFile(procRoot+"/net/tcp6", buf)
 		}
+		if s.udp {
+			readFile(procRoot+"/net/udp", buf)
+			if ipv6IsSupported {
+				readFile(procRoot+"/net/udp6", buf)
+			}
+		}
 	}
 
 	return &pnConnIter{
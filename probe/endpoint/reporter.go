@@ -11,6 +11,31 @@ const (
 	ReverseDNSNames = report.ReverseDNSNames
 	SnoopedDNSNames = report.SnoopedDNSNames
 	CopyOf          = report.CopyOf
+	Protocol        = report.Protocol
+)
+
+// Values of the Protocol key. Endpoints without it are TCP endpoints.
+const (
+	TCP = "tcp"
+	UDP = "udp"
+)
+
+// Node metrics keys. These are the byte and packet counters of the
+// connections made from an endpoint, over both directions, as reported
+// by conntrack accounting, also when connections are tracked with eBPF.
+// Accounting is off by default in most kernels; without
+// net.netfilter.nf_conntrack_acct=1 endpoints have no metrics.
+const (
+	Bytes   = report.EndpointBytes
+	Packets = report.EndpointPackets
+)
+
+// Values of the host ConnectionCounters key, which tells users where the
+// throughput of connections comes from, or why there is none.
+const (
+	CountersConntrack    = "conntrack accounting"
+	CountersNoConntrack  = "unavailable: conntrack is disabled"
+	CountersNoAccounting = "unavailable: enable conntrack accounting (sysctl net.netfilter.nf_conntrack_acct=1)"
 )
 
 // ReporterConfig are the config options for the endpoint reporter.
@@ -21,6 +46,7 @@ type ReporterConfig struct {
 	UseConntrack bool
 	WalkProc     bool
 	UseEbpfConn  bool
+	TrackUDP     bool
 	ProcRoot     string
 	BufferSize   int
 	ProcessCache *process.CachingWalker
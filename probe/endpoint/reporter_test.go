@@ -2,6 +2,7 @@ package endpoint_test
 
 import (
 	"net"
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -126,3 +127,58 @@ func TestSpyWithProcesses(t *testing.T) {
 		}
 	}
 }
+
+func TestSpyUDP(t *testing.T) {
+	const (
+		nodeID   = "nikon"
+		nodeName = "fishermans-friend"
+	)
+
+	scanner := procspy.FixedScanner([]procspy.Connection{
+		{
+			// unconnected socket; doesn't make an edge
+			Transport:     procspy.TransportUDP,
+			LocalAddress:  net.ParseIP("0.0.0.0"),
+			LocalPort:     53,
+			RemoteAddress: net.ParseIP("0.0.0.0"),
+			Proc:          procspy.Proc{PID: fixProcessPID, Name: fixProcessName},
+		},
+		{
+			Transport:     procspy.TransportUDP,
+			LocalAddress:  fixLocalAddress,
+			LocalPort:     fixRemotePort,
+			RemoteAddress: fixRemoteAddress,
+			RemotePort:    8125,
+			Proc:          procspy.Proc{PID: fixProcessPID, Name: fixProcessName},
+		},
+	})
+	reporter := endpoint.NewReporter(endpoint.ReporterConfig{
+		HostID:     nodeID,
+		HostName:   nodeName,
+		SpyProcs:   true,
+		WalkProc:   true,
+		TrackUDP:   true,
+		BufferSize: bufferSize,
+		Scanner:    scanner,
+	})
+	r, _ := reporter.Report()
+
+	var (
+		scopedLocal  = report.MakeProtocolEndpointNodeID(report.MakeEndpointNodeID(nodeID, "", fixLocalAddress.String(), strconv.Itoa(int(fixRemotePort))), endpoint.UDP)
+		scopedRemote = report.MakeProtocolEndpointNodeID(report.MakeEndpointNodeID(nodeID, "", fixRemoteAddress.String(), "8125"), endpoint.UDP)
+	)
+	if want, have := 2, len(r.Endpoint.Nodes); want != have {
+		t.Fatalf("want %d nodes, have %d", want, have)
+	}
+	if want, have := report.MakeIDList(scopedRemote), r.Endpoint.Nodes[scopedLocal].Adjacency; !reflect.DeepEqual(want, have) {
+		t.Fatalf("want %v, have %v", want, have)
+	}
+	for _, id := range []string{scopedLocal, scopedRemote} {
+		if have, _ := r.Endpoint.Nodes[id].Latest.Lookup(endpoint.Protocol); have != endpoint.UDP {
+			t.Errorf("%s: want protocol %q, have %q", id, endpoint.UDP, have)
+		}
+	}
+	if have, _ := r.Endpoint.Nodes[scopedLocal].Latest.Lookup("pid"); have != strconv.FormatUint(uint64(fixProcessPID), 10) {
+		t.Errorf("want pid %d, have %q", fixProcessPID, have)
+	}
+}
@@ -26,6 +26,8 @@ const (
 	MemoryUsage   = report.HostMemoryUsage
 	ScopeVersion  = report.ScopeVersion
 	NodeName      = report.KubernetesNodeName
+	// ConnectionCounters is set by the endpoint reporter
+	ConnectionCounters = report.HostConnectionCounters
 )
 
 // Exposed for testing.
@@ -46,6 +48,8 @@ var (
 		LocalNetworks: {ID: LocalNetworks, Label: "Local networks", From: report.FromSets, Priority: 13},
 		ScopeVersion:  {ID: ScopeVersion, Label: "Scope version", From: report.FromLatest, Priority: 14},
 		NodeName:      {ID: NodeName, Label: "Nodename", From: report.FromLatest, Priority: 15},
+
+		ConnectionCounters: {ID: ConnectionCounters, Label: "Connection throughput", From: report.FromLatest, Priority: 16},
 	}
 
 	MetricTemplates = report.MetricTemplates{
@@ -4,9 +4,13 @@ import (
 	"strconv"
 
 	mayav1alpha1 "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	"github.com/weaveworks/common/mtime"
 	"github.com/weaveworks/scope/report"
 )
 
+// DeviceCapacityBytes is the key of the block device capacity metric
+const DeviceCapacityBytes = report.KubernetesDeviceCapacityBytes
+
 // BlockDevice represent NDM BlockDevice interface
 type BlockDevice interface {
 	Meta
@@ -37,5 +41,7 @@ func (b *blockDevice) GetNode(probeID string) report.Node {
 		HostName:              b.GetLabels()["kubernetes.io/hostname"],
 		Path:                  b.Spec.Path,
 		report.ControlProbeID: probeID,
+	}).WithMetrics(report.Metrics{
+		DeviceCapacityBytes: report.MakeSingletonMetric(mtime.Now(), float64(b.Spec.Capacity.Storage)),
 	}).WithLatestActiveControls(Describe)
 }
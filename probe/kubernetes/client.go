@@ -3,10 +3,12 @@ package kubernetes
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -27,17 +29,25 @@ import (
 	log "github.com/sirupsen/logrus"
 	apiappsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	apibatchv1 "k8s.io/api/batch/v1"
 	apibatchv1beta1 "k8s.io/api/batch/v1beta1"
 	apiv1 "k8s.io/api/core/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
@@ -74,6 +84,19 @@ type Client interface {
 	WalkCsiVolumeSnapshots(f func(CsiVolumeSnapshot) error) error
 	WalkVolumeSnapshotClasses(f func(VolumeSnapshotClass) error) error
 	WalkVolumeSnapshotContents(f func(VolumeSnapshotContent) error) error
+	WalkNetworkPolicies(f func(NetworkPolicy) error) error
+	WalkIngresses(f func(Ingress) error) error
+	WalkEndpoints(f func(ServiceEndpoints) error) error
+	WalkNodes(f func(Node) error) error
+	WalkEvents(f func(ResourceEvent) error) error
+	WalkCSIDrivers(f func(CSIDriver) error) error
+	WalkCSINodes(f func(CSINode) error) error
+	WalkCStorVolumeConfigs(f func(CStorVolumeConfig) error) error
+	WalkCStorVolumeAttachments(f func(CStorVolumeAttachment) error) error
+	WalkCustomResources(gvr schema.GroupVersionResource, f func(CustomResource) error) error
+	WalkHorizontalPodAutoscalers(f func(HorizontalPodAutoscaler) error) error
+	WalkReplicaSets(f func(ReplicaSet) error) error
+	GetOwner(namespace string, ref metav1.OwnerReference) (CustomResource, error)
 	WatchPods(f func(Event, Pod))
 
 	CloneVolumeSnapshot(ctx context.Context, namespaceID, volumeSnapshotID, persistentVolumeClaimID, capacity string) error
@@ -86,6 +109,14 @@ type Client interface {
 	DeleteCsiVolumeSnapshot(ctx context.Context, namespaceID, volumeSnapshotID string) error
 	ScaleUp(ctx context.Context, namespaceID, id string) error
 	ScaleDown(ctx context.Context, namespaceID, id string) error
+	SetReplicas(ctx context.Context, kind, namespaceID, id string, replicas int32) error
+	RestartRollout(ctx context.Context, kind, namespaceID, id string) error
+	RollbackDeployment(ctx context.Context, namespaceID, id, replicaSetID string) error
+	CordonNode(ctx context.Context, name string, unschedulable bool) error
+	DrainNode(ctx context.Context, name string) error
+	ExpandPersistentVolumeClaim(ctx context.Context, namespaceID, persistentVolumeClaimID, capacity string) error
+	DeletePersistentVolumeClaim(ctx context.Context, namespaceID, persistentVolumeClaimID string) error
+	GetJivaReplicas(ctx context.Context, controllerIP string) ([]JivaReplica, error)
 }
 
 // ResourceMap is the mapping of resource and their GroupKind
@@ -101,102 +132,9 @@ var ResourceMap = map[string]schema.GroupKind{
 	"PersistentVolume":      {Group: apiv1.GroupName, Kind: "PersistentVolume"},
 	"PersistentVolumeClaim": {Group: apiv1.GroupName, Kind: "PersistentVolumeClaim"},
 	"StorageClass":          {Group: storagev1.GroupName, Kind: "StorageClass"},
-}
-
-var csiDriverMap = map[string]bool{
-	"diskplugin.csi.alibabacloud.com":          true,
-	"nasplugin.csi.alibabacloud.com":           true,
-	"ossplugin.csi.alibabacloud.com":           true,
-	"arstor.csi.huayun.io":                     true,
-	"ebs.csi.aws.com":                          true,
-	"efs.csi.aws.com":                          true,
-	"fsx.csi.aws.com":                          true,
-	"disk.csi.azure.com":                       true,
-	"file.csi.azure.com":                       true,
-	"csi.block.bigtera.com":                    true,
-	"csi.fs.bigtera.com":                       true,
-	"cephfs.csi.ceph.com":                      true,
-	"rbd.csi.ceph.com":                         true,
-	"csi.chubaofs.com":                         true,
-	"cinder.csi.openstack.org":                 true,
-	"csi.cloudscale.ch":                        true,
-	"csi-infiblock-plugin":                     true,
-	"csi-infifs-plugin":                        true,
-	"dsp.csi.daterainc.io":                     true,
-	"csi-isilon.dellemc.com":                   true,
-	"csi-powermax.dellemc.com":                 true,
-	"csi-powerstore.dellemc.com":               true,
-	"csi-unity.dellemc.com":                    true,
-	"csi-vxflexos.dellemc.com":                 true,
-	"csi-xtremio.dellemc.com":                  true,
-	"org.democratic-csi.v1.0":                  true,
-	"org.democratic-csi.v1.1":                  true,
-	"org.democratic-csi.v1.2":                  true,
-	"dcx.csi.diamanti.com":                     true,
-	"dobs.csi.digitalocean.com":                true,
-	"csi.drivescale.com":                       true,
-	"v0.2.ember-csi.io":                        true,
-	"v0.3.ember-csi.io":                        true,
-	"v1.0.ember-csi.io":                        true,
-	"pd.csi.storage.gke.io":                    true,
-	"com.google.csi.filestore":                 true,
-	"gcs.csi.ofek.dev":                         true,
-	"org.gluster.glusterfs":                    true,
-	"org.gluster.glustervirtblock":             true,
-	"com.hammerspace.csi":                      true,
-	"io.hedvig.csi":                            true,
-	"csi.hetzner.cloud":                        true,
-	"com.hitachi.hspc.csi":                     true,
-	"csi.hpe.com":                              true,
-	"csi.huawei.com":                           true,
-	"eu.zetanova.csi.hyperv":                   true,
-	"block.csi.ibm.com":                        true,
-	"spectrumscale.csi.ibm.com":                true,
-	"vpc.block.csi.ibm.io":                     true,
-	"infinibox-csi-driver":                     true,
-	"csi-instorage":                            true,
-	"pmem-csi.intel.com":                       true,
-	"csi.juicefs.com":                          true,
-	"org.kadalu.gluster":                       true,
-	"linodebs.csi.linode.com":                  true,
-	"io.drbd.linstor-csi":                      true,
-	"driver.longhorn.io":                       true,
-	"csi-macrosan":                             true,
-	"manila.csi.openstack.org":                 true,
-	"com.mapr.csi-kdf":                         true,
-	"com.tuxera.csi.moosefs":                   true,
-	"csi.trident.netapp.io":                    true,
-	"nexentastor-csi-driver.nexenta.com":       true,
-	"nexentastor-block-csi-driver.nexenta.com": true,
-	"com.nutanix.csi":                          true,
-	"cstor.csi.openebs.io":                     true,
-	"csi-opensdsplugin":                        true,
-	"com.open-e.joviandss.csi":                 true,
-	"pxd.openstorage.org":                      true,
-	"pure-csi":                                 true,
-	"disk.csi.qingcloud.com":                   true,
-	"csi-neonsan":                              true,
-	"quobyte-csi":                              true,
-	"robin":                                    true,
-	"csi-sandstone-plugin":                     true,
-	"eds.csi.sangfor.com":                      true,
-	"seaweedfs-csi-driver":                     true,
-	"secrets-store.csi.k8s.io":                 true,
-	"csi-smtx-plugin":                          true,
-	"csi.spdk.io":                              true,
-	"storageos":                                true,
-	"com.tencent.cloud.csi.cbs":                true,
-	"com.tencent.cloud.csi.cfs":                true,
-	"com.tencent.cloud.csi.cosfs":              true,
-	"topolvm.cybozu.com":                       true,
-	"csi.vastdata.com":                         true,
-	"csi.block.xsky.com":                       true,
-	"csi.fs.xsky.com":                          true,
-	"secrets.csi.kubevault.com":                true,
-	"csi.vsphere.vmware.com":                   true,
-	"csi.weka.io":                              true,
-	"yandex.csi.flant.com":                     true,
-	"csi.zadara.com":                           true,
+	"NetworkPolicy":         {Group: networkingv1.GroupName, Kind: "NetworkPolicy"},
+	"Ingress":               {Group: networkingv1.GroupName, Kind: "Ingress"},
+	"CSIDriver":             {Group: storagev1.GroupName, Kind: "CSIDriver"},
 }
 
 type client struct {
@@ -206,6 +144,7 @@ type client struct {
 	mayaClient                 *mayaclient.Clientset
 	csiSnapshotClient          *csisnapshot.Clientset
 	cstorClient                *cstorclient.Clientset
+	dynamicClient              dynamic.Interface
 	podStore                   cache.Store
 	serviceStore               cache.Store
 	deploymentStore            cache.Store
@@ -232,6 +171,30 @@ type client struct {
 	csiVolumeSnapshotStore     cache.Store
 	volumeSnapshotClassStore   cache.Store
 	volumeSnapshotContentStore cache.Store
+	networkPolicyStore         cache.Store
+	ingressStore               cache.Store
+	endpointsStore             cache.Store
+	endpointSliceStore         cache.Store
+	eventStore                 cache.Store
+	csiDriverStore             cache.Store
+	csiNodeStore               cache.Store
+	cStorVolumeConfigStore     cache.Store
+	cStorVolumeAttachmentStore cache.Store
+	autoscalerStore            cache.Store
+	replicaSetStore            cache.Store
+
+	// Stores of the configured custom resources are set up the first
+	// time they are walked.
+	customResourceStoresMutex sync.Mutex
+	customResourceStores      map[schema.GroupVersionResource]cache.Store
+
+	// Owners of pods are looked up through the dynamic client, as they
+	// can be of any kind, and kept for a while as many pods share them.
+	ownersMutex    sync.Mutex
+	owners         map[types.UID]cachedOwner
+	ownersPending  map[types.UID]struct{}
+	ownerLookups   chan ownerLookup
+	ownerResources map[string]metav1.APIResource
 
 	podWatchesMutex sync.Mutex
 	podWatches      []func(Event, Pod)
@@ -320,6 +283,11 @@ func NewClient(config ClientConfig) (Client, error) {
 		return nil, err
 	}
 
+	dc, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	result := &client{
 		quit:              make(chan struct{}),
 		client:            c,
@@ -327,7 +295,15 @@ func NewClient(config ClientConfig) (Client, error) {
 		mayaClient:        mc,
 		csiSnapshotClient: csc,
 		cstorClient:  cc,
+		dynamicClient:     dc,
+
+		customResourceStores: map[schema.GroupVersionResource]cache.Store{},
+		owners:               map[types.UID]cachedOwner{},
+		ownersPending:        map[types.UID]struct{}{},
+		ownerLookups:         make(chan ownerLookup, ownerLookupQueue),
+		ownerResources:       map[string]metav1.APIResource{},
 	}
+	go result.lookupOwners()
 
 	result.podStore = NewEventStore(result.triggerPodWatches, cache.MetaNamespaceKeyFunc)
 	result.runReflectorUntil("pods", result.podStore)
@@ -357,6 +333,17 @@ func NewClient(config ClientConfig) (Client, error) {
 	result.csiVolumeSnapshotStore = result.setupStore("csivolumesnapshots")
 	result.volumeSnapshotClassStore = result.setupStore("volumesnapshotclasses")
 	result.volumeSnapshotContentStore = result.setupStore("volumesnapshotcontents")
+	result.networkPolicyStore = result.setupStore("networkpolicies")
+	result.ingressStore = result.setupStore("ingresses")
+	result.endpointsStore = result.setupStore("endpoints")
+	result.endpointSliceStore = result.setupStore("endpointslices")
+	result.eventStore = result.setupStore("events")
+	result.csiDriverStore = result.setupStore("csidrivers")
+	result.csiNodeStore = result.setupStore("csinodes")
+	result.cStorVolumeConfigStore = result.setupStore("cstorvolumeconfigs")
+	result.cStorVolumeAttachmentStore = result.setupDynamicStore(CStorVolumeAttachmentResource)
+	result.autoscalerStore = result.setupAutoscalerStore()
+	result.replicaSetStore = result.setupStore("replicasets")
 
 	return result, nil
 }
@@ -385,6 +372,58 @@ func (c *client) setupStore(resource string) cache.Store {
 	return store
 }
 
+// setupAutoscalerStore reflects autoscaling/v2 HorizontalPodAutoscalers,
+// or v2beta2 ones on clusters which don't serve v2 yet.
+func (c *client) setupAutoscalerStore() cache.Store {
+	gvr := HorizontalPodAutoscalerResource
+	if ok, err := c.isResourceSupported(gvr.GroupVersion(), gvr.Resource); err == nil && !ok {
+		return c.setupStore("horizontalpodautoscalers")
+	}
+	return c.setupDynamicStore(gvr)
+}
+
+// setupDynamicStore reflects a resource through the dynamic client, for
+// resources whose types aren't vendored. The store holds
+// *unstructured.Unstructured objects.
+func (c *client) setupDynamicStore(gvr schema.GroupVersionResource) cache.Store {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	var r *cache.Reflector
+	listAndWatch := func() (bool, error) {
+		if r == nil {
+			ok, err := c.isResourceSupported(gvr.GroupVersion(), gvr.Resource)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				log.Infof("%v are not supported by this Kubernetes cluster", gvr.Resource)
+				return true, nil
+			}
+			resource := c.dynamicClient.Resource(gvr).Namespace(metav1.NamespaceAll)
+			lw := &cache.ListWatch{
+				ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+					return resource.List(context.TODO(), options)
+				},
+				WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+					return resource.Watch(context.TODO(), options)
+				},
+			}
+			r = cache.NewReflector(lw, &unstructured.Unstructured{}, store, 0)
+		}
+
+		select {
+		case <-c.quit:
+			return true, nil
+		default:
+			err := r.ListAndWatch(c.quit)
+			return false, err
+		}
+	}
+	bo := backoff.New(listAndWatch, fmt.Sprintf("Kubernetes reflector (%s)", gvr.Resource))
+	bo.SetMaxBackoff(5 * time.Minute)
+	go bo.Start()
+	return store
+}
+
 func (c *client) clientAndType(resource string) (rest.Interface, interface{}, error) {
 	switch resource {
 	case "pods":
@@ -431,6 +470,8 @@ func (c *client) clientAndType(resource string) (rest.Interface, interface{}, er
 		return c.cstorClient.CstorV1().RESTClient(), &cstorv1.CStorPoolCluster{}, nil
 	case "cstorpoolinstances":
 		return c.cstorClient.CstorV1().RESTClient(), &cstorv1.CStorPoolInstance{}, nil
+	case "cstorvolumeconfigs":
+		return c.cstorClient.CstorV1().RESTClient(), &cstorv1.CStorVolumeConfig{}, nil
 	case "cronjobs":
 		return c.client.BatchV1beta1().RESTClient(), &apibatchv1beta1.CronJob{}, nil
 	case "csivolumesnapshots":
@@ -439,6 +480,24 @@ func (c *client) clientAndType(resource string) (rest.Interface, interface{}, er
 		return c.csiSnapshotClient.SnapshotV1beta1().RESTClient(), &csisnapshotv1beta1.VolumeSnapshotClass{}, nil
 	case "volumesnapshotcontents":
 		return c.csiSnapshotClient.SnapshotV1beta1().RESTClient(), &csisnapshotv1beta1.VolumeSnapshotContent{}, nil
+	case "networkpolicies":
+		return c.client.NetworkingV1().RESTClient(), &networkingv1.NetworkPolicy{}, nil
+	case "ingresses":
+		return c.client.NetworkingV1().RESTClient(), &networkingv1.Ingress{}, nil
+	case "csidrivers":
+		return c.client.StorageV1().RESTClient(), &storagev1.CSIDriver{}, nil
+	case "csinodes":
+		return c.client.StorageV1().RESTClient(), &storagev1.CSINode{}, nil
+	case "events":
+		return c.client.CoreV1().RESTClient(), &apiv1.Event{}, nil
+	case "endpoints":
+		return c.client.CoreV1().RESTClient(), &apiv1.Endpoints{}, nil
+	case "endpointslices":
+		return c.client.DiscoveryV1beta1().RESTClient(), &discoveryv1beta1.EndpointSlice{}, nil
+	case "horizontalpodautoscalers":
+		return c.client.AutoscalingV2beta2().RESTClient(), &autoscalingv2beta2.HorizontalPodAutoscaler{}, nil
+	case "replicasets":
+		return c.client.AppsV1().RESTClient(), &apiappsv1.ReplicaSet{}, nil
 	}
 	return nil, nil, fmt.Errorf("Invalid resource: %v", resource)
 }
@@ -668,6 +727,154 @@ func (c *client) WalkVolumeSnapshotContents(f func(VolumeSnapshotContent) error)
 	return nil
 }
 
+// WalkNetworkPolicies calls f for each network policy
+func (c *client) WalkNetworkPolicies(f func(NetworkPolicy) error) error {
+	for _, m := range c.networkPolicyStore.List() {
+		np := m.(*networkingv1.NetworkPolicy)
+		if err := f(NewNetworkPolicy(np)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkNodes calls f for each node
+func (c *client) WalkNodes(f func(Node) error) error {
+	for _, m := range c.nodeStore.List() {
+		n := m.(*apiv1.Node)
+		if err := f(NewNode(n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkCSIDrivers calls f for each CSIDriver object
+func (c *client) WalkCSIDrivers(f func(CSIDriver) error) error {
+	for _, m := range c.csiDriverStore.List() {
+		d := m.(*storagev1.CSIDriver)
+		if err := f(NewCSIDriver(d)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkCSINodes calls f for each CSINode object
+func (c *client) WalkCSINodes(f func(CSINode) error) error {
+	for _, m := range c.csiNodeStore.List() {
+		n := m.(*storagev1.CSINode)
+		if err := f(NewCSINode(n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkHorizontalPodAutoscalers calls f for each HorizontalPodAutoscaler
+func (c *client) WalkHorizontalPodAutoscalers(f func(HorizontalPodAutoscaler) error) error {
+	for _, m := range c.autoscalerStore.List() {
+		h, ok := m.(*autoscalingv2beta2.HorizontalPodAutoscaler)
+		if !ok {
+			var err error
+			if h, err = autoscalerFromUnstructured(m.(*unstructured.Unstructured)); err != nil {
+				log.Warnf("Cannot read HorizontalPodAutoscaler: %v", err)
+				continue
+			}
+		}
+		if err := f(NewHorizontalPodAutoscaler(h)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkReplicaSets calls f for each replica set
+func (c *client) WalkReplicaSets(f func(ReplicaSet) error) error {
+	for _, m := range c.replicaSetStore.List() {
+		r := m.(*apiappsv1.ReplicaSet)
+		if err := f(NewReplicaSet(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isCSIDriver tells whether a provisioner is a CSI driver, that is
+// whether it has a CSIDriver object or is registered on any node.
+func (c *client) isCSIDriver(driver string) bool {
+	if _, ok, _ := c.csiDriverStore.GetByKey(driver); ok {
+		return true
+	}
+	for _, m := range c.csiNodeStore.List() {
+		for _, d := range m.(*storagev1.CSINode).Spec.Drivers {
+			if d.Name == driver {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WalkEvents calls f for each event
+func (c *client) WalkEvents(f func(ResourceEvent) error) error {
+	for _, m := range c.eventStore.List() {
+		e := m.(*apiv1.Event)
+		if err := f(NewResourceEvent(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkIngresses calls f for each ingress
+func (c *client) WalkIngresses(f func(Ingress) error) error {
+	for _, m := range c.ingressStore.List() {
+		i := m.(*networkingv1.Ingress)
+		if err := f(NewIngress(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkEndpoints calls f for the endpoints of each service. EndpointSlices
+// are used where the cluster has them, merged per service; otherwise the
+// Endpoints objects are.
+func (c *client) WalkEndpoints(f func(ServiceEndpoints) error) error {
+	if slices := c.endpointSliceStore.List(); len(slices) > 0 {
+		var (
+			keys      []string
+			endpoints = map[string]ServiceEndpoints{}
+		)
+		for _, m := range slices {
+			e := NewServiceEndpointsFromSlice(m.(*discoveryv1beta1.EndpointSlice))
+			if e.Service == "" {
+				continue
+			}
+			key := e.Namespace + "/" + e.Service
+			if existing, ok := endpoints[key]; ok {
+				endpoints[key] = existing.Add(e)
+			} else {
+				keys = append(keys, key)
+				endpoints[key] = e
+			}
+		}
+		for _, key := range keys {
+			if err := f(endpoints[key]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, m := range c.endpointsStore.List() {
+		if err := f(NewServiceEndpoints(m.(*apiv1.Endpoints))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *client) WalkJobs(f func(Job) error) error {
 	for _, m := range c.jobStore.List() {
 		job := m.(*apibatchv1.Job)
@@ -768,6 +975,172 @@ func (c *client) WalkCStorPoolInstances(f func(CStorPoolInstance) error) error {
 	return nil
 }
 
+func (c *client) WalkCStorVolumeConfigs(f func(CStorVolumeConfig) error) error {
+	for _, m := range c.cStorVolumeConfigStore.List() {
+		cvc := m.(*cstorv1.CStorVolumeConfig)
+		if err := f(NewCStorVolumeConfig(cvc)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *client) WalkCStorVolumeAttachments(f func(CStorVolumeAttachment) error) error {
+	for _, m := range c.cStorVolumeAttachmentStore.List() {
+		cva := m.(*unstructured.Unstructured)
+		if err := f(NewCStorVolumeAttachment(cva)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *client) WalkCustomResources(gvr schema.GroupVersionResource, f func(CustomResource) error) error {
+	c.customResourceStoresMutex.Lock()
+	store, ok := c.customResourceStores[gvr]
+	if !ok {
+		store = c.setupDynamicStore(gvr)
+		c.customResourceStores[gvr] = store
+	}
+	c.customResourceStoresMutex.Unlock()
+	for _, m := range store.List() {
+		u := m.(*unstructured.Unstructured)
+		if err := f(NewCustomResource(u)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const (
+	// ownerCacheTTL is how long owners are kept once looked up, or the
+	// failure to look them up
+	ownerCacheTTL = time.Minute
+	// ownerLookupTimeout bounds each lookup of an owner
+	ownerLookupTimeout = 10 * time.Second
+	// ownerLookupQueue is how many lookups of owners can be waiting
+	ownerLookupQueue = 100
+)
+
+// errOwnerPending is returned for owners which haven't been looked up yet
+var errOwnerPending = errors.New("owner lookup pending")
+
+type cachedOwner struct {
+	owner   CustomResource
+	err     error
+	fetched time.Time
+}
+
+type ownerLookup struct {
+	namespace string
+	ref       metav1.OwnerReference
+}
+
+// GetOwner returns the object an owner reference refers to. Owners are
+// in the namespace of the objects they own, unless cluster-scoped. Owners
+// are looked up in the background, so that reports aren't held up by the
+// API server: until an owner has been looked up, errOwnerPending is
+// returned, and once the cached owner expires it is still returned while
+// it is looked up again.
+func (c *client) GetOwner(namespace string, ref metav1.OwnerReference) (CustomResource, error) {
+	c.ownersMutex.Lock()
+	defer c.ownersMutex.Unlock()
+	cached, ok := c.owners[ref.UID]
+	if ok && time.Since(cached.fetched) < ownerCacheTTL {
+		return cached.owner, cached.err
+	}
+	if _, pending := c.ownersPending[ref.UID]; !pending {
+		select {
+		case c.ownerLookups <- ownerLookup{namespace: namespace, ref: ref}:
+			c.ownersPending[ref.UID] = struct{}{}
+		default:
+			// The queue is full; the owner is looked up in a later report
+		}
+	}
+	if ok {
+		return cached.owner, cached.err
+	}
+	return nil, errOwnerPending
+}
+
+// lookupOwners looks up the owners queued by GetOwner, until the client
+// is stopped.
+func (c *client) lookupOwners() {
+	for {
+		select {
+		case <-c.quit:
+			return
+		case l := <-c.ownerLookups:
+			ctx, cancel := context.WithTimeout(context.Background(), ownerLookupTimeout)
+			owner, err := c.lookupOwner(ctx, l.namespace, l.ref)
+			cancel()
+
+			c.ownersMutex.Lock()
+			now := time.Now()
+			for uid, cached := range c.owners {
+				// Owners still in use are refreshed before this
+				if now.Sub(cached.fetched) >= 2*ownerCacheTTL {
+					delete(c.owners, uid)
+				}
+			}
+			c.owners[l.ref.UID] = cachedOwner{owner: owner, err: err, fetched: now}
+			delete(c.ownersPending, l.ref.UID)
+			c.ownersMutex.Unlock()
+		}
+	}
+}
+
+func (c *client) lookupOwner(ctx context.Context, namespace string, ref metav1.OwnerReference) (CustomResource, error) {
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	resource, err := c.resourceForKind(gv, ref.Kind)
+	if err != nil {
+		return nil, err
+	}
+	ri := c.dynamicClient.Resource(gv.WithResource(resource.Name))
+	var u *unstructured.Unstructured
+	if resource.Namespaced {
+		u, err = ri.Namespace(namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	} else {
+		u, err = ri.Get(ctx, ref.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if u.GetUID() != ref.UID {
+		return nil, fmt.Errorf("%s %s/%s not found", ref.Kind, namespace, ref.Name)
+	}
+	return NewCustomResource(u), nil
+}
+
+// resourceForKind finds the resource objects of a kind are served as.
+func (c *client) resourceForKind(gv schema.GroupVersion, kind string) (metav1.APIResource, error) {
+	key := gv.String() + "/" + kind
+	c.ownersMutex.Lock()
+	resource, ok := c.ownerResources[key]
+	c.ownersMutex.Unlock()
+	if ok {
+		return resource, nil
+	}
+	resourceList, err := c.client.Discovery().ServerResourcesForGroupVersion(gv.String())
+	if err != nil {
+		return resource, err
+	}
+	for _, r := range resourceList.APIResources {
+		// Subresources, like deployments/scale, have the kind they're served as
+		if r.Kind != kind || strings.Contains(r.Name, "/") {
+			continue
+		}
+		c.ownersMutex.Lock()
+		c.ownerResources[key] = r
+		c.ownersMutex.Unlock()
+		return r, nil
+	}
+	return resource, fmt.Errorf("no resource found for %s", key)
+}
+
 func (c *client) CloneVolumeSnapshot(ctx context.Context, namespaceID, volumeSnapshotID, persistentVolumeClaimID, capacity string) error {
 	var scName string
 	var claimSize string
@@ -834,7 +1207,7 @@ func (c *client) CreateVolumeSnapshot(ctx context.Context, namespaceID, persiste
 	UID := strings.Split(uuid.New(), "-")
 	snapshotName := "snapshot-" + time.Now().Format("20060102150405") + "-" + UID[1]
 	var err error
-	if _, ok := csiDriverMap[driver]; ok {
+	if c.isCSIDriver(driver) {
 		err = c.createCsiVolumeSnapshot(ctx, snapshotName, namespaceID, persistentVolumeClaimID, capacity, driver)
 	} else {
 		err = c.createVolumeSnapshot(ctx, snapshotName, namespaceID, persistentVolumeClaimID, capacity)
@@ -905,19 +1278,44 @@ func (c *client) DeleteCsiVolumeSnapshot(ctx context.Context, namespaceID, volum
 }
 
 func (c *client) ScaleUp(ctx context.Context, namespaceID, id string) error {
-	return c.modifyScale(ctx, namespaceID, id, func(scale *autoscalingv1.Scale) {
+	return c.modifyScale(ctx, c.client.AppsV1().Deployments(namespaceID), id, func(scale *autoscalingv1.Scale) {
 		scale.Spec.Replicas++
 	})
 }
 
 func (c *client) ScaleDown(ctx context.Context, namespaceID, id string) error {
-	return c.modifyScale(ctx, namespaceID, id, func(scale *autoscalingv1.Scale) {
+	return c.modifyScale(ctx, c.client.AppsV1().Deployments(namespaceID), id, func(scale *autoscalingv1.Scale) {
 		scale.Spec.Replicas--
 	})
 }
 
-func (c *client) modifyScale(ctx context.Context, namespaceID, id string, f func(*autoscalingv1.Scale)) error {
-	scaler := c.client.AppsV1().Deployments(namespaceID)
+// SetReplicas sets the number of replicas of a Deployment or StatefulSet
+func (c *client) SetReplicas(ctx context.Context, kind, namespaceID, id string, replicas int32) error {
+	if replicas < 0 {
+		return fmt.Errorf("invalid number of replicas: %d", replicas)
+	}
+	var s scaler
+	switch kind {
+	case "Deployment":
+		s = c.client.AppsV1().Deployments(namespaceID)
+	case "StatefulSet":
+		s = c.client.AppsV1().StatefulSets(namespaceID)
+	default:
+		return fmt.Errorf("cannot scale a %s", kind)
+	}
+	return c.modifyScale(ctx, s, id, func(scale *autoscalingv1.Scale) {
+		scale.Spec.Replicas = replicas
+	})
+}
+
+// scaler is implemented by the clients of resources with a scale
+// subresource.
+type scaler interface {
+	GetScale(ctx context.Context, name string, options metav1.GetOptions) (*autoscalingv1.Scale, error)
+	UpdateScale(ctx context.Context, name string, scale *autoscalingv1.Scale, opts metav1.UpdateOptions) (*autoscalingv1.Scale, error)
+}
+
+func (c *client) modifyScale(ctx context.Context, scaler scaler, id string, f func(*autoscalingv1.Scale)) error {
 	scale, err := scaler.GetScale(ctx, id, metav1.GetOptions{})
 	if err != nil {
 		return err
@@ -927,6 +1325,218 @@ func (c *client) modifyScale(ctx context.Context, namespaceID, id string, f func
 	return err
 }
 
+// RestartRollout restarts the pods of a Deployment, DaemonSet or
+// StatefulSet by annotating their template, as kubectl rollout restart
+// does. The controller then replaces the pods following its update
+// strategy.
+func (c *client) RestartRollout(ctx context.Context, kind, namespaceID, id string) error {
+	patch := restartedAtPatch(time.Now())
+	var err error
+	switch kind {
+	case "Deployment":
+		_, err = c.client.AppsV1().Deployments(namespaceID).Patch(ctx, id, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case "DaemonSet":
+		_, err = c.client.AppsV1().DaemonSets(namespaceID).Patch(ctx, id, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	case "StatefulSet":
+		_, err = c.client.AppsV1().StatefulSets(namespaceID).Patch(ctx, id, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	default:
+		err = fmt.Errorf("cannot restart a %s", kind)
+	}
+	return err
+}
+
+// RollbackDeployment rolls a Deployment back to the pod template of one
+// of its ReplicaSets, as kubectl rollout undo does.
+func (c *client) RollbackDeployment(ctx context.Context, namespaceID, id, replicaSetID string) error {
+	deployments := c.client.AppsV1().Deployments(namespaceID)
+	deployment, err := deployments.Get(ctx, id, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	rs, err := c.client.AppsV1().ReplicaSets(namespaceID).Get(ctx, replicaSetID, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(rs, deployment) {
+		return fmt.Errorf("replica set %s does not belong to deployment %s", replicaSetID, id)
+	}
+	template := rs.Spec.Template.DeepCopy()
+	delete(template.Labels, apiappsv1.DefaultDeploymentUniqueLabelKey)
+	deployment.Spec.Template = *template
+	_, err = deployments.Update(ctx, deployment, metav1.UpdateOptions{})
+	return err
+}
+
+// ExpandPersistentVolumeClaim requests more storage for the volume of a
+// claim, leaving it to its provisioner to expand the volume. Volumes can't
+// be shrunk.
+func (c *client) ExpandPersistentVolumeClaim(ctx context.Context, namespaceID, persistentVolumeClaimID, capacity string) error {
+	size, err := resource.ParseQuantity(capacity)
+	if err != nil {
+		return fmt.Errorf("invalid capacity %q: %v", capacity, err)
+	}
+	claims := c.client.CoreV1().PersistentVolumeClaims(namespaceID)
+	pvc, err := claims.Get(ctx, persistentVolumeClaimID, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if current := pvc.Spec.Resources.Requests[apiv1.ResourceStorage]; size.Cmp(current) <= 0 {
+		return fmt.Errorf("capacity %s must be larger than the current %s", size.String(), current.String())
+	}
+	patch := fmt.Sprintf(`{"spec":{"resources":{"requests":{"storage":%q}}}}`, size.String())
+	_, err = claims.Patch(ctx, persistentVolumeClaimID, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+func (c *client) DeletePersistentVolumeClaim(ctx context.Context, namespaceID, persistentVolumeClaimID string) error {
+	return c.client.CoreV1().PersistentVolumeClaims(namespaceID).Delete(ctx, persistentVolumeClaimID, metav1.DeleteOptions{})
+}
+
+// CordonNode marks a node as unschedulable, or as schedulable again
+func (c *client) CordonNode(ctx context.Context, name string, unschedulable bool) error {
+	nodes := c.client.CoreV1().Nodes()
+	node, err := nodes.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if node.Spec.Unschedulable == unschedulable {
+		return nil
+	}
+	node.Spec.Unschedulable = unschedulable
+	_, err = nodes.Update(ctx, node, metav1.UpdateOptions{})
+	return err
+}
+
+const (
+	// drainTimeout bounds how long draining a node waits for the
+	// disruption budgets of its pods to allow their eviction. It is well
+	// within the minute the app waits for the response to a control, so
+	// that the UI never reports a failure while pods are still evicted.
+	drainTimeout         = 45 * time.Second
+	maxEvictionRetryWait = 16 * time.Second
+)
+
+// DrainNode cordons a node and evicts its pods, as kubectl drain does.
+// Pods managed by a DaemonSet and mirror pods are left alone, since
+// evicting them is pointless, and so are pods keeping data on the node, in
+// emptyDir or hostPath volumes, and pods no controller manages, which
+// would not be recreated, not to lose them. Evictions refused by a
+// PodDisruptionBudget are retried until drainTimeout.
+func (c *client) DrainNode(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, drainTimeout)
+	defer cancel()
+	if err := c.CordonNode(ctx, name, true); err != nil {
+		return err
+	}
+	pods, err := c.client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", name).String(),
+	})
+	if err != nil {
+		return err
+	}
+	var withLocalData, unmanaged []string
+	for _, pod := range pods.Items {
+		switch {
+		case isMirrorPod(pod), isDaemonSetPod(pod):
+			continue
+		case pod.Status.Phase == apiv1.PodSucceeded || pod.Status.Phase == apiv1.PodFailed:
+			continue
+		case metav1.GetControllerOf(&pod) == nil:
+			unmanaged = append(unmanaged, pod.Namespace+"/"+pod.Name)
+			continue
+		case hasLocalData(pod):
+			withLocalData = append(withLocalData, pod.Namespace+"/"+pod.Name)
+			continue
+		}
+		if err := c.evictPod(ctx, pod); err != nil {
+			return fmt.Errorf("evicting pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+	var skipped []string
+	if len(unmanaged) > 0 {
+		skipped = append(skipped, "pods not managed by a controller: "+strings.Join(unmanaged, ", "))
+	}
+	if len(withLocalData) > 0 {
+		skipped = append(skipped, "pods with local data: "+strings.Join(withLocalData, ", "))
+	}
+	if len(skipped) > 0 {
+		return fmt.Errorf("node cordoned, but these were not evicted: %s", strings.Join(skipped, "; "))
+	}
+	return nil
+}
+
+// evictPod evicts a pod, retrying with backoff while a PodDisruptionBudget
+// doesn't allow it, until ctx is done.
+func (c *client) evictPod(ctx context.Context, pod apiv1.Pod) error {
+	eviction := &policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+	}
+	wait := time.Second
+	for {
+		err := c.client.CoreV1().Pods(pod.Namespace).Evict(ctx, eviction)
+		switch {
+		case err == nil || apierrors.IsNotFound(err):
+			return nil
+		case !apierrors.IsTooManyRequests(err):
+			return err
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return fmt.Errorf("still not allowed by its disruption budget: %v", err)
+		}
+		if wait *= 2; wait > maxEvictionRetryWait {
+			wait = maxEvictionRetryWait
+		}
+	}
+}
+
+func isMirrorPod(pod apiv1.Pod) bool {
+	_, ok := pod.Annotations[apiv1.MirrorPodAnnotationKey]
+	return ok
+}
+
+func isDaemonSetPod(pod apiv1.Pod) bool {
+	owner := metav1.GetControllerOf(&pod)
+	return owner != nil && owner.Kind == "DaemonSet"
+}
+
+// hasLocalData tells whether a pod keeps data on its node, which is lost
+// when it's evicted.
+func hasLocalData(pod apiv1.Pod) bool {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.EmptyDir != nil || volume.HostPath != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// GetJivaReplicas asks a Jiva controller for the replicas registered with
+// it, and their modes.
+func (c *client) GetJivaReplicas(ctx context.Context, controllerIP string) ([]JivaReplica, error) {
+	url := fmt.Sprintf("http://%s:%d/v1/replicas", controllerIP, jivaControllerPort)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jiva controller %s: %s", controllerIP, resp.Status)
+	}
+	var replicas struct {
+		Data []JivaReplica `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&replicas); err != nil {
+		return nil, err
+	}
+	return replicas.Data, nil
+}
+
 func (c *client) Stop() {
 	close(c.quit)
 }
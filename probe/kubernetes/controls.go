@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"strconv"
 
 	"github.com/weaveworks/scope/common/xfer"
 	"github.com/weaveworks/scope/probe/controls"
@@ -24,6 +25,14 @@ const (
 	DeleteCsiVolumeSnapshot = report.KubernetesDeleteCsiVolumeSnapshot
 	ScaleUp                 = report.KubernetesScaleUp
 	ScaleDown               = report.KubernetesScaleDown
+	CordonNode              = report.KubernetesCordonNode
+	UncordonNode            = report.KubernetesUncordonNode
+	DrainNode               = report.KubernetesDrainNode
+	ExpandVolumeClaim       = report.KubernetesExpandVolumeClaim
+	DeleteVolumeClaim       = report.KubernetesDeleteVolumeClaim
+	RestartRollout          = report.KubernetesRestartRollout
+	RollbackDeployment      = report.KubernetesRollbackDeployment
+	SetReplicas             = report.KubernetesSetReplicas
 )
 
 // GroupName and version used by CRDs
@@ -34,6 +43,8 @@ const (
 	CsiSnapshotVersion   = "v1beta1"
 	OpenEBSGroupName     = "openebs.io"
 	OpenEBSVersion       = "v1alpha1"
+	CStorGroupName       = "cstor.openebs.io"
+	CStorVersion         = "v1"
 )
 
 var ctx = context.TODO()
@@ -104,6 +115,22 @@ func (r *Reporter) describeJob(req xfer.Request, namespaceID, jobID string) xfer
 	return r.describe(req, namespaceID, jobID, ResourceMap["Job"], apimeta.RESTMapping{})
 }
 
+func (r *Reporter) describeNetworkPolicy(req xfer.Request, namespaceID, networkPolicyID string) xfer.Response {
+	return r.describe(req, namespaceID, networkPolicyID, ResourceMap["NetworkPolicy"], apimeta.RESTMapping{})
+}
+
+func (r *Reporter) describeNode(req xfer.Request, nodeID string) xfer.Response {
+	return r.describe(req, "", nodeID, ResourceMap["Node"], apimeta.RESTMapping{})
+}
+
+func (r *Reporter) describeCSIDriver(req xfer.Request, name string) xfer.Response {
+	return r.describe(req, "", name, ResourceMap["CSIDriver"], apimeta.RESTMapping{})
+}
+
+func (r *Reporter) describeIngress(req xfer.Request, namespaceID, ingressID string) xfer.Response {
+	return r.describe(req, namespaceID, ingressID, ResourceMap["Ingress"], apimeta.RESTMapping{})
+}
+
 func (r *Reporter) describeVolumeSnapshot(req xfer.Request, namespaceID, volumeSnapshotID, _, _ string) xfer.Response {
 	restMapping := apimeta.RESTMapping{
 		Resource: schema.GroupVersionResource{
@@ -257,6 +284,21 @@ func (r *Reporter) describeCStorPoolInstance(req xfer.Request, namespaceID, cSto
 	return r.describe(req, namespaceID, cStorPoolInstanceID, schema.GroupKind{}, restMapping)
 }
 
+func (r *Reporter) describeCStorVolumeConfig(req xfer.Request, namespaceID, cStorVolumeConfigID string) xfer.Response {
+	restMapping := apimeta.RESTMapping{
+		Resource: schema.GroupVersionResource{
+			Group:    CStorGroupName,
+			Version:  CStorVersion,
+			Resource: "cstorvolumeconfigs",
+		},
+	}
+	return r.describe(req, namespaceID, cStorVolumeConfigID, schema.GroupKind{}, restMapping)
+}
+
+func (r *Reporter) describeCStorVolumeAttachment(req xfer.Request, namespaceID, cStorVolumeAttachmentID string) xfer.Response {
+	return r.describe(req, namespaceID, cStorVolumeAttachmentID, schema.GroupKind{}, apimeta.RESTMapping{Resource: CStorVolumeAttachmentResource})
+}
+
 // GetLogs is the control to get the logs for a kubernetes pod
 func (r *Reporter) describe(req xfer.Request, namespaceID, resourceID string, groupKind schema.GroupKind, restMapping apimeta.RESTMapping) xfer.Response {
 	readCloser, err := r.client.Describe(namespaceID, resourceID, groupKind, restMapping)
@@ -283,6 +325,10 @@ func (r *Reporter) describe(req xfer.Request, namespaceID, resourceID string, gr
 	}
 }
 
+func (r *Reporter) describeCustomResource(req xfer.Request, c CustomResourceConfig, namespaceID, name string) xfer.Response {
+	return r.describe(req, namespaceID, name, schema.GroupKind{}, apimeta.RESTMapping{Resource: c.GroupVersionResource()})
+}
+
 func (r *Reporter) cloneVolumeSnapshot(req xfer.Request, namespaceID, volumeSnapshotID, persistentVolumeClaimID, capacity string) xfer.Response {
 	err := r.client.CloneVolumeSnapshot(ctx, namespaceID, volumeSnapshotID, persistentVolumeClaimID, capacity)
 	if err != nil {
@@ -307,6 +353,28 @@ func (r *Reporter) createVolumeSnapshot(req xfer.Request, namespaceID, persisten
 	return xfer.Response{}
 }
 
+// expandVolumeClaim expands the volume of a claim to the capacity given
+// in the request
+func (r *Reporter) expandVolumeClaim(req xfer.Request, namespaceID, persistentVolumeClaimID, _, _ string) xfer.Response {
+	capacity, ok := req.ControlArgs["capacity"]
+	if !ok || capacity == "" {
+		return xfer.ResponseErrorf("Cannot expand volume claim %s/%s: missing capacity", namespaceID, persistentVolumeClaimID)
+	}
+	if err := r.client.ExpandPersistentVolumeClaim(ctx, namespaceID, persistentVolumeClaimID, capacity); err != nil {
+		return xfer.ResponseError(err)
+	}
+	return xfer.Response{}
+}
+
+func (r *Reporter) deleteVolumeClaim(req xfer.Request, namespaceID, persistentVolumeClaimID, _, _ string) xfer.Response {
+	if err := r.client.DeletePersistentVolumeClaim(ctx, namespaceID, persistentVolumeClaimID); err != nil {
+		return xfer.ResponseError(err)
+	}
+	return xfer.Response{
+		RemovedNode: req.NodeID,
+	}
+}
+
 func (r *Reporter) deletePod(req xfer.Request, namespaceID, podID string, _ []string) xfer.Response {
 	if err := r.client.DeletePod(ctx, namespaceID, podID); err != nil {
 		return xfer.ResponseError(err)
@@ -391,7 +459,23 @@ func (r *Reporter) Describe() func(xfer.Request) xfer.Response {
 			f = r.CaptureVolumeSnapshotClass(r.describeVolumeSnapshotClass)
 		case "<volume_snapshot_content>":
 			f = r.CaptureVolumeSnapshotContent(r.describeVolumeSnapshotContent)
+		case "<network_policy>":
+			f = r.CaptureNetworkPolicy(r.describeNetworkPolicy)
+		case "<ingress>":
+			f = r.CaptureIngress(r.describeIngress)
+		case "<kubernetes_node>":
+			f = r.CaptureNode(r.describeNode)
+		case "<csi_driver>":
+			f = r.CaptureCSIDriver(r.describeCSIDriver)
+		case "<cstor_volume_config>":
+			f = r.CaptureCStorVolumeConfig(r.describeCStorVolumeConfig)
+		case "<cstor_volume_attachment>":
+			f = r.CaptureCStorVolumeAttachment(r.describeCStorVolumeAttachment)
 		default:
+			if _, _, ok := report.ParseCustomResourceNodeID(req.NodeID); ok {
+				f = r.CaptureCustomResource(r.describeCustomResource)
+				break
+			}
 			return xfer.ResponseErrorf("Node not found: %s", req.NodeID)
 		}
 		return f(req)
@@ -461,14 +545,14 @@ func (r *Reporter) CapturePersistentVolumeClaim(f func(xfer.Request, string, str
 		}
 
 		// find provisioner from storage class
-		var storageClass StorageClass
+		var provisioner string
 		r.client.WalkStorageClasses(func(p StorageClass) error {
 			if p.Name() == persistentVolumeClaim.GetStorageClass() {
-				storageClass = p
+				provisioner = p.GetProvisioner()
 			}
 			return nil
 		})
-		return f(req, persistentVolumeClaim.Namespace(), persistentVolumeClaim.Name(), persistentVolumeClaim.GetCapacity(), storageClass.GetProvisioner())
+		return f(req, persistentVolumeClaim.Namespace(), persistentVolumeClaim.Name(), persistentVolumeClaim.GetCapacity(), provisioner)
 	}
 }
 
@@ -581,6 +665,80 @@ func (r *Reporter) CaptureService(f func(xfer.Request, string, string) xfer.Resp
 	}
 }
 
+// CaptureNetworkPolicy is exported for testing
+func (r *Reporter) CaptureNetworkPolicy(f func(xfer.Request, string, string) xfer.Response) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		uid, ok := report.ParseNetworkPolicyNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+		var networkPolicy NetworkPolicy
+		r.client.WalkNetworkPolicies(func(p NetworkPolicy) error {
+			if p.UID() == uid {
+				networkPolicy = p
+			}
+			return nil
+		})
+		if networkPolicy == nil {
+			return xfer.ResponseErrorf("Network policy not found: %s", uid)
+		}
+		return f(req, networkPolicy.Namespace(), networkPolicy.Name())
+	}
+}
+
+// CaptureIngress is exported for testing
+func (r *Reporter) CaptureIngress(f func(xfer.Request, string, string) xfer.Response) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		uid, ok := report.ParseIngressNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+		var ingress Ingress
+		r.client.WalkIngresses(func(i Ingress) error {
+			if i.UID() == uid {
+				ingress = i
+			}
+			return nil
+		})
+		if ingress == nil {
+			return xfer.ResponseErrorf("Ingress not found: %s", uid)
+		}
+		return f(req, ingress.Namespace(), ingress.Name())
+	}
+}
+
+// CaptureNode is exported for testing
+func (r *Reporter) CaptureNode(f func(xfer.Request, string) xfer.Response) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		uid, ok := report.ParseKubernetesNodeNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+		var node Node
+		r.client.WalkNodes(func(n Node) error {
+			if n.UID() == uid {
+				node = n
+			}
+			return nil
+		})
+		if node == nil {
+			return xfer.ResponseErrorf("Node not found: %s", uid)
+		}
+		return f(req, node.Name())
+	}
+}
+
+// CaptureCSIDriver is exported for testing
+func (r *Reporter) CaptureCSIDriver(f func(xfer.Request, string) xfer.Response) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		name, ok := report.ParseCSIDriverNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+		return f(req, name)
+	}
+}
+
 // CaptureDaemonSet is exported for testing
 func (r *Reporter) CaptureDaemonSet(f func(xfer.Request, string, string) xfer.Response) func(xfer.Request) xfer.Response {
 	return func(req xfer.Request) xfer.Response {
@@ -928,6 +1086,75 @@ func (r *Reporter) CaptureCStorPoolInstance(f func(xfer.Request, string, string)
 	}
 }
 
+// CaptureCStorVolumeConfig will return the name and namespace of the cStor volume config
+func (r *Reporter) CaptureCStorVolumeConfig(f func(xfer.Request, string, string) xfer.Response) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		uid, ok := report.ParseCStorVolumeConfigNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+		var cStorVolumeConfig CStorVolumeConfig
+		r.client.WalkCStorVolumeConfigs(func(c CStorVolumeConfig) error {
+			if c.UID() == uid {
+				cStorVolumeConfig = c
+			}
+			return nil
+		})
+		if cStorVolumeConfig == nil {
+			return xfer.ResponseErrorf("CStor Volume Config not found: %s", uid)
+		}
+		return f(req, cStorVolumeConfig.Namespace(), cStorVolumeConfig.Name())
+	}
+}
+
+// CaptureCStorVolumeAttachment will return the name and namespace of the cStor volume attachment
+func (r *Reporter) CaptureCStorVolumeAttachment(f func(xfer.Request, string, string) xfer.Response) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		uid, ok := report.ParseCStorVolumeAttachmentNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+		var cStorVolumeAttachment CStorVolumeAttachment
+		r.client.WalkCStorVolumeAttachments(func(a CStorVolumeAttachment) error {
+			if a.UID() == uid {
+				cStorVolumeAttachment = a
+			}
+			return nil
+		})
+		if cStorVolumeAttachment == nil {
+			return xfer.ResponseErrorf("CStor Volume Attachment not found: %s", uid)
+		}
+		return f(req, cStorVolumeAttachment.Namespace(), cStorVolumeAttachment.Name())
+	}
+}
+
+// CaptureCustomResource is exported for testing
+func (r *Reporter) CaptureCustomResource(f func(xfer.Request, CustomResourceConfig, string, string) xfer.Response) func(xfer.Request) xfer.Response {
+	return func(req xfer.Request) xfer.Response {
+		topology, uid, ok := report.ParseCustomResourceNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+		for _, c := range r.customResources {
+			if c.Topology() != topology {
+				continue
+			}
+			var customResource CustomResource
+			r.client.WalkCustomResources(c.GroupVersionResource(), func(cr CustomResource) error {
+				if cr.UID() == uid {
+					customResource = cr
+				}
+				return nil
+			})
+			if customResource == nil {
+				return xfer.ResponseErrorf("%s not found: %s", c.Kind, uid)
+			}
+			return f(req, c, customResource.Namespace(), customResource.Name())
+		}
+		return xfer.ResponseErrorf("Custom resource not reported: %s", topology)
+	}
+}
+
 // ScaleUp is the control to scale up a deployment
 func (r *Reporter) ScaleUp(req xfer.Request, namespace, id string) xfer.Response {
 	return xfer.ResponseError(r.client.ScaleUp(ctx, namespace, id))
@@ -938,6 +1165,117 @@ func (r *Reporter) ScaleDown(req xfer.Request, namespace, id string) xfer.Respon
 	return xfer.ResponseError(r.client.ScaleDown(ctx, namespace, id))
 }
 
+// CaptureController resolves the node of a Deployment, DaemonSet or
+// StatefulSet to its kind, namespace and name
+func (r *Reporter) CaptureController(f func(xfer.Request, string, string, string) xfer.Response) func(xfer.Request) xfer.Response {
+	withKind := func(kind string) func(xfer.Request, string, string) xfer.Response {
+		return func(req xfer.Request, namespaceID, id string) xfer.Response {
+			return f(req, kind, namespaceID, id)
+		}
+	}
+	return func(req xfer.Request) xfer.Response {
+		_, tag, ok := report.ParseNodeID(req.NodeID)
+		if !ok {
+			return xfer.ResponseErrorf("Invalid ID: %s", req.NodeID)
+		}
+		switch tag {
+		case "<deployment>":
+			return r.CaptureDeployment(withKind("Deployment"))(req)
+		case "<daemonset>":
+			return r.CaptureDaemonSet(withKind("DaemonSet"))(req)
+		case "<statefulset>":
+			return r.CaptureStatefulSet(withKind("StatefulSet"))(req)
+		}
+		return xfer.ResponseErrorf("Not a controller: %s", req.NodeID)
+	}
+}
+
+// restartRollout is the control to restart the pods of a controller
+func (r *Reporter) restartRollout(req xfer.Request, kind, namespaceID, id string) xfer.Response {
+	return xfer.ResponseError(r.client.RestartRollout(ctx, kind, namespaceID, id))
+}
+
+// setReplicas is the control to scale a controller to the number of
+// replicas given in the request
+func (r *Reporter) setReplicas(req xfer.Request, kind, namespaceID, id string) xfer.Response {
+	arg, ok := req.ControlArgs["replicas"]
+	if !ok {
+		return xfer.ResponseErrorf("Missing number of replicas")
+	}
+	replicas, err := strconv.ParseInt(arg, 10, 32)
+	if err != nil || replicas < 0 {
+		return xfer.ResponseErrorf("Invalid number of replicas %q", arg)
+	}
+	return xfer.ResponseError(r.client.SetReplicas(ctx, kind, namespaceID, id, int32(replicas)))
+}
+
+// rollbackDeployment is the control to roll a deployment back to the
+// revision given in the request, or to the one before its current
+// revision
+func (r *Reporter) rollbackDeployment(req xfer.Request, namespaceID, id string) xfer.Response {
+	var deployment Deployment
+	r.client.WalkDeployments(func(d Deployment) error {
+		if d.Namespace() == namespaceID && d.Name() == id {
+			deployment = d
+		}
+		return nil
+	})
+	if deployment == nil {
+		return xfer.ResponseErrorf("Deployment not found: %s/%s", namespaceID, id)
+	}
+	current := deployment.Revision()
+
+	var toRevision int64
+	if arg, ok := req.ControlArgs["revision"]; ok {
+		v, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || v <= 0 {
+			return xfer.ResponseErrorf("Invalid revision %q", arg)
+		}
+		if v == current {
+			return xfer.ResponseErrorf("Deployment %s is already at revision %d", id, v)
+		}
+		toRevision = v
+	}
+
+	var target ReplicaSet
+	r.client.WalkReplicaSets(func(rs ReplicaSet) error {
+		if rs.ControllerUID() != deployment.UID() {
+			return nil
+		}
+		switch revision := rs.Revision(); {
+		case toRevision > 0:
+			if revision == toRevision {
+				target = rs
+			}
+		case revision < current && (target == nil || revision > target.Revision()):
+			target = rs
+		}
+		return nil
+	})
+	if target == nil {
+		if toRevision > 0 {
+			return xfer.ResponseErrorf("Revision %d of deployment %s not found", toRevision, id)
+		}
+		return xfer.ResponseErrorf("Deployment %s has no previous revision", id)
+	}
+	return xfer.ResponseError(r.client.RollbackDeployment(ctx, namespaceID, id, target.Name()))
+}
+
+// cordonNode is the control to mark a node as unschedulable
+func (r *Reporter) cordonNode(req xfer.Request, name string) xfer.Response {
+	return xfer.ResponseError(r.client.CordonNode(ctx, name, true))
+}
+
+// uncordonNode is the control to mark a node as schedulable again
+func (r *Reporter) uncordonNode(req xfer.Request, name string) xfer.Response {
+	return xfer.ResponseError(r.client.CordonNode(ctx, name, false))
+}
+
+// drainNode is the control to cordon a node and evict its pods
+func (r *Reporter) drainNode(req xfer.Request, name string) xfer.Response {
+	return xfer.ResponseError(r.client.DrainNode(ctx, name))
+}
+
 func (r *Reporter) registerControls() {
 	controls := map[string]xfer.ControlHandlerFunc{
 		CloneVolumeSnapshot:     r.CaptureVolumeSnapshot(r.cloneVolumeSnapshot),
@@ -950,6 +1288,14 @@ func (r *Reporter) registerControls() {
 		DeleteCsiVolumeSnapshot: r.CaptureCsiVolumeSnapshot(r.deleteCsiVolumeSnapshot),
 		ScaleUp:                 r.CaptureDeployment(r.ScaleUp),
 		ScaleDown:               r.CaptureDeployment(r.ScaleDown),
+		SetReplicas:             r.CaptureController(r.setReplicas),
+		RestartRollout:          r.CaptureController(r.restartRollout),
+		RollbackDeployment:      r.CaptureDeployment(r.rollbackDeployment),
+		CordonNode:              r.CaptureNode(r.cordonNode),
+		UncordonNode:            r.CaptureNode(r.uncordonNode),
+		DrainNode:               r.CaptureNode(r.drainNode),
+		ExpandVolumeClaim:       r.CapturePersistentVolumeClaim(r.expandVolumeClaim),
+		DeleteVolumeClaim:       r.CapturePersistentVolumeClaim(r.deleteVolumeClaim),
 	}
 	r.handlerRegistry.Batch(nil, controls)
 }
@@ -966,6 +1312,14 @@ func (r *Reporter) deregisterControls() {
 		DeleteCsiVolumeSnapshot,
 		ScaleUp,
 		ScaleDown,
+		SetReplicas,
+		RestartRollout,
+		RollbackDeployment,
+		CordonNode,
+		UncordonNode,
+		DrainNode,
+		ExpandVolumeClaim,
+		DeleteVolumeClaim,
 	}
 	r.handlerRegistry.Batch(controls, nil)
 }
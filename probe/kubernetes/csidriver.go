@@ -0,0 +1,130 @@
+package kubernetes
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/weaveworks/scope/report"
+
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+// These constants are keys used in node metadata
+const (
+	AttachRequired       = report.KubernetesAttachRequired
+	VolumeLifecycleModes = report.KubernetesVolumeLifecycleModes
+	SnapshotSupport      = report.KubernetesSnapshotSupport
+	CSINodes             = report.KubernetesCSINodes
+)
+
+// CSIDriver represents a Kubernetes CSIDriver object
+type CSIDriver interface {
+	Meta
+	AttachRequired() bool
+	LifecycleModes() []string
+	SupportsSnapshots() bool
+}
+
+type csiDriver struct {
+	*storagev1.CSIDriver
+	Meta
+}
+
+// NewCSIDriver creates a new CSIDriver
+func NewCSIDriver(d *storagev1.CSIDriver) CSIDriver {
+	return &csiDriver{CSIDriver: d, Meta: meta{d.ObjectMeta}}
+}
+
+// AttachRequired tells whether volumes of the driver need to be attached
+// before being mounted. Unset, Kubernetes assumes they do.
+func (d *csiDriver) AttachRequired() bool {
+	return d.Spec.AttachRequired == nil || *d.Spec.AttachRequired
+}
+
+// LifecycleModes returns the volume lifecycle modes the driver supports.
+// Unset, Kubernetes assumes persistent volumes only.
+func (d *csiDriver) LifecycleModes() []string {
+	if len(d.Spec.VolumeLifecycleModes) == 0 {
+		return []string{string(storagev1.VolumeLifecyclePersistent)}
+	}
+	modes := make([]string, 0, len(d.Spec.VolumeLifecycleModes))
+	for _, m := range d.Spec.VolumeLifecycleModes {
+		modes = append(modes, string(m))
+	}
+	return modes
+}
+
+// SupportsSnapshots tells whether volumes of the driver can be
+// snapshotted: only persistent volumes can, so drivers limited to
+// ephemeral inline volumes cannot.
+func (d *csiDriver) SupportsSnapshots() bool {
+	for _, m := range d.LifecycleModes() {
+		if m == string(storagev1.VolumeLifecyclePersistent) {
+			return true
+		}
+	}
+	return false
+}
+
+// CSINode represents a Kubernetes CSINode object, which lists the CSI
+// drivers registered on a node
+type CSINode interface {
+	Meta
+	Drivers() []string
+}
+
+type csiNode struct {
+	*storagev1.CSINode
+	Meta
+}
+
+// NewCSINode creates a new CSINode
+func NewCSINode(n *storagev1.CSINode) CSINode {
+	return &csiNode{CSINode: n, Meta: meta{n.ObjectMeta}}
+}
+
+func (n *csiNode) Drivers() []string {
+	drivers := make([]string, 0, len(n.Spec.Drivers))
+	for _, d := range n.Spec.Drivers {
+		drivers = append(drivers, d.Name)
+	}
+	return drivers
+}
+
+// CSIDriverInfo is what is known of a CSI driver from its CSIDriver
+// object, if it has one, and the CSINodes it is registered on.
+type CSIDriverInfo struct {
+	Name   string
+	Driver CSIDriver
+	Nodes  []string
+}
+
+// SupportsSnapshots tells whether volumes of the driver can be
+// snapshotted. Drivers without a CSIDriver object are taken to provide
+// persistent volumes, as Kubernetes does.
+func (i CSIDriverInfo) SupportsSnapshots() bool {
+	return i.Driver == nil || i.Driver.SupportsSnapshots()
+}
+
+// GetNode returns the CSI driver as a node
+func (i CSIDriverInfo) GetNode(probeID string) report.Node {
+	nodes := append([]string{}, i.Nodes...)
+	sort.Strings(nodes)
+	latest := map[string]string{
+		Name:                  i.Name,
+		NodeType:              "CSI Driver",
+		SnapshotSupport:       strconv.FormatBool(i.SupportsSnapshots()),
+		CSINodes:              strings.Join(nodes, report.ScopeDelim),
+		report.ControlProbeID: probeID,
+	}
+	id := report.MakeCSIDriverNodeID(i.Name)
+	n := report.MakeNode(id)
+	if i.Driver != nil {
+		n = i.Driver.MetaNode(id).WithLatestActiveControls(Describe)
+		latest[AttachRequired] = strconv.FormatBool(i.Driver.AttachRequired())
+		latest[VolumeLifecycleModes] = strings.Join(i.Driver.LifecycleModes(), ",")
+	}
+	return n.WithLatests(latest).
+		WithCounters(map[string]int{report.KubernetesNode: len(nodes)})
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	cstorv1 "github.com/openebs/api/pkg/apis/cstor/v1"
+	"github.com/weaveworks/common/mtime"
 	"github.com/weaveworks/scope/report"
 )
 
@@ -13,6 +14,7 @@ type CStorPoolInstance interface {
 	Meta
 	GetNode(probeID string) report.Node
 	GetBlockDeviceList() string
+	GetMetrics() report.Metrics
 }
 
 // cStorPoolInstance represent the cStorPoolInstance CRD of Kubernetes.
@@ -51,5 +53,19 @@ func (c *cStorPoolInstance) GetNode(probeID string) report.Node {
 		BlockDeviceList:       c.GetBlockDeviceList(),
 		StoragePoolClaimName:  c.GetLabels()["openebs.io/cstor-pool-cluster"],
 		report.ControlProbeID: probeID,
-	}).WithLatestActiveControls(Describe)
+	}).WithMetrics(c.GetMetrics()).WithLatestActiveControls(Describe)
+}
+
+// GetMetrics returns the used and free space of the pool instance
+func (c *cStorPoolInstance) GetMetrics() report.Metrics {
+	used := float64(c.Status.Capacity.Used.Value())
+	free := float64(c.Status.Capacity.Free.Value())
+	if used == 0 && free == 0 {
+		return report.Metrics{}
+	}
+	now := mtime.Now()
+	return report.Metrics{
+		PoolUsedBytes: report.MakeSingletonMetric(now, used).WithMax(used + free),
+		PoolFreeBytes: report.MakeSingletonMetric(now, free).WithMax(used + free),
+	}
 }
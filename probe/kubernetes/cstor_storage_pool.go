@@ -1,12 +1,20 @@
 package kubernetes
 
 import (
+	"strconv"
 	"strings"
 
 	mayav1alpha1 "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
+	"github.com/weaveworks/common/mtime"
 	"github.com/weaveworks/scope/report"
 )
 
+// These constants are keys used in node metrics
+const (
+	PoolUsedBytes = report.KubernetesPoolUsedBytes
+	PoolFreeBytes = report.KubernetesPoolFreeBytes
+)
+
 // CStorPool interface
 type CStorPool interface {
 	Meta
@@ -17,6 +25,7 @@ type CStorPool interface {
 	GetStoragePoolClaim() string
 	GetDiskList() string
 	GetBlockDeviceList() string
+	GetMetrics() report.Metrics
 }
 
 // cStorPool represents cStor Volume CSP
@@ -48,6 +57,7 @@ func (p *cStorPool) GetNode(probeID string) report.Node {
 	}
 	return p.MetaNode(report.MakeCStorPoolNodeID(p.UID())).
 		WithLatests(latests).
+		WithMetrics(p.GetMetrics()).
 		WithNodeTag(p.GetNodeTagOnStatus(strings.ToLower(status))).
 		WithLatestActiveControls(Describe)
 }
@@ -93,3 +103,39 @@ func (p *cStorPool) GetStoragePoolClaim() string {
 	host := p.Labels()["openebs.io/storage-pool-claim"]
 	return string(host)
 }
+
+// GetMetrics returns the used and free space of the pool, as reported by
+// the pool itself, once it reported them.
+func (p *cStorPool) GetMetrics() report.Metrics {
+	used, usedOK := parseZFSSize(p.Status.Capacity.Used)
+	free, freeOK := parseZFSSize(p.Status.Capacity.Free)
+	if !usedOK || !freeOK {
+		return report.Metrics{}
+	}
+	now := mtime.Now()
+	return report.Metrics{
+		PoolUsedBytes: report.MakeSingletonMetric(now, used).WithMax(used + free),
+		PoolFreeBytes: report.MakeSingletonMetric(now, free).WithMax(used + free),
+	}
+}
+
+// parseZFSSize parses a size as printed by zfs, such as 9.94G, whose
+// suffixes stand for powers of 1024.
+func parseZFSSize(size string) (float64, bool) {
+	size = strings.TrimSpace(size)
+	if size == "" {
+		return 0, false
+	}
+	multiplier := 1.0
+	if i := strings.IndexByte("KMGTPE", size[len(size)-1]); i >= 0 {
+		for ; i >= 0; i-- {
+			multiplier *= 1024
+		}
+		size = size[:len(size)-1]
+	}
+	value, err := strconv.ParseFloat(size, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value * multiplier, true
+}
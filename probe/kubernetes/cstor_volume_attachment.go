@@ -0,0 +1,81 @@
+package kubernetes
+
+import (
+	"github.com/weaveworks/scope/report"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CStorVolumeAttachmentResource is the resource of CStorVolumeAttachments.
+// Their types live in the cStor CSI driver rather than in the OpenEBS API,
+// so they are read through the dynamic client.
+var CStorVolumeAttachmentResource = schema.GroupVersionResource{
+	Group:    CStorGroupName,
+	Version:  CStorVersion,
+	Resource: "cstorvolumeattachments",
+}
+
+// CStorVolumeAttachment represents a CStorVolumeAttachment, which records
+// the node a cStor CSI volume is attached to
+type CStorVolumeAttachment interface {
+	Meta
+	GetNode(probeID string) report.Node
+	GetPersistentVolumeName() string
+	GetOwnerNode() string
+}
+
+type cStorVolumeAttachment struct {
+	*unstructured.Unstructured
+	Meta
+}
+
+// NewCStorVolumeAttachment returns fresh CStorVolumeAttachment instance
+func NewCStorVolumeAttachment(u *unstructured.Unstructured) CStorVolumeAttachment {
+	objectMeta := metav1.ObjectMeta{
+		Name:              u.GetName(),
+		Namespace:         u.GetNamespace(),
+		UID:               u.GetUID(),
+		Labels:            u.GetLabels(),
+		Annotations:       u.GetAnnotations(),
+		CreationTimestamp: u.GetCreationTimestamp(),
+	}
+	return &cStorVolumeAttachment{Unstructured: u, Meta: meta{objectMeta}}
+}
+
+func (a *cStorVolumeAttachment) field(fields ...string) string {
+	value, _, _ := unstructured.NestedString(a.Object, fields...)
+	return value
+}
+
+// GetPersistentVolumeName returns the name of the attached volume
+func (a *cStorVolumeAttachment) GetPersistentVolumeName() string {
+	return a.field("spec", "volume", "name")
+}
+
+// GetOwnerNode returns the node the volume is attached to
+func (a *cStorVolumeAttachment) GetOwnerNode() string {
+	return a.field("spec", "volume", "ownerNodeID")
+}
+
+// GetNode returns the CStorVolumeAttachment as a node
+func (a *cStorVolumeAttachment) GetNode(probeID string) report.Node {
+	latests := map[string]string{
+		NodeType:              "CStor Volume Attachment",
+		VolumeName:            a.GetPersistentVolumeName(),
+		PublishedNode:         a.GetOwnerNode(),
+		report.ControlProbeID: probeID,
+	}
+	if status := a.field("status"); status != "" {
+		latests[Status] = status
+	}
+	if capacity := a.field("spec", "volume", "capacity"); capacity != "" {
+		latests[VolumeCapacity] = capacity
+	}
+	if devicePath := a.field("spec", "volume", "devicePath"); devicePath != "" {
+		latests[DevicePath] = devicePath
+	}
+	return a.MetaNode(report.MakeCStorVolumeAttachmentNodeID(a.UID())).
+		WithLatests(latests).
+		WithLatestActiveControls(Describe)
+}
@@ -0,0 +1,81 @@
+package kubernetes
+
+import (
+	"strconv"
+	"strings"
+
+	cstorv1 "github.com/openebs/api/pkg/apis/cstor/v1"
+	"github.com/weaveworks/scope/report"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// These constants are keys used in node metadata
+const (
+	LocalPath          = report.KubernetesLocalPath
+	CStorPoolInstances = report.KubernetesCStorPoolInstances
+	PublishedNode      = report.KubernetesPublishedNode
+	DevicePath         = report.KubernetesDevicePath
+)
+
+// CStorVolumeConfig represents a CStorVolumeConfig, from which the cStor
+// CSI driver provisions the cStor volume of a persistent volume
+type CStorVolumeConfig interface {
+	Meta
+	GetNode(probeID string) report.Node
+	GetPersistentVolumeName() string
+	GetPoolInstances() []string
+	GetPublishedNode() string
+}
+
+type cStorVolumeConfig struct {
+	*cstorv1.CStorVolumeConfig
+	Meta
+}
+
+// NewCStorVolumeConfig returns fresh CStorVolumeConfig instance
+func NewCStorVolumeConfig(c *cstorv1.CStorVolumeConfig) CStorVolumeConfig {
+	return &cStorVolumeConfig{CStorVolumeConfig: c, Meta: meta{c.ObjectMeta}}
+}
+
+// GetPersistentVolumeName returns the name of the persistent volume, which
+// the CStorVolumeConfig is named after.
+func (c *cStorVolumeConfig) GetPersistentVolumeName() string {
+	if pv, ok := c.GetLabels()["openebs.io/persistent-volume"]; ok {
+		return pv
+	}
+	return c.Name()
+}
+
+// GetPoolInstances returns the names of the pool instances holding the
+// replicas of the volume
+func (c *cStorVolumeConfig) GetPoolInstances() []string {
+	return c.Status.PoolInfo
+}
+
+// GetPublishedNode returns the node the volume is attached to
+func (c *cStorVolumeConfig) GetPublishedNode() string {
+	return c.Publish.NodeID
+}
+
+// GetNode returns the CStorVolumeConfig as a node
+func (c *cStorVolumeConfig) GetNode(probeID string) report.Node {
+	latests := map[string]string{
+		NodeType:              "CStor Volume Config",
+		VolumeName:            c.GetPersistentVolumeName(),
+		Status:                string(c.Status.Phase),
+		Replicas:              strconv.Itoa(c.Spec.Provision.ReplicaCount),
+		CStorPoolInstances:    strings.Join(c.GetPoolInstances(), report.ScopeDelim),
+		report.ControlProbeID: probeID,
+	}
+	if capacity, ok := c.Status.Capacity[apiv1.ResourceStorage]; ok {
+		latests[VolumeCapacity] = capacity.String()
+	} else if capacity, ok := c.Spec.Capacity[apiv1.ResourceStorage]; ok {
+		latests[VolumeCapacity] = capacity.String()
+	}
+	if c.GetPublishedNode() != "" {
+		latests[PublishedNode] = c.GetPublishedNode()
+	}
+	return c.MetaNode(report.MakeCStorVolumeConfigNodeID(c.UID())).
+		WithLatests(latests).
+		WithLatestActiveControls(Describe)
+}
@@ -0,0 +1,315 @@
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/weaveworks/scope/report"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+// These constants are keys used in node metadata
+const (
+	CustomResourceKind  = report.KubernetesCustomResourceKind
+	CustomResourceName  = report.KubernetesCustomResourceName
+	CustomResourceLabel = report.KubernetesCustomResourceLabel
+)
+
+// CustomResourceConfig configures the reporting of the custom resources of
+// a CustomResourceDefinition, so that operators the probe knows nothing
+// about can be shown. The paths are JSONPath templates, as in kubectl,
+// evaluated against each resource. The probe's cluster role must allow it
+// to list and watch the resources, and to get them and list events for the
+// describe control; see examples/k8s/cluster-role.yaml.
+type CustomResourceConfig struct {
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"`
+	// Kind of the resources, by which other resources refer to them
+	Kind string `json:"kind"`
+
+	// Label and LabelPlural name the resources in the UI; they default
+	// to the kind.
+	Label       string `json:"label,omitempty"`
+	LabelPlural string `json:"labelPlural,omitempty"`
+	Shape       string `json:"shape,omitempty"`
+
+	// NamePath is the name resources are shown by, instead of theirs.
+	NamePath string `json:"namePath,omitempty"`
+	// LabelPath is the label shown below the name of resources.
+	LabelPath string `json:"labelPath,omitempty"`
+	// StatusPath is the status of resources.
+	StatusPath string `json:"statusPath,omitempty"`
+
+	Parents []CustomResourceParent `json:"parents,omitempty"`
+
+	namePath, labelPath, statusPath *jsonpath.JSONPath
+}
+
+// CustomResourceParent is a rule finding the parents of custom resources.
+type CustomResourceParent struct {
+	// Kind of the parents: either that of other configured custom
+	// resources, or one of the Kubernetes kinds in ownerTopologies.
+	Kind string `json:"kind"`
+	// NamePath is the name of the parent, in the namespace of the
+	// resource, for resources which refer to their parents by name.
+	// Without it, parents are found from the owner references of the
+	// resource; only custom resources can be found by name.
+	NamePath string `json:"namePath,omitempty"`
+
+	namePath *jsonpath.JSONPath
+}
+
+// ownerTopologies are the Kubernetes kinds custom resources can be owned
+// by, with the topology and node ID of the owners.
+var ownerTopologies = map[string]struct {
+	topology string
+	makeID   func(string) string
+}{
+	"Pod":                   {report.Pod, report.MakePodNodeID},
+	"Service":               {report.Service, report.MakeServiceNodeID},
+	"Deployment":            {report.Deployment, report.MakeDeploymentNodeID},
+	"DaemonSet":             {report.DaemonSet, report.MakeDaemonSetNodeID},
+	"StatefulSet":           {report.StatefulSet, report.MakeStatefulSetNodeID},
+	"CronJob":               {report.CronJob, report.MakeCronJobNodeID},
+	"Job":                   {report.Job, report.MakeJobNodeID},
+	"PersistentVolume":      {report.PersistentVolume, report.MakePersistentVolumeNodeID},
+	"PersistentVolumeClaim": {report.PersistentVolumeClaim, report.MakePersistentVolumeClaimNodeID},
+}
+
+// LoadCustomResourceConfigs reads the configuration of the custom
+// resources to report from a YAML or JSON file.
+func LoadCustomResourceConfigs(path string) ([]CustomResourceConfig, error) {
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCustomResourceConfigs(buf)
+}
+
+// ParseCustomResourceConfigs parses the configuration of the custom
+// resources to report, a YAML or JSON list of CustomResourceConfig.
+func ParseCustomResourceConfigs(buf []byte) ([]CustomResourceConfig, error) {
+	var configs []CustomResourceConfig
+	if err := yaml.Unmarshal(buf, &configs); err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	for i := range configs {
+		if err := configs[i].compile(); err != nil {
+			return nil, err
+		}
+		if _, ok := seen[configs[i].CRD()]; ok {
+			return nil, fmt.Errorf("%s configured more than once", configs[i].CRD())
+		}
+		seen[configs[i].CRD()] = struct{}{}
+	}
+	return configs, nil
+}
+
+func (c *CustomResourceConfig) compile() error {
+	if c.Group == "" || c.Version == "" || c.Resource == "" || c.Kind == "" {
+		return fmt.Errorf("custom resources need a group, version, resource and kind: %+v", *c)
+	}
+	var err error
+	if c.namePath, err = compileJSONPath(c.NamePath); err != nil {
+		return fmt.Errorf("%s: invalid name path: %v", c.CRD(), err)
+	}
+	if c.labelPath, err = compileJSONPath(c.LabelPath); err != nil {
+		return fmt.Errorf("%s: invalid label path: %v", c.CRD(), err)
+	}
+	if c.statusPath, err = compileJSONPath(c.StatusPath); err != nil {
+		return fmt.Errorf("%s: invalid status path: %v", c.CRD(), err)
+	}
+	for i, p := range c.Parents {
+		if p.Kind == "" {
+			return fmt.Errorf("%s: parents need a kind", c.CRD())
+		}
+		if c.Parents[i].namePath, err = compileJSONPath(p.NamePath); err != nil {
+			return fmt.Errorf("%s: invalid name path of %s parents: %v", c.CRD(), p.Kind, err)
+		}
+	}
+	return nil
+}
+
+// compileJSONPath parses a JSONPath template, if any. Missing fields
+// evaluate to nothing rather than failing.
+func compileJSONPath(template string) (*jsonpath.JSONPath, error) {
+	if template == "" {
+		return nil, nil
+	}
+	j := jsonpath.New(template).AllowMissingKeys(true)
+	if err := j.Parse(template); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// evalJSONPath evaluates a JSONPath template against a resource.
+func evalJSONPath(j *jsonpath.JSONPath, u *unstructured.Unstructured) string {
+	if j == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := j.Execute(&buf, u.Object); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// CRD returns the name of the CustomResourceDefinition of the resources.
+func (c CustomResourceConfig) CRD() string {
+	return c.Resource + "." + c.Group
+}
+
+// GroupVersionResource returns the resource the custom resources are
+// served as.
+func (c CustomResourceConfig) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: c.Group, Version: c.Version, Resource: c.Resource}
+}
+
+// Topology returns the name of the topology of the custom resources.
+func (c CustomResourceConfig) Topology() string {
+	return report.MakeCustomResourceTopology(c.CRD())
+}
+
+// MakeTopology returns an empty topology for the custom resources.
+func (c CustomResourceConfig) MakeTopology() report.Topology {
+	label, labelPlural, shape := c.Label, c.LabelPlural, c.Shape
+	if label == "" {
+		label = c.Kind
+	}
+	if labelPlural == "" {
+		labelPlural = label + "s"
+	}
+	if shape == "" {
+		shape = report.Square
+	}
+	result := report.MakeTopology().
+		WithShape(shape).
+		WithLabel(label, labelPlural).
+		WithMetadataTemplates(CustomResourceMetadataTemplates).
+		WithTableTemplates(TableTemplates)
+	result.Controls.AddControl(DescribeControl)
+	return result
+}
+
+// CustomResource represents a custom resource, read through the dynamic
+// client.
+type CustomResource interface {
+	Meta
+	Kind() string
+	APIVersion() string
+	OwnerReferences() []metav1.OwnerReference
+	Unstructured() *unstructured.Unstructured
+}
+
+type customResource struct {
+	u *unstructured.Unstructured
+	Meta
+}
+
+// NewCustomResource returns fresh CustomResource instance
+func NewCustomResource(u *unstructured.Unstructured) CustomResource {
+	objectMeta := metav1.ObjectMeta{
+		Name:              u.GetName(),
+		Namespace:         u.GetNamespace(),
+		UID:               u.GetUID(),
+		Labels:            u.GetLabels(),
+		Annotations:       u.GetAnnotations(),
+		CreationTimestamp: u.GetCreationTimestamp(),
+	}
+	return &customResource{u: u, Meta: meta{objectMeta}}
+}
+
+func (r *customResource) Kind() string {
+	return r.u.GetKind()
+}
+
+func (r *customResource) APIVersion() string {
+	return r.u.GetAPIVersion()
+}
+
+func (r *customResource) OwnerReferences() []metav1.OwnerReference {
+	return r.u.GetOwnerReferences()
+}
+
+func (r *customResource) Unstructured() *unstructured.Unstructured {
+	return r.u
+}
+
+// GetNode returns a custom resource as a node of the topology of its
+// configuration.
+func (c CustomResourceConfig) GetNode(r CustomResource, probeID string) report.Node {
+	u := r.Unstructured()
+	latests := map[string]string{
+		NodeType:              c.Kind,
+		CustomResourceKind:    c.Kind,
+		report.ControlProbeID: probeID,
+	}
+	if name := evalJSONPath(c.namePath, u); name != "" {
+		latests[CustomResourceName] = name
+	}
+	if label := evalJSONPath(c.labelPath, u); label != "" {
+		latests[CustomResourceLabel] = label
+	}
+	if status := evalJSONPath(c.statusPath, u); status != "" {
+		latests[Status] = status
+	}
+	return r.MetaNode(report.MakeCustomResourceNodeID(c.Topology(), r.UID())).
+		WithLatests(latests).
+		WithLatestActiveControls(Describe)
+}
+
+// customResourceIndex finds configured custom resources by kind and by
+// namespace and name.
+type customResourceIndex map[string]struct {
+	topology string
+	uids     map[string]string
+}
+
+func (idx customResourceIndex) add(c CustomResourceConfig, r CustomResource) {
+	entry, ok := idx[c.Kind]
+	if !ok {
+		entry.topology = c.Topology()
+		entry.uids = map[string]string{}
+	}
+	entry.uids[r.Namespace()+"/"+r.Name()] = r.UID()
+	idx[c.Kind] = entry
+}
+
+// parents returns the parents of a custom resource, by topology, as found
+// by the parent rules of its configuration.
+func (c CustomResourceConfig) parents(r CustomResource, idx customResourceIndex) report.Sets {
+	parents := report.MakeSets()
+	for _, p := range c.Parents {
+		if p.namePath != nil {
+			entry, ok := idx[p.Kind]
+			if !ok {
+				continue
+			}
+			name := evalJSONPath(p.namePath, r.Unstructured())
+			if uid, ok := entry.uids[r.Namespace()+"/"+name]; ok {
+				parents = parents.AddString(entry.topology, report.MakeCustomResourceNodeID(entry.topology, uid))
+			}
+			continue
+		}
+		for _, owner := range r.OwnerReferences() {
+			if owner.Kind != p.Kind {
+				continue
+			}
+			if entry, ok := idx[p.Kind]; ok {
+				parents = parents.AddString(entry.topology, report.MakeCustomResourceNodeID(entry.topology, string(owner.UID)))
+			} else if t, ok := ownerTopologies[p.Kind]; ok {
+				parents = parents.AddString(t.topology, t.makeID(string(owner.UID)))
+			}
+		}
+	}
+	return parents
+}
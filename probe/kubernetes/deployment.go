@@ -23,6 +23,7 @@ const (
 type Deployment interface {
 	Meta
 	Selector() (labels.Selector, error)
+	Revision() int64
 	GetNode(probeID string) report.Node
 }
 
@@ -45,6 +46,12 @@ func (d *deployment) Selector() (labels.Selector, error) {
 	return selector, nil
 }
 
+// Revision returns the current revision of the deployment, or 0 if it
+// hasn't been rolled out yet.
+func (d *deployment) Revision() int64 {
+	return revision(d.ObjectMeta)
+}
+
 func (d *deployment) GetNode(probeID string) report.Node {
 	// Spec.Replicas can be omitted, and the pointer will be nil. It defaults to 1.
 	desiredReplicas := 1
@@ -61,5 +68,5 @@ func (d *deployment) GetNode(probeID string) report.Node {
 		Strategy:              string(d.Spec.Strategy.Type),
 		report.ControlProbeID: probeID,
 		NodeType:              "Deployment",
-	}).WithLatestActiveControls(ScaleUp, ScaleDown, Describe)
+	}).WithLatestActiveControls(ScaleUp, ScaleDown, SetReplicas, RestartRollout, RollbackDeployment, Describe)
 }
@@ -0,0 +1,96 @@
+package kubernetes
+
+import (
+	"strconv"
+
+	"github.com/weaveworks/scope/report"
+
+	apiv1 "k8s.io/api/core/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+)
+
+// These constants are keys used in node metadata
+const (
+	ReadyEndpoints    = report.KubernetesReadyEndpoints
+	NotReadyEndpoints = report.KubernetesNotReadyEndpoints
+	EndpointPods      = report.KubernetesEndpointPods
+)
+
+// EndpointAddress is a single address backing a service.
+type EndpointAddress struct {
+	IP     string
+	PodUID string
+	Ready  bool
+}
+
+// ServiceEndpoints are the addresses backing a service, as reported by
+// its Endpoints or EndpointSlices.
+type ServiceEndpoints struct {
+	Namespace string
+	Service   string
+	Addresses []EndpointAddress
+}
+
+// NewServiceEndpoints creates ServiceEndpoints from an Endpoints object
+func NewServiceEndpoints(e *apiv1.Endpoints) ServiceEndpoints {
+	result := ServiceEndpoints{Namespace: e.Namespace, Service: e.Name}
+	add := func(addresses []apiv1.EndpointAddress, ready bool) {
+		for _, a := range addresses {
+			address := EndpointAddress{IP: a.IP, Ready: ready}
+			if a.TargetRef != nil && a.TargetRef.Kind == "Pod" {
+				address.PodUID = string(a.TargetRef.UID)
+			}
+			result.Addresses = append(result.Addresses, address)
+		}
+	}
+	for _, subset := range e.Subsets {
+		add(subset.Addresses, true)
+		add(subset.NotReadyAddresses, false)
+	}
+	return result
+}
+
+// NewServiceEndpointsFromSlice creates ServiceEndpoints from an
+// EndpointSlice. A service may have several slices, which are merged with
+// Add.
+func NewServiceEndpointsFromSlice(s *discoveryv1beta1.EndpointSlice) ServiceEndpoints {
+	result := ServiceEndpoints{Namespace: s.Namespace, Service: s.Labels[discoveryv1beta1.LabelServiceName]}
+	for _, e := range s.Endpoints {
+		// A nil ready condition is to be read as ready
+		ready := e.Conditions.Ready == nil || *e.Conditions.Ready
+		var podUID string
+		if e.TargetRef != nil && e.TargetRef.Kind == "Pod" {
+			podUID = string(e.TargetRef.UID)
+		}
+		for _, ip := range e.Addresses {
+			result.Addresses = append(result.Addresses, EndpointAddress{IP: ip, PodUID: podUID, Ready: ready})
+		}
+	}
+	return result
+}
+
+// Add merges the addresses of other into e
+func (e ServiceEndpoints) Add(other ServiceEndpoints) ServiceEndpoints {
+	e.Addresses = append(append([]EndpointAddress{}, e.Addresses...), other.Addresses...)
+	return e
+}
+
+// AddToNode records the endpoint counts and pods on a service node
+func (e ServiceEndpoints) AddToNode(n report.Node) report.Node {
+	var ready, notReady int
+	pods := []string{}
+	for _, a := range e.Addresses {
+		if a.Ready {
+			ready++
+		} else {
+			notReady++
+		}
+		if a.PodUID != "" {
+			pods = append(pods, report.MakePodNodeID(a.PodUID))
+		}
+	}
+	return n.WithLatests(map[string]string{
+		ReadyEndpoints:    strconv.Itoa(ready),
+		NotReadyEndpoints: strconv.Itoa(notReady),
+	}).WithSet(EndpointPods, report.MakeStringSet(pods...))
+}
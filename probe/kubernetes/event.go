@@ -0,0 +1,114 @@
+package kubernetes
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/weaveworks/scope/report"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// These constants are keys used in node metadata
+const (
+	EventsPrefix = report.KubernetesEventsPrefix
+)
+
+// maxNodeEvents is the number of events reported for each node, most
+// recent first.
+const maxNodeEvents = 10
+
+// ResourceEvent represents a Kubernetes event about a resource
+type ResourceEvent interface {
+	InvolvedObject() apiv1.ObjectReference
+	LastSeen() time.Time
+	Row(id string) report.Row
+}
+
+type resourceEvent struct {
+	*apiv1.Event
+}
+
+// NewResourceEvent creates a new ResourceEvent
+func NewResourceEvent(e *apiv1.Event) ResourceEvent {
+	return &resourceEvent{Event: e}
+}
+
+func (e *resourceEvent) InvolvedObject() apiv1.ObjectReference {
+	return e.Event.InvolvedObject
+}
+
+// LastSeen returns when the event last occurred. Events recorded through
+// the events.k8s.io API only have an event time.
+func (e *resourceEvent) LastSeen() time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	case !e.FirstTimestamp.IsZero():
+		return e.FirstTimestamp.Time
+	}
+	return e.CreationTimestamp.Time
+}
+
+func (e *resourceEvent) Row(id string) report.Row {
+	count := e.Count
+	if count == 0 {
+		count = 1
+	}
+	return report.Row{
+		ID: id,
+		Entries: map[string]string{
+			"last_seen": e.LastSeen().Format(time.RFC3339),
+			"type":      e.Type,
+			"reason":    e.Reason,
+			"message":   e.Message,
+			"count":     strconv.Itoa(int(count)),
+		},
+	}
+}
+
+// eventNodeIDs maps the kinds of resources events are attached to, to the
+// IDs of their nodes.
+var eventNodeIDs = map[string]func(apiv1.ObjectReference) string{
+	"Pod":                   func(o apiv1.ObjectReference) string { return report.MakePodNodeID(string(o.UID)) },
+	"Service":               func(o apiv1.ObjectReference) string { return report.MakeServiceNodeID(string(o.UID)) },
+	"Deployment":            func(o apiv1.ObjectReference) string { return report.MakeDeploymentNodeID(string(o.UID)) },
+	"DaemonSet":             func(o apiv1.ObjectReference) string { return report.MakeDaemonSetNodeID(string(o.UID)) },
+	"StatefulSet":           func(o apiv1.ObjectReference) string { return report.MakeStatefulSetNodeID(string(o.UID)) },
+	"CronJob":               func(o apiv1.ObjectReference) string { return report.MakeCronJobNodeID(string(o.UID)) },
+	"Job":                   func(o apiv1.ObjectReference) string { return report.MakeJobNodeID(string(o.UID)) },
+	"Node":                  func(o apiv1.ObjectReference) string { return report.MakeKubernetesNodeNodeID(string(o.UID)) },
+	"Ingress":               func(o apiv1.ObjectReference) string { return report.MakeIngressNodeID(string(o.UID)) },
+	"PersistentVolume":      func(o apiv1.ObjectReference) string { return report.MakePersistentVolumeNodeID(string(o.UID)) },
+	"PersistentVolumeClaim": func(o apiv1.ObjectReference) string { return report.MakePersistentVolumeClaimNodeID(string(o.UID)) },
+	"StorageClass":          func(o apiv1.ObjectReference) string { return report.MakeStorageClassNodeID(string(o.UID)) },
+	"CStorVolume":           func(o apiv1.ObjectReference) string { return report.MakeCStorVolumeNodeID(o.Name) },
+	"CStorVolumeReplica":    func(o apiv1.ObjectReference) string { return report.MakeCStorVolumeReplicaNodeID(string(o.UID)) },
+	"CStorPool":             func(o apiv1.ObjectReference) string { return report.MakeCStorPoolNodeID(string(o.UID)) },
+	"CStorPoolCluster":      func(o apiv1.ObjectReference) string { return report.MakeCStorPoolClusterNodeID(string(o.UID)) },
+	"CStorPoolInstance":     func(o apiv1.ObjectReference) string { return report.MakeCStorPoolInstanceNodeID(string(o.UID)) },
+	"BlockDevice":           func(o apiv1.ObjectReference) string { return report.MakeBlockDeviceNodeID(string(o.UID)) },
+	"BlockDeviceClaim":      func(o apiv1.ObjectReference) string { return report.MakeBlockDeviceClaimNodeID(string(o.UID)) },
+	"CStorVolumeConfig":     func(o apiv1.ObjectReference) string { return report.MakeCStorVolumeConfigNodeID(string(o.UID)) },
+	// Both snapshot APIs have VolumeSnapshots
+	"VolumeSnapshot": func(o apiv1.ObjectReference) string {
+		if strings.HasPrefix(o.APIVersion, CsiSnapshotGroupName+"/") {
+			return report.MakeCsiVolumeSnapshotNodeID(string(o.UID))
+		}
+		return report.MakeVolumeSnapshotNodeID(string(o.UID))
+	},
+}
+
+// EventNodeID returns the ID of the node an event is about, if events
+// about resources of its kind are reported.
+func EventNodeID(e ResourceEvent) (string, bool) {
+	o := e.InvolvedObject()
+	nodeID, ok := eventNodeIDs[o.Kind]
+	if !ok {
+		return "", false
+	}
+	return nodeID(o), true
+}
@@ -0,0 +1,165 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/weaveworks/scope/report"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// These constants are keys used in node metadata
+const (
+	Autoscaler              = report.KubernetesAutoscaler
+	MinReplicas             = report.KubernetesMinReplicas
+	MaxReplicas             = report.KubernetesMaxReplicas
+	AutoscalerMetricsPrefix = report.KubernetesAutoscalerMetricsPrefix
+)
+
+// autoscalerMinReplicas is the minimum number of replicas of autoscalers
+// which don't set one.
+const autoscalerMinReplicas = 1
+
+// HorizontalPodAutoscalerResource is the resource of autoscaling/v2
+// HorizontalPodAutoscalers, which replaced v2beta2 (removed in Kubernetes
+// 1.26). Its types aren't vendored, so it is read through the dynamic
+// client, into the v2beta2 types it is compatible with.
+var HorizontalPodAutoscalerResource = schema.GroupVersionResource{
+	Group:    "autoscaling",
+	Version:  "v2",
+	Resource: "horizontalpodautoscalers",
+}
+
+// HorizontalPodAutoscaler represents a Kubernetes HorizontalPodAutoscaler.
+// Autoscalers aren't nodes of their own, they are shown on the
+// controllers they scale.
+type HorizontalPodAutoscaler interface {
+	Meta
+	ScaleTarget() (kind, name string)
+	Latests() map[string]string
+	MetricRows() []report.Row
+}
+
+type horizontalPodAutoscaler struct {
+	*autoscalingv2beta2.HorizontalPodAutoscaler
+	Meta
+}
+
+// NewHorizontalPodAutoscaler creates a new HorizontalPodAutoscaler
+func NewHorizontalPodAutoscaler(h *autoscalingv2beta2.HorizontalPodAutoscaler) HorizontalPodAutoscaler {
+	return &horizontalPodAutoscaler{HorizontalPodAutoscaler: h, Meta: meta{h.ObjectMeta}}
+}
+
+func (h *horizontalPodAutoscaler) ScaleTarget() (string, string) {
+	return h.Spec.ScaleTargetRef.Kind, h.Spec.ScaleTargetRef.Name
+}
+
+// Latests returns the metadata shown on the scale target of the autoscaler
+func (h *horizontalPodAutoscaler) Latests() map[string]string {
+	minReplicas := int32(autoscalerMinReplicas)
+	if h.Spec.MinReplicas != nil {
+		minReplicas = *h.Spec.MinReplicas
+	}
+	return map[string]string{
+		Autoscaler:  h.Name(),
+		MinReplicas: fmt.Sprint(minReplicas),
+		MaxReplicas: fmt.Sprint(h.Spec.MaxReplicas),
+	}
+}
+
+// MetricRows returns a row for each metric the autoscaler scales on, with
+// its current value and target.
+func (h *horizontalPodAutoscaler) MetricRows() []report.Row {
+	current := map[string]string{}
+	for _, status := range h.Status.CurrentMetrics {
+		if name, value := metricStatus(status); name != "" {
+			current[name] = value
+		}
+	}
+	rows := make([]report.Row, 0, len(h.Spec.Metrics))
+	for i, spec := range h.Spec.Metrics {
+		name, target := metricSpec(spec)
+		if name == "" {
+			continue
+		}
+		rows = append(rows, report.Row{
+			ID: fmt.Sprintf("metric%02d", i),
+			Entries: map[string]string{
+				"metric":  name,
+				"current": current[name],
+				"target":  target,
+			},
+		})
+	}
+	return rows
+}
+
+// metricSpec returns the name and target of a metric, named the same way
+// as its status by metricStatus.
+func metricSpec(spec autoscalingv2beta2.MetricSpec) (string, string) {
+	switch {
+	case spec.Resource != nil:
+		return string(spec.Resource.Name), metricTarget(spec.Resource.Target)
+	case spec.Pods != nil:
+		return spec.Pods.Metric.Name, metricTarget(spec.Pods.Target)
+	case spec.Object != nil:
+		return objectMetricName(spec.Object.Metric, spec.Object.DescribedObject), metricTarget(spec.Object.Target)
+	case spec.External != nil:
+		return spec.External.Metric.Name, metricTarget(spec.External.Target)
+	}
+	return "", ""
+}
+
+// metricStatus returns the name and current value of a metric.
+func metricStatus(status autoscalingv2beta2.MetricStatus) (string, string) {
+	switch {
+	case status.Resource != nil:
+		return string(status.Resource.Name), metricValue(status.Resource.Current)
+	case status.Pods != nil:
+		return status.Pods.Metric.Name, metricValue(status.Pods.Current)
+	case status.Object != nil:
+		return objectMetricName(status.Object.Metric, status.Object.DescribedObject), metricValue(status.Object.Current)
+	case status.External != nil:
+		return status.External.Metric.Name, metricValue(status.External.Current)
+	}
+	return "", ""
+}
+
+func objectMetricName(metric autoscalingv2beta2.MetricIdentifier, object autoscalingv2beta2.CrossVersionObjectReference) string {
+	return fmt.Sprintf("%s on %s/%s", metric.Name, object.Kind, object.Name)
+}
+
+func metricTarget(target autoscalingv2beta2.MetricTarget) string {
+	switch {
+	case target.Type == autoscalingv2beta2.UtilizationMetricType && target.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *target.AverageUtilization)
+	case target.Type == autoscalingv2beta2.AverageValueMetricType && target.AverageValue != nil:
+		return target.AverageValue.String() + " (avg)"
+	case target.Type == autoscalingv2beta2.ValueMetricType && target.Value != nil:
+		return target.Value.String()
+	}
+	return ""
+}
+
+func metricValue(value autoscalingv2beta2.MetricValueStatus) string {
+	switch {
+	case value.AverageUtilization != nil:
+		return fmt.Sprintf("%d%%", *value.AverageUtilization)
+	case value.AverageValue != nil:
+		return value.AverageValue.String() + " (avg)"
+	case value.Value != nil:
+		return value.Value.String()
+	}
+	return ""
+}
+
+// autoscalerFromUnstructured converts an autoscaling/v2
+// HorizontalPodAutoscaler to the v2beta2 type.
+func autoscalerFromUnstructured(u *unstructured.Unstructured) (*autoscalingv2beta2.HorizontalPodAutoscaler, error) {
+	h := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), h)
+	return h, err
+}
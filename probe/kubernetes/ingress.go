@@ -0,0 +1,152 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/weaveworks/scope/report"
+
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// These constants are keys used in node metadata
+const (
+	IngressClass       = report.KubernetesIngressClass
+	Hosts              = report.KubernetesHosts
+	TLSHosts           = report.KubernetesTLSHosts
+	IngressBackends    = report.KubernetesIngressBackends
+	IngressRulesPrefix = report.KubernetesIngressRulesPrefix
+
+	ingressClassAnnotation = "kubernetes.io/ingress.class"
+)
+
+// Ingress represents a Kubernetes ingress
+type Ingress interface {
+	Meta
+	GetNode(probeID string) report.Node
+}
+
+type ingress struct {
+	*networkingv1.Ingress
+	Meta
+}
+
+// NewIngress creates a new Ingress
+func NewIngress(i *networkingv1.Ingress) Ingress {
+	return &ingress{Ingress: i, Meta: meta{i.ObjectMeta}}
+}
+
+func (i *ingress) class() string {
+	if i.Spec.IngressClassName != nil {
+		return *i.Spec.IngressClassName
+	}
+	return i.ObjectMeta.Annotations[ingressClassAnnotation]
+}
+
+// human-readable version of an ingress backend
+func ingressBackendString(b networkingv1.IngressBackend) string {
+	switch {
+	case b.Service == nil && b.Resource != nil:
+		return fmt.Sprintf("%s/%s", b.Resource.Kind, b.Resource.Name)
+	case b.Service == nil:
+		return ""
+	case b.Service.Port.Name != "":
+		return fmt.Sprintf("%s:%s", b.Service.Name, b.Service.Port.Name)
+	default:
+		return fmt.Sprintf("%s:%d", b.Service.Name, b.Service.Port.Number)
+	}
+}
+
+// backends returns the names of the services the ingress routes to.
+func (i *ingress) backends() []string {
+	seen := map[string]struct{}{}
+	services := []string{}
+	add := func(b *networkingv1.IngressBackend) {
+		if b == nil || b.Service == nil {
+			return
+		}
+		if _, ok := seen[b.Service.Name]; !ok {
+			seen[b.Service.Name] = struct{}{}
+			services = append(services, b.Service.Name)
+		}
+	}
+	add(i.Spec.DefaultBackend)
+	for _, rule := range i.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			add(&path.Backend)
+		}
+	}
+	return services
+}
+
+func (i *ingress) rules() []report.Row {
+	rows := []report.Row{}
+	addRow := func(host, path string, backend networkingv1.IngressBackend) {
+		if host == "" {
+			host = "*"
+		}
+		rows = append(rows, report.Row{
+			ID: fmt.Sprintf("rule%d", len(rows)),
+			Entries: map[string]string{
+				"host":    host,
+				"path":    path,
+				"backend": ingressBackendString(backend),
+			},
+		})
+	}
+	if i.Spec.DefaultBackend != nil {
+		addRow("", "", *i.Spec.DefaultBackend)
+	}
+	for _, rule := range i.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			addRow(rule.Host, path.Path, path.Backend)
+		}
+	}
+	return rows
+}
+
+func (i *ingress) GetNode(probeID string) report.Node {
+	var hosts, tlsHosts, ips []string
+	for _, rule := range i.Spec.Rules {
+		if rule.Host != "" {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	for _, tls := range i.Spec.TLS {
+		tlsHosts = append(tlsHosts, tls.Hosts...)
+	}
+	for _, lb := range i.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			ips = append(ips, lb.IP)
+		} else if lb.Hostname != "" {
+			ips = append(ips, lb.Hostname)
+		}
+	}
+	latest := map[string]string{
+		NodeType:              "Ingress",
+		IngressBackends:       strings.Join(i.backends(), report.ScopeDelim),
+		report.ControlProbeID: probeID,
+	}
+	if class := i.class(); class != "" {
+		latest[IngressClass] = class
+	}
+	if len(hosts) > 0 {
+		latest[Hosts] = strings.Join(hosts, ",")
+	}
+	if len(tlsHosts) > 0 {
+		latest[TLSHosts] = strings.Join(tlsHosts, ",")
+	}
+	if len(ips) > 0 {
+		latest[PublicIP] = strings.Join(ips, ",")
+	}
+	return i.MetaNode(report.MakeIngressNodeID(i.UID())).
+		WithLatests(latest).
+		AddPrefixMulticolumnTable(IngressRulesPrefix, i.rules()).
+		WithLatestActiveControls(Describe)
+}
@@ -0,0 +1,279 @@
+package kubernetes
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/scope/report"
+)
+
+// These constants are keys used in node metadata
+const (
+	JivaRole               = report.KubernetesJivaRole
+	JivaHostPath           = report.KubernetesJivaHostPath
+	JivaController         = report.KubernetesJivaController
+	JivaControllerPod      = report.KubernetesJivaControllerPod
+	JivaReplicaPods        = report.KubernetesJivaReplicaPods
+	JivaRebuildingReplicas = report.KubernetesJivaRebuildingReplicas
+	JivaReplicasPrefix     = report.KubernetesJivaReplicasPrefix
+)
+
+// Modes of the replicas registered with a Jiva controller
+const (
+	JivaReplicaModeRW  = "RW"
+	JivaReplicaModeWO  = "WO"
+	JivaReplicaModeERR = "ERR"
+)
+
+// jivaControllerPort is the port of the Jiva controller REST API
+const jivaControllerPort = 9501
+
+const (
+	// jivaControllerTimeout bounds how long polling waits for each
+	// controller to list its replicas.
+	jivaControllerTimeout = 2 * time.Second
+	// jivaPollInterval is how often controllers are polled.
+	jivaPollInterval = 10 * time.Second
+	// Controllers not reported for jivaForgetAfter aren't polled anymore.
+	jivaForgetAfter = 3 * jivaPollInterval
+)
+
+// JivaReplica is a replica as registered with its Jiva controller. A
+// replica in WO mode is being rebuilt from the others.
+type JivaReplica struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+}
+
+// IP returns the IP address of the replica, taken from its tcp:// address.
+func (r JivaReplica) IP() string {
+	u, err := url.Parse(r.Address)
+	if err != nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		return u.Host
+	}
+	return host
+}
+
+// JivaVolume is a Jiva volume, made of the controller and replica pods of
+// a persistent volume, along with the replicas as the controller sees
+// them, unless the controller couldn't be queried.
+type JivaVolume struct {
+	Name        string
+	Controller  Pod
+	ReplicaPods []Pod
+	Replicas    []JivaReplica
+	Unreachable bool
+}
+
+// status returns the overall status of the volume, along with the number
+// of healthy and rebuilding replicas.
+func (v JivaVolume) status() (string, int, int) {
+	if v.Controller == nil || v.Controller.PodIP() == "" {
+		return "Offline", 0, 0
+	}
+	if v.Unreachable {
+		return "Unknown", 0, 0
+	}
+	healthy, rebuilding := 0, 0
+	for _, r := range v.Replicas {
+		switch r.Mode {
+		case JivaReplicaModeRW:
+			healthy++
+		case JivaReplicaModeWO:
+			rebuilding++
+		}
+	}
+	switch {
+	case healthy == 0:
+		return "Offline", healthy, rebuilding
+	case rebuilding > 0:
+		return "Rebuilding", healthy, rebuilding
+	case healthy < len(v.ReplicaPods):
+		return "Degraded", healthy, rebuilding
+	}
+	return "Healthy", healthy, rebuilding
+}
+
+// replicaRows returns a row for each replica pod, with its mode as seen by
+// the controller, and for each replica registered with the controller
+// without a pod.
+func (v JivaVolume) replicaRows() []report.Row {
+	modes := map[string]string{}
+	for _, r := range v.Replicas {
+		modes[r.IP()] = r.Mode
+	}
+	rows := []report.Row{}
+	for _, p := range v.ReplicaPods {
+		mode, ok := modes[p.PodIP()]
+		switch {
+		case v.Unreachable:
+			mode = "Unknown"
+		case !ok:
+			mode = "Not registered"
+		}
+		delete(modes, p.PodIP())
+		rows = append(rows, report.Row{
+			ID: p.Name(),
+			Entries: map[string]string{
+				"pod":        p.Name(),
+				"node":       p.NodeName(),
+				"host_path":  p.HostPath(),
+				"address":    p.PodIP(),
+				"mode":       mode,
+				"rebuilding": strconv.FormatBool(mode == JivaReplicaModeWO),
+			},
+		})
+	}
+	for ip, mode := range modes {
+		rows = append(rows, report.Row{
+			ID: ip,
+			Entries: map[string]string{
+				"address":    ip,
+				"mode":       mode,
+				"rebuilding": strconv.FormatBool(mode == JivaReplicaModeWO),
+			},
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].ID < rows[j].ID })
+	return rows
+}
+
+// GetNode returns the Jiva volume as a node
+func (v JivaVolume) GetNode(probeID string) report.Node {
+	status, healthy, rebuilding := v.status()
+	latests := map[string]string{
+		Name:                   v.Name,
+		NodeType:               "Jiva Volume",
+		VolumeName:             v.Name,
+		Status:                 status,
+		ProvisionedReplicas:    strconv.Itoa(len(v.ReplicaPods)),
+		HealthyReplicas:        strconv.Itoa(healthy),
+		JivaRebuildingReplicas: strconv.Itoa(rebuilding),
+		report.ControlProbeID:  probeID,
+	}
+	replicaPods := make([]string, 0, len(v.ReplicaPods))
+	for _, p := range v.ReplicaPods {
+		replicaPods = append(replicaPods, report.MakePodNodeID(p.UID()))
+	}
+	n := report.MakeNode(report.MakeJivaVolumeNodeID(v.Name))
+	if v.Controller != nil {
+		latests[Namespace] = v.Controller.Namespace()
+		latests[JivaController] = v.Controller.Name()
+		n = n.WithSet(JivaControllerPod, report.MakeStringSet(report.MakePodNodeID(v.Controller.UID())))
+	}
+	return n.WithLatests(latests).
+		WithSet(JivaReplicaPods, report.MakeStringSet(replicaPods...)).
+		WithNodeTag(CStorVolumeStatusMap[strings.ToLower(status)]).
+		AddPrefixMulticolumnTable(JivaReplicasPrefix, v.replicaRows())
+}
+
+// jivaPoller asks the Jiva controllers for their replicas in the
+// background, all at once, so that reports don't wait for them.
+type jivaPoller struct {
+	client Client
+	poll   chan struct{}
+	quit   chan struct{}
+
+	sync.Mutex
+	controllers map[string]jivaControllerState // by IP
+}
+
+type jivaControllerState struct {
+	asked    time.Time
+	polled   bool
+	replicas []JivaReplica
+	err      error
+}
+
+func newJivaPoller(client Client) *jivaPoller {
+	p := &jivaPoller{
+		client:      client,
+		poll:        make(chan struct{}, 1),
+		quit:        make(chan struct{}),
+		controllers: map[string]jivaControllerState{},
+	}
+	go p.loop()
+	return p
+}
+
+// replicas returns the replicas of the controller at ip as last polled, and
+// whether it answered. Controllers are polled from the first time they are
+// asked for, until they aren't asked for anymore.
+func (p *jivaPoller) replicas(ip string) ([]JivaReplica, bool) {
+	p.Lock()
+	defer p.Unlock()
+	state, ok := p.controllers[ip]
+	if !ok {
+		select {
+		case p.poll <- struct{}{}:
+		default:
+		}
+	}
+	state.asked = time.Now()
+	p.controllers[ip] = state
+	return state.replicas, state.polled && state.err == nil
+}
+
+func (p *jivaPoller) loop() {
+	ticker := time.NewTicker(jivaPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.poll:
+		case <-p.quit:
+			return
+		}
+		p.pollControllers()
+	}
+}
+
+func (p *jivaPoller) pollControllers() {
+	p.Lock()
+	ips := make([]string, 0, len(p.controllers))
+	for ip, state := range p.controllers {
+		if time.Since(state.asked) > jivaForgetAfter {
+			delete(p.controllers, ip)
+			continue
+		}
+		ips = append(ips, ip)
+	}
+	p.Unlock()
+
+	var wg sync.WaitGroup
+	for _, ip := range ips {
+		wg.Add(1)
+		go func(ip string) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), jivaControllerTimeout)
+			replicas, err := p.client.GetJivaReplicas(ctx, ip)
+			cancel()
+			if err != nil {
+				log.Debugf("Cannot get replicas from Jiva controller %s: %v", ip, err)
+			}
+			p.Lock()
+			defer p.Unlock()
+			if state, ok := p.controllers[ip]; ok {
+				state.polled, state.replicas, state.err = true, replicas, err
+				p.controllers[ip] = state
+			}
+		}(ip)
+	}
+	wg.Wait()
+}
+
+func (p *jivaPoller) stop() {
+	close(p.quit)
+}
@@ -3,8 +3,19 @@ package kubernetes
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/ugorji/go/codec"
+
+	"github.com/weaveworks/scope/report"
+)
+
+// These constants are keys used in node metrics
+const (
+	VolumeUsedBytes      = report.KubernetesVolumeUsedBytes
+	VolumeAvailableBytes = report.KubernetesVolumeAvailableBytes
+	VolumeInodesUsed     = report.KubernetesVolumeInodesUsed
+	VolumeInodesFree     = report.KubernetesVolumeInodesFree
 )
 
 // Intentionally not using the full kubernetes library DS
@@ -35,3 +46,64 @@ var GetLocalPodUIDs = func(kubeletHost string) (map[string]struct{}, error) {
 	}
 	return result, nil
 }
+
+// VolumeStats are the usage statistics kubelet keeps of a volume mounted
+// by a pod, as found in its stats summary.
+type VolumeStats struct {
+	Name   string `json:"name"`
+	PVCRef *struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"pvcRef"`
+	CapacityBytes  uint64 `json:"capacityBytes"`
+	UsedBytes      uint64 `json:"usedBytes"`
+	AvailableBytes uint64 `json:"availableBytes"`
+	Inodes         uint64 `json:"inodes"`
+	InodesUsed     uint64 `json:"inodesUsed"`
+	InodesFree     uint64 `json:"inodesFree"`
+}
+
+// Metrics returns the volume statistics as metrics at the given time.
+func (s VolumeStats) Metrics(now time.Time) report.Metrics {
+	capacity, inodes := float64(s.CapacityBytes), float64(s.Inodes)
+	return report.Metrics{
+		VolumeUsedBytes:      report.MakeSingletonMetric(now, float64(s.UsedBytes)).WithMax(capacity),
+		VolumeAvailableBytes: report.MakeSingletonMetric(now, float64(s.AvailableBytes)).WithMax(capacity),
+		VolumeInodesUsed:     report.MakeSingletonMetric(now, float64(s.InodesUsed)).WithMax(inodes),
+		VolumeInodesFree:     report.MakeSingletonMetric(now, float64(s.InodesFree)).WithMax(inodes),
+	}
+}
+
+type statsSummary struct {
+	Pods []struct {
+		Volume []VolumeStats `json:"volume"`
+	} `json:"pods"`
+}
+
+// GetVolumeStats obtains the statistics of the persistent volume claims
+// mounted locally, keyed by namespace/name (it's just exported for testing)
+var GetVolumeStats = func(kubeletHost string) (map[string]VolumeStats, error) {
+	url := fmt.Sprintf("http://%s/stats/summary", kubeletHost)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	var summary statsSummary
+	if err := codec.NewDecoder(resp.Body, &codec.JsonHandle{}).Decode(&summary); err != nil {
+		return nil, err
+	}
+	result := map[string]VolumeStats{}
+	for _, pod := range summary.Pods {
+		for _, volume := range pod.Volume {
+			if volume.PVCRef == nil {
+				continue
+			}
+			result[volume.PVCRef.Namespace+"/"+volume.PVCRef.Name] = volume
+		}
+	}
+	return result, nil
+}
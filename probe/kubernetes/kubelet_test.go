@@ -46,3 +46,38 @@ func TestGetLocalPodUIDs(t *testing.T) {
 		}
 	}
 }
+
+const kubeletStatsSummaryJSONFile = "kubelet_stats_summary.json"
+
+func TestGetVolumeStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/stats/summary" {
+				t.Fatalf("unexpected path: %s", r.URL.Path)
+			}
+			b, err := ioutil.ReadFile(kubeletStatsSummaryJSONFile)
+			if err != nil {
+				t.Fatalf("unexpected error reading json file: %v", err)
+			}
+			w.Write(b)
+		},
+	))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	stats, err := kubernetes.GetVolumeStats(serverURL.Host)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Only volumes of persistent volume claims are kept
+	if len(stats) != 1 {
+		t.Errorf("unexpected length in volume stats (%d): expected 1", len(stats))
+	}
+	s, ok := stats["default/data-mysql-0"]
+	if !ok {
+		t.Fatalf("volume stats not found: default/data-mysql-0")
+	}
+	if s.UsedBytes != 3210739712 || s.CapacityBytes != 10726932480 || s.InodesUsed != 1071 {
+		t.Errorf("unexpected volume stats: %+v", s)
+	}
+}
@@ -0,0 +1,231 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/weaveworks/scope/report"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// These constants are keys used in node metadata
+const (
+	PodSelector       = report.KubernetesPodSelector
+	PolicyTypes       = report.KubernetesPolicyTypes
+	IngressRules      = report.KubernetesIngressRules
+	EgressRules       = report.KubernetesEgressRules
+	NetworkPolicySpec = report.KubernetesNetworkPolicySpec
+)
+
+// NetworkPolicy represents a Kubernetes network policy
+type NetworkPolicy interface {
+	Meta
+	GetNode(probeID string) report.Node
+}
+
+type networkPolicy struct {
+	*networkingv1.NetworkPolicy
+	Meta
+}
+
+// NewNetworkPolicy creates a new NetworkPolicy
+func NewNetworkPolicy(p *networkingv1.NetworkPolicy) NetworkPolicy {
+	return &networkPolicy{NetworkPolicy: p, Meta: meta{p.ObjectMeta}}
+}
+
+func (p *networkPolicy) GetNode(probeID string) report.Node {
+	latest := map[string]string{
+		NodeType:              "Network Policy",
+		PodSelector:           metav1.FormatLabelSelector(&p.Spec.PodSelector),
+		PolicyTypes:           strings.Join(policyTypes(p.Spec), ","),
+		IngressRules:          strconv.Itoa(len(p.Spec.Ingress)),
+		EgressRules:           strconv.Itoa(len(p.Spec.Egress)),
+		report.ControlProbeID: probeID,
+	}
+	// The app evaluates the policy against the pods it renders, so
+	// report the whole spec rather than a summary of it.
+	if spec, err := json.Marshal(p.Spec); err == nil {
+		latest[NetworkPolicySpec] = string(spec)
+	}
+	return p.MetaNode(report.MakeNetworkPolicyNodeID(p.UID())).
+		WithLatests(latest).
+		WithLatestActiveControls(Describe)
+}
+
+// policyTypes returns the effective policy types of a spec: policies
+// without types always affect ingress, and affect egress if they have
+// egress rules.
+func policyTypes(spec networkingv1.NetworkPolicySpec) []string {
+	if len(spec.PolicyTypes) == 0 {
+		types := []string{string(networkingv1.PolicyTypeIngress)}
+		if len(spec.Egress) > 0 {
+			types = append(types, string(networkingv1.PolicyTypeEgress))
+		}
+		return types
+	}
+	types := make([]string, 0, len(spec.PolicyTypes))
+	for _, t := range spec.PolicyTypes {
+		types = append(types, string(t))
+	}
+	return types
+}
+
+// PolicyPeer is a pod as seen by network policies.
+type PolicyPeer struct {
+	Namespace       string
+	Labels          map[string]string
+	NamespaceLabels map[string]string
+	IP              string
+}
+
+// NetworkPolicyRules are the rules of a reported network policy.
+type NetworkPolicyRules struct {
+	Namespace string
+	Spec      networkingv1.NetworkPolicySpec
+}
+
+// NetworkPolicyRulesFromNode decodes the rules of a network policy node.
+func NetworkPolicyRulesFromNode(n report.Node) (NetworkPolicyRules, bool) {
+	namespace, _ := n.Latest.Lookup(Namespace)
+	encoded, ok := n.Latest.Lookup(NetworkPolicySpec)
+	if !ok {
+		return NetworkPolicyRules{}, false
+	}
+	rules := NetworkPolicyRules{Namespace: namespace}
+	if err := json.Unmarshal([]byte(encoded), &rules.Spec); err != nil {
+		return NetworkPolicyRules{}, false
+	}
+	return rules, true
+}
+
+func (p NetworkPolicyRules) hasType(t networkingv1.PolicyType) bool {
+	for _, pt := range policyTypes(p.Spec) {
+		if pt == string(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Selects tells whether the policy applies to the pod.
+func (p NetworkPolicyRules) Selects(pod PolicyPeer) bool {
+	return pod.Namespace == p.Namespace && selectorMatches(&p.Spec.PodSelector, pod.Labels)
+}
+
+// AnyPort, with an empty protocol, asks NetworkPolicyAllows whether the
+// policies allow any traffic from src to dst, for connections whose port
+// is unknown.
+const AnyPort = -1
+
+// NetworkPolicyAllows tells whether the policies allow a connection from
+// src to dst on the given port and protocol. As in Kubernetes, a pod
+// selected by any policy of a type only accepts the traffic some rule of
+// those policies allows, and other pods accept everything. Named ports
+// cannot be resolved from the reports, so they match any port.
+func NetworkPolicyAllows(policies []NetworkPolicyRules, src, dst PolicyPeer, port int, protocol string) bool {
+	return allowedBy(policies, networkingv1.PolicyTypeEgress, src, dst, port, protocol) &&
+		allowedBy(policies, networkingv1.PolicyTypeIngress, dst, src, port, protocol)
+}
+
+// allowedBy checks the policies of type t selecting pod, against traffic
+// to or from peer.
+func allowedBy(policies []NetworkPolicyRules, t networkingv1.PolicyType, pod, peer PolicyPeer, port int, protocol string) bool {
+	isolated := false
+	for _, p := range policies {
+		if !p.hasType(t) || !p.Selects(pod) {
+			continue
+		}
+		isolated = true
+		if t == networkingv1.PolicyTypeIngress {
+			for _, rule := range p.Spec.Ingress {
+				if p.peersMatch(rule.From, peer) && portsMatch(rule.Ports, port, protocol) {
+					return true
+				}
+			}
+		} else {
+			for _, rule := range p.Spec.Egress {
+				if p.peersMatch(rule.To, peer) && portsMatch(rule.Ports, port, protocol) {
+					return true
+				}
+			}
+		}
+	}
+	return !isolated
+}
+
+// peersMatch tells whether a rule's peers include the pod. Rules without
+// peers match every pod.
+func (p NetworkPolicyRules) peersMatch(peers []networkingv1.NetworkPolicyPeer, pod PolicyPeer) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, peer := range peers {
+		if peer.IPBlock != nil {
+			if ipBlockMatches(peer.IPBlock, pod.IP) {
+				return true
+			}
+			continue
+		}
+		if peer.NamespaceSelector == nil {
+			// only pods in the policy's namespace
+			if pod.Namespace != p.Namespace {
+				continue
+			}
+		} else if !selectorMatches(peer.NamespaceSelector, pod.NamespaceLabels) {
+			continue
+		}
+		if peer.PodSelector == nil || selectorMatches(peer.PodSelector, pod.Labels) {
+			return true
+		}
+	}
+	return false
+}
+
+func portsMatch(ports []networkingv1.NetworkPolicyPort, port int, protocol string) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, p := range ports {
+		want := "TCP"
+		if p.Protocol != nil {
+			want = string(*p.Protocol)
+		}
+		if protocol != "" && !strings.EqualFold(want, protocol) {
+			continue
+		}
+		if port == AnyPort || p.Port == nil || p.Port.Type == intstr.String || p.Port.IntValue() == port {
+			return true
+		}
+	}
+	return false
+}
+
+func ipBlockMatches(block *networkingv1.IPBlock, ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	if _, cidr, err := net.ParseCIDR(block.CIDR); err != nil || !cidr.Contains(addr) {
+		return false
+	}
+	for _, except := range block.Except {
+		if _, cidr, err := net.ParseCIDR(except); err == nil && cidr.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+func selectorMatches(selector *metav1.LabelSelector, set map[string]string) bool {
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(labels.Set(set))
+}
@@ -0,0 +1,129 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/weaveworks/scope/report"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// These constants are keys used in node metadata
+const (
+	NodeReady         = report.KubernetesNodeReady
+	NodeConditions    = report.KubernetesNodeConditions
+	Taints            = report.KubernetesTaints
+	AllocatableCPU    = report.KubernetesAllocatableCPU
+	AllocatableMemory = report.KubernetesAllocatableMemory
+	AllocatablePods   = report.KubernetesAllocatablePods
+	KubeletVersion    = report.KubernetesKubeletVersion
+	Unschedulable     = report.KubernetesUnschedulable
+)
+
+// NodeHostKeys are the keys of the Kubernetes node metadata which is
+// merged into the host the node runs on.
+var NodeHostKeys = []string{
+	NodeReady,
+	NodeConditions,
+	Taints,
+	AllocatableCPU,
+	AllocatableMemory,
+	AllocatablePods,
+	KubeletVersion,
+	Unschedulable,
+}
+
+// Node represents a Kubernetes node
+type Node interface {
+	Meta
+	GetNode(probeID string) report.Node
+	Unschedulable() bool
+	CPUCapacity() string
+}
+
+type node struct {
+	*apiv1.Node
+	Meta
+}
+
+// NewNode creates a new Node
+func NewNode(n *apiv1.Node) Node {
+	return &node{Node: n, Meta: meta{n.ObjectMeta}}
+}
+
+func (n *node) Unschedulable() bool {
+	return n.Spec.Unschedulable
+}
+
+// CPUCapacity returns the number of CPUs of the node, if known
+func (n *node) CPUCapacity() string {
+	if cpu, ok := n.Status.Capacity[apiv1.ResourceCPU]; ok {
+		return cpu.String()
+	}
+	return ""
+}
+
+// conditions returns the status of the node's Ready condition, and the other
+// conditions which currently hold, such as MemoryPressure.
+func (n *node) conditions() (string, []string) {
+	ready := string(apiv1.ConditionUnknown)
+	conditions := []string{}
+	for _, c := range n.Status.Conditions {
+		if c.Type == apiv1.NodeReady {
+			ready = string(c.Status)
+			if c.Status != apiv1.ConditionTrue {
+				conditions = append(conditions, "NotReady")
+			}
+			continue
+		}
+		if c.Status == apiv1.ConditionTrue {
+			conditions = append(conditions, string(c.Type))
+		}
+	}
+	return ready, conditions
+}
+
+// human-readable version of a Kubernetes Taint
+func taintString(t apiv1.Taint) string {
+	if t.Value == "" {
+		return fmt.Sprintf("%s:%s", t.Key, t.Effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", t.Key, t.Value, t.Effect)
+}
+
+func (n *node) GetNode(probeID string) report.Node {
+	ready, conditions := n.conditions()
+	taints := make([]string, 0, len(n.Spec.Taints))
+	for _, t := range n.Spec.Taints {
+		taints = append(taints, taintString(t))
+	}
+	latest := map[string]string{
+		NodeType:              "Node",
+		NodeReady:             ready,
+		NodeConditions:        strings.Join(conditions, ","),
+		Taints:                strings.Join(taints, ","),
+		KubeletVersion:        n.Status.NodeInfo.KubeletVersion,
+		Unschedulable:         strconv.FormatBool(n.Spec.Unschedulable),
+		report.ControlProbeID: probeID,
+	}
+	if cpu, ok := n.Status.Allocatable[apiv1.ResourceCPU]; ok {
+		latest[AllocatableCPU] = cpu.String()
+	}
+	if memory, ok := n.Status.Allocatable[apiv1.ResourceMemory]; ok {
+		latest[AllocatableMemory] = memory.String()
+	}
+	if pods, ok := n.Status.Allocatable[apiv1.ResourcePods]; ok {
+		latest[AllocatablePods] = pods.String()
+	}
+	controls := []string{DrainNode, Describe}
+	if n.Spec.Unschedulable {
+		controls = append(controls, UncordonNode)
+	} else {
+		controls = append(controls, CordonNode)
+	}
+	return n.MetaNode(report.MakeKubernetesNodeNodeID(n.UID())).
+		WithLatests(latest).
+		WithLatestActiveControls(controls...)
+}
@@ -0,0 +1,90 @@
+package kubernetes
+
+import (
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/weaveworks/scope/report"
+)
+
+// controllerTopologies are the topologies of the controllers of pods
+// which are reported in their own topologies, by kind. Pods are matched
+// with them by selector.
+var controllerTopologies = map[string]string{
+	"Deployment":  report.Deployment,
+	"DaemonSet":   report.DaemonSet,
+	"StatefulSet": report.StatefulSet,
+	"CronJob":     report.CronJob,
+	"Job":         report.Job,
+}
+
+// maxOwnerDepth bounds how many owner references are followed up from a
+// pod, in case of cycles.
+const maxOwnerDepth = 8
+
+// controllerRef returns the reference to the controller among owner
+// references, or the first owner if none is marked as the controller.
+func controllerRef(refs []metav1.OwnerReference) (metav1.OwnerReference, bool) {
+	for _, ref := range refs {
+		if ref.Controller != nil && *ref.Controller {
+			return ref, true
+		}
+	}
+	if len(refs) > 0 {
+		return refs[0], true
+	}
+	return metav1.OwnerReference{}, false
+}
+
+// genericControllerOf follows the owner references of a pod up to its
+// topmost owner, which is the generic controller of the pod. Pods whose
+// owners lead to a controller with a topology of its own, such as the
+// ReplicaSets of a Deployment, have no generic controller. Owners are
+// looked up in the background, so pods only get their generic controller
+// in the reports after the lookup.
+func (r *Reporter) genericControllerOf(p Pod) (CustomResource, bool) {
+	var (
+		refs  = p.GetOwnerReferences()
+		owner CustomResource
+	)
+	for depth := 0; depth < maxOwnerDepth; depth++ {
+		ref, ok := controllerRef(refs)
+		if !ok {
+			break
+		}
+		if _, ok := controllerTopologies[ref.Kind]; ok {
+			return nil, false
+		}
+		o, err := r.client.GetOwner(p.Namespace(), ref)
+		if err == errOwnerPending {
+			// Rather than report an owner halfway up
+			return nil, false
+		} else if err != nil {
+			log.Debugf("Kubernetes: cannot get %s %s/%s owning pod %s: %v", ref.Kind, p.Namespace(), ref.Name, p.Name(), err)
+			break
+		}
+		owner, refs = o, o.OwnerReferences()
+	}
+	return owner, owner != nil
+}
+
+// GetGenericControllerNode returns the owner of pods as a generic
+// controller node
+func GetGenericControllerNode(owner CustomResource, probeID string) report.Node {
+	return owner.MetaNode(report.MakeGenericControllerNodeID(owner.UID())).WithLatests(map[string]string{
+		NodeType:              owner.Kind(),
+		APIVersion:            owner.APIVersion(),
+		report.ControlProbeID: probeID,
+	})
+}
+
+// hasControllerParent tells whether a pod node was matched with a
+// controller reported in a topology of its own.
+func hasControllerParent(n report.Node) bool {
+	for _, topology := range controllerTopologies {
+		if _, ok := n.Parents.Lookup(topology); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -10,6 +10,13 @@ import (
 const (
 	casTypeLabel  = "openebs.io/cas-type"
 	bdcAnnotation = "local.openebs.io/blockdeviceclaim"
+	hostnameLabel = "kubernetes.io/hostname"
+)
+
+// CAS types of OpenEBS LocalPV volumes
+const (
+	LocalHostpathCASType = "local-hostpath"
+	LocalDeviceCASType   = "local-device"
 )
 
 // PersistentVolume represent kubernetes PersistentVolume interface
@@ -18,9 +25,12 @@ type PersistentVolume interface {
 	GetNode(probeID string) report.Node
 	GetAccessMode() string
 	GetVolume() string
+	GetVolumeNamespace() string
 	GetStorageDriver() string
 	GetCASType() string
 	GetBDCName() string
+	GetLocalPath() string
+	GetHostName() string
 }
 
 // persistentVolume represents kubernetes persistent volume
@@ -55,6 +65,14 @@ func (p *persistentVolume) GetVolume() string {
 	return volume
 }
 
+// GetVolumeNamespace returns the namespace of the claim bound to the volume
+func (p *persistentVolume) GetVolumeNamespace() string {
+	if p.Spec.ClaimRef != nil {
+		return p.Spec.ClaimRef.Namespace
+	}
+	return ""
+}
+
 // GetStorageDriver returns the backing driver of Persistent Volume
 func (p *persistentVolume) GetStorageDriver() string {
 	persistentVolumeSource := reflect.ValueOf(p.Spec.PersistentVolumeSource)
@@ -72,8 +90,8 @@ func (p *persistentVolume) GetStorageDriver() string {
 
 func (p *persistentVolume) GetCASType() string {
 	casType := p.GetLabels()[casTypeLabel]
-	if casType == "local-device" {
-		return "local-device"
+	if casType == LocalDeviceCASType || casType == LocalHostpathCASType {
+		return casType
 	}
 	return ""
 }
@@ -82,6 +100,33 @@ func (p *persistentVolume) GetBDCName() string {
 	return p.GetAnnotations()[bdcAnnotation]
 }
 
+// GetLocalPath returns the path on its host of a local volume
+func (p *persistentVolume) GetLocalPath() string {
+	switch {
+	case p.Spec.Local != nil:
+		return p.Spec.Local.Path
+	case p.Spec.HostPath != nil:
+		return p.Spec.HostPath.Path
+	}
+	return ""
+}
+
+// GetHostName returns the host a local volume is pinned to by its node
+// affinity, if it is pinned to a single one.
+func (p *persistentVolume) GetHostName() string {
+	if p.Spec.NodeAffinity == nil || p.Spec.NodeAffinity.Required == nil {
+		return ""
+	}
+	for _, term := range p.Spec.NodeAffinity.Required.NodeSelectorTerms {
+		for _, e := range term.MatchExpressions {
+			if e.Key == hostnameLabel && e.Operator == apiv1.NodeSelectorOpIn && len(e.Values) == 1 {
+				return e.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
 // GetNode returns Persistent Volume as Node
 func (p *persistentVolume) GetNode(probeID string) report.Node {
 	latests := map[string]string{
@@ -105,6 +150,14 @@ func (p *persistentVolume) GetNode(probeID string) report.Node {
 		latests[BlockDeviceClaimName] = p.GetBDCName()
 	}
 
+	if p.GetLocalPath() != "" {
+		latests[LocalPath] = p.GetLocalPath()
+	}
+
+	if p.GetHostName() != "" {
+		latests[HostName] = p.GetHostName()
+	}
+
 	return p.MetaNode(report.MakePersistentVolumeNodeID(p.UID())).
 		WithLatests(latests).
 		WithLatestActiveControls(Describe)
@@ -23,6 +23,7 @@ type PersistentVolumeClaim interface {
 	GetStorageClass() string
 	GetCapacity() string
 	GetVolumeSnapshot() string
+	GetResizeStatus() string
 }
 
 // persistentVolumeClaim represents kubernetes Persistent Volume Claims
@@ -73,6 +74,23 @@ func (p *persistentVolumeClaim) GetVolumeSnapshot() string {
 	return ""
 }
 
+// GetResizeStatus returns the progress of the expansion of the volume, if
+// one is under way
+func (p *persistentVolumeClaim) GetResizeStatus() string {
+	for _, c := range p.Status.Conditions {
+		if c.Status != apiv1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case apiv1.PersistentVolumeClaimResizing:
+			return "Resizing"
+		case apiv1.PersistentVolumeClaimFileSystemResizePending:
+			return "File system resize pending"
+		}
+	}
+	return ""
+}
+
 // GetNode returns Persistent Volume Claim as Node
 func (p *persistentVolumeClaim) GetNode(probeID string) report.Node {
 	latests := map[string]string{
@@ -91,9 +109,13 @@ func (p *persistentVolumeClaim) GetNode(probeID string) report.Node {
 		latests[VolumeSnapshotName] = p.GetVolumeSnapshot()
 	}
 
+	if p.GetResizeStatus() != "" {
+		latests[ResizeStatus] = p.GetResizeStatus()
+	}
+
 	return p.MetaNode(report.MakePersistentVolumeClaimNodeID(p.UID())).
 		WithLatests(latests).
-		WithLatestActiveControls(CreateVolumeSnapshot, Describe)
+		WithLatestActiveControls(CreateVolumeSnapshot, ExpandVolumeClaim, DeleteVolumeClaim, Describe)
 }
 
 // Selector returns all Persistent Volume Claim selector
@@ -7,6 +7,8 @@ import (
 	"github.com/weaveworks/scope/report"
 
 	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // These constants are keys used in node metadata
@@ -14,6 +16,11 @@ const (
 	State           = report.KubernetesState
 	IsInHostNetwork = report.KubernetesIsInHostNetwork
 	RestartCount    = report.KubernetesRestartCount
+	CPURequest      = report.KubernetesCPURequest
+	CPULimit        = report.KubernetesCPULimit
+	MemoryRequest   = report.KubernetesMemoryRequest
+	MemoryLimit     = report.KubernetesMemoryLimit
+	NodeCPUCapacity = report.KubernetesNodeCPUCapacity
 )
 
 // Pod labels to get pv name, if it is a controller/target or replica pod
@@ -31,10 +38,23 @@ const (
 	JivaReplicaValue = "jiva-replica"
 )
 
+// Pod label to distinguish jiva controller pod
+const (
+	ControllerPodLabel  = "openebs.io/controller"
+	JivaControllerValue = "jiva-controller"
+)
+
+// Roles of the pods making up a Jiva volume
+const (
+	JivaControllerRole = "controller"
+	JivaReplicaRole    = "replica"
+)
+
 // Pod represents a Kubernetes pod
 type Pod interface {
 	Meta
 	AddParent(topology, id string)
+	GetOwnerReferences() []metav1.OwnerReference
 	NodeName() string
 	GetNode(probeID string) report.Node
 	RestartCount() uint
@@ -42,6 +62,10 @@ type Pod interface {
 	VolumeClaimNames() []string
 	GetVolumeName() string
 	IsReplicaOrPoolPod() bool
+	PodIP() string
+	JivaRole() string
+	JivaVolumeName() string
+	HostPath() string
 }
 
 type pod struct {
@@ -97,6 +121,46 @@ func (p *pod) IsReplicaOrPoolPod() bool {
 	return false
 }
 
+func (p *pod) PodIP() string {
+	return p.Status.PodIP
+}
+
+// JivaRole tells whether the pod is the controller or a replica of a
+// Jiva volume, if it is part of one.
+func (p *pod) JivaRole() string {
+	switch {
+	case p.GetLabels()[ControllerPodLabel] == JivaControllerValue:
+		return JivaControllerRole
+	case p.GetLabels()[ReplicaPodLabel] == JivaReplicaValue:
+		return JivaReplicaRole
+	}
+	return ""
+}
+
+// JivaVolumeName returns the name of the persistent volume of the Jiva
+// volume the pod is part of. Replica pods carry the same labels as the
+// controller, so GetVolumeName can't be used for them.
+func (p *pod) JivaVolumeName() string {
+	if p.JivaRole() == "" {
+		return ""
+	}
+	if volumeName, ok := p.GetLabels()[PersistentVolumeLabel]; ok {
+		return volumeName
+	}
+	return p.GetLabels()[VSMLabel]
+}
+
+// HostPath returns the path of the first host directory mounted in the
+// pod, which is where a Jiva replica keeps its data.
+func (p *pod) HostPath() string {
+	for _, volume := range p.Spec.Volumes {
+		if volume.VolumeSource.HostPath != nil {
+			return volume.VolumeSource.HostPath.Path
+		}
+	}
+	return ""
+}
+
 func (p *pod) GetVolumeName() string {
 	if strings.Contains(p.GetName(), "-rep-") {
 		return ""
@@ -144,6 +208,10 @@ func (p *pod) GetNode(probeID string) report.Node {
 		latests[IsInHostNetwork] = "true"
 	}
 
+	for key, quantity := range p.resources() {
+		latests[key] = quantity
+	}
+
 	if p.GetVolumeName() != "" {
 		latests[VolumeName] = p.GetVolumeName()
 		latests[VolumePod] = "true"
@@ -153,11 +221,56 @@ func (p *pod) GetNode(probeID string) report.Node {
 		latests[VolumePod] = "true"
 	}
 
+	switch p.JivaRole() {
+	case JivaControllerRole:
+		latests[JivaRole] = JivaControllerRole
+	case JivaReplicaRole:
+		latests[JivaRole] = JivaReplicaRole
+		latests[report.KubernetesNodeName] = p.NodeName()
+		if hostPath := p.HostPath(); hostPath != "" {
+			latests[JivaHostPath] = hostPath
+		}
+	}
+
 	return p.MetaNode(report.MakePodNodeID(p.UID())).WithLatests(latests).
 		WithParents(p.parents).
 		WithLatestActiveControls(GetLogs, DeletePod, Describe)
 }
 
+// resources returns the CPU and memory requests and limits of the pod, as
+// the sums of those of its containers. The pod only has a limit when all
+// its containers have one.
+func (p *pod) resources() map[string]string {
+	result := map[string]string{}
+	for _, r := range []struct {
+		name           apiv1.ResourceName
+		request, limit string
+	}{
+		{apiv1.ResourceCPU, CPURequest, CPULimit},
+		{apiv1.ResourceMemory, MemoryRequest, MemoryLimit},
+	} {
+		var request, limit resource.Quantity
+		limited := len(p.Pod.Spec.Containers) > 0
+		for _, c := range p.Pod.Spec.Containers {
+			if q, ok := c.Resources.Requests[r.name]; ok {
+				request.Add(q)
+			}
+			if q, ok := c.Resources.Limits[r.name]; ok {
+				limit.Add(q)
+			} else {
+				limited = false
+			}
+		}
+		if !request.IsZero() {
+			result[r.request] = request.String()
+		}
+		if limited {
+			result[r.limit] = limit.String()
+		}
+	}
+	return result
+}
+
 func (p *pod) ContainerNames() []string {
 	containerNames := make([]string, 0, len(p.Pod.Spec.Containers))
 	for _, c := range p.Pod.Spec.Containers {
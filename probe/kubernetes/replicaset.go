@@ -0,0 +1,95 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/weaveworks/scope/report"
+
+	apiappsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// These constants are keys used in node metadata
+const (
+	RolloutHistoryPrefix = report.KubernetesRolloutHistoryPrefix
+)
+
+// Annotations of Deployments and their ReplicaSets
+const (
+	revisionAnnotation    = "deployment.kubernetes.io/revision"
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+)
+
+// ReplicaSet represents a Kubernetes ReplicaSet. ReplicaSets aren't nodes
+// of their own: those of Deployments make up their rollout history.
+type ReplicaSet interface {
+	Meta
+	Revision() int64
+	ControllerUID() string
+	Row() report.Row
+}
+
+type replicaSet struct {
+	*apiappsv1.ReplicaSet
+	Meta
+}
+
+// NewReplicaSet creates a new ReplicaSet
+func NewReplicaSet(r *apiappsv1.ReplicaSet) ReplicaSet {
+	return &replicaSet{ReplicaSet: r, Meta: meta{r.ObjectMeta}}
+}
+
+// Revision returns the revision of the Deployment the ReplicaSet stands
+// for, or 0 if unknown.
+func (r *replicaSet) Revision() int64 {
+	return revision(r.ObjectMeta)
+}
+
+// ControllerUID returns the UID of the controller of the ReplicaSet, if any
+func (r *replicaSet) ControllerUID() string {
+	if ref := metav1.GetControllerOf(r); ref != nil {
+		return string(ref.UID)
+	}
+	return ""
+}
+
+// Row returns the ReplicaSet as a row of the rollout history of its
+// Deployment.
+func (r *replicaSet) Row() report.Row {
+	images := make([]string, 0, len(r.Spec.Template.Spec.Containers))
+	for _, c := range r.Spec.Template.Spec.Containers {
+		images = append(images, c.Image)
+	}
+	desired := int32(1)
+	if r.Spec.Replicas != nil {
+		desired = *r.Spec.Replicas
+	}
+	return report.Row{
+		ID: fmt.Sprintf("revision%06d", r.Revision()),
+		Entries: map[string]string{
+			"revision":     strconv.FormatInt(r.Revision(), 10),
+			"replica_set":  r.Name(),
+			"created":      r.Created(),
+			"images":       strings.Join(images, ", "),
+			"replicas":     strconv.Itoa(int(r.Status.ReadyReplicas)) + "/" + strconv.Itoa(int(desired)),
+			"change_cause": r.Annotations[changeCauseAnnotation],
+		},
+	}
+}
+
+func revision(m metav1.ObjectMeta) int64 {
+	v, err := strconv.ParseInt(m.Annotations[revisionAnnotation], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// restartedAtPatch is the patch to the pod template of a controller which
+// restarts its rollout, as kubectl rollout restart does.
+func restartedAtPatch(now time.Time) []byte {
+	return []byte(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":"` + now.Format(time.RFC3339) + `"}}}}}`)
+}
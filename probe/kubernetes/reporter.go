@@ -2,7 +2,9 @@ package kubernetes
 
 import (
 	"fmt"
+	"sort"
 
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
 	log "github.com/sirupsen/logrus"
@@ -86,6 +88,8 @@ const (
 	CStorPoolInstanceUID         = report.KubernetesCStorPoolInstanceUID
 	Driver                       = report.KubernetesDriver
 	DeletionPolicy               = report.KubernetesDeletionPolicy
+	ResizeStatus                 = report.KubernetesResizeStatus
+	VolumeExpansion              = report.KubernetesVolumeExpansion
 )
 
 var (
@@ -104,6 +108,13 @@ var (
 	}
 )
 
+// Bounds and formats of the params of controls
+var (
+	minReplicas     = int64(0)
+	minRevision     = int64(1)
+	quantityPattern = `^[0-9]+(\.[0-9]+)?([KMGTPE]i?|k)?$`
+)
+
 // Exposed for testing
 var (
 	PodMetadataTemplates = report.MetadataTemplates{
@@ -113,6 +124,12 @@ var (
 		Namespace:        {ID: Namespace, Label: "Namespace", From: report.FromLatest, Priority: 5},
 		Created:          {ID: Created, Label: "Created", From: report.FromLatest, Datatype: report.DateTime, Priority: 6},
 		RestartCount:     {ID: RestartCount, Label: "Restart #", From: report.FromLatest, Priority: 7},
+		JivaRole:         {ID: JivaRole, Label: "Jiva role", From: report.FromLatest, Priority: 8},
+		JivaHostPath:     {ID: JivaHostPath, Label: "Host path", From: report.FromLatest, Priority: 9},
+		CPURequest:       {ID: CPURequest, Label: "CPU request", From: report.FromLatest, Priority: 10},
+		CPULimit:         {ID: CPULimit, Label: "CPU limit", From: report.FromLatest, Priority: 11},
+		MemoryRequest:    {ID: MemoryRequest, Label: "Memory request", From: report.FromLatest, Priority: 12},
+		MemoryLimit:      {ID: MemoryLimit, Label: "Memory limit", From: report.FromLatest, Priority: 13},
 	}
 
 	PodMetricTemplates = docker.ContainerMetricTemplates
@@ -125,6 +142,9 @@ var (
 		report.Pod: {ID: report.Pod, Label: "# Pods", From: report.FromCounters, Datatype: report.Number, Priority: 6},
 		Type:       {ID: Type, Label: "Type", From: report.FromLatest, Priority: 7},
 		Ports:      {ID: Ports, Label: "Ports", From: report.FromLatest, Priority: 8},
+
+		ReadyEndpoints:    {ID: ReadyEndpoints, Label: "Ready endpoints", From: report.FromLatest, Datatype: report.Number, Priority: 9},
+		NotReadyEndpoints: {ID: NotReadyEndpoints, Label: "Not ready endpoints", From: report.FromLatest, Datatype: report.Number, Priority: 10},
 	}
 
 	ServiceMetricTemplates = PodMetricTemplates
@@ -182,6 +202,9 @@ var (
 		AccessModes:      {ID: AccessModes, Label: "Access modes", From: report.FromLatest, Priority: 5},
 		Status:           {ID: Status, Label: "Status", From: report.FromLatest, Priority: 6},
 		StorageDriver:    {ID: StorageDriver, Label: "Storage driver", From: report.FromLatest, Priority: 7},
+		CASType:          {ID: CASType, Label: "CAS type", From: report.FromLatest, Priority: 8},
+		HostName:         {ID: HostName, Label: "Host", From: report.FromLatest, Priority: 9},
+		LocalPath:        {ID: LocalPath, Label: "Local path", From: report.FromLatest, Priority: 10},
 	}
 
 	PersistentVolumeClaimMetadataTemplates = report.MetadataTemplates{
@@ -191,11 +214,24 @@ var (
 		VolumeName:       {ID: VolumeName, Label: "Volume", From: report.FromLatest, Priority: 4},
 		StorageClassName: {ID: StorageClassName, Label: "Storage class", From: report.FromLatest, Priority: 5},
 		VolumeCapacity:   {ID: VolumeCapacity, Label: "Capacity", From: report.FromLatest, Priority: 6},
+		ResizeStatus:     {ID: ResizeStatus, Label: "Resize", From: report.FromLatest, Priority: 7},
 	}
 
+	VolumeMetricTemplates = report.MetricTemplates{
+		VolumeUsedBytes:      {ID: VolumeUsedBytes, Label: "Used", Format: report.FilesizeFormat, Priority: 1},
+		VolumeAvailableBytes: {ID: VolumeAvailableBytes, Label: "Available", Format: report.FilesizeFormat, Priority: 2},
+		VolumeInodesUsed:     {ID: VolumeInodesUsed, Label: "Inodes used", Format: report.DefaultFormat, Group: "inodes", Priority: 3},
+		VolumeInodesFree:     {ID: VolumeInodesFree, Label: "Inodes free", Format: report.DefaultFormat, Group: "inodes", Priority: 4},
+	}
+
+	PersistentVolumeMetricTemplates = VolumeMetricTemplates
+
+	PersistentVolumeClaimMetricTemplates = VolumeMetricTemplates
+
 	StorageClassMetadataTemplates = report.MetadataTemplates{
-		NodeType:    {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
-		Provisioner: {ID: Provisioner, Label: "Provisioner", From: report.FromLatest, Priority: 2},
+		NodeType:        {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
+		Provisioner:     {ID: Provisioner, Label: "Provisioner", From: report.FromLatest, Priority: 2},
+		VolumeExpansion: {ID: VolumeExpansion, Label: "Volume expansion", From: report.FromLatest, Priority: 3},
 	}
 
 	VolumeSnapshotMetadataTemplates = report.MetadataTemplates{
@@ -251,6 +287,10 @@ var (
 		LogicalSectorSize: {ID: LogicalSectorSize, Label: "Logical Sector Size", From: report.FromLatest, Priority: 6},
 	}
 
+	BlockDeviceMetricTemplates = report.MetricTemplates{
+		DeviceCapacityBytes: {ID: DeviceCapacityBytes, Label: "Capacity", Format: report.FilesizeFormat, Priority: 1},
+	}
+
 	StoragePoolClaimMetadataTemplates = report.MetadataTemplates{
 		NodeType:   {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
 		APIVersion: {ID: APIVersion, Label: "API Version", From: report.FromLatest, Priority: 2},
@@ -278,6 +318,11 @@ var (
 		Status:     {ID: Status, Label: "Status", From: report.FromLatest, Priority: 3},
 	}
 
+	CStorPoolMetricTemplates = report.MetricTemplates{
+		PoolUsedBytes: {ID: PoolUsedBytes, Label: "Used", Format: report.FilesizeFormat, Priority: 1},
+		PoolFreeBytes: {ID: PoolFreeBytes, Label: "Free", Format: report.FilesizeFormat, Priority: 2},
+	}
+
 	BlockDeviceClaimMetadataTemplates = report.MetadataTemplates{
 		NodeType:        {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
 		BlockDeviceName: {ID: BlockDeviceName, Label: "Block device name", From: report.FromLatest, Priority: 2},
@@ -304,6 +349,8 @@ var (
 		HealthyReplicas:      {ID: HealthyReplicas, Label: "Healthy Replicas", From: report.FromLatest, Priority: 9},
 	}
 
+	CStorPoolInstanceMetricTemplates = CStorPoolMetricTemplates
+
 	CsiVolumeSnapshotMetadataTemplates = report.MetadataTemplates{
 		NodeType:      {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
 		Namespace:     {ID: Namespace, Label: "Name", From: report.FromLatest, Priority: 2},
@@ -324,6 +371,175 @@ var (
 		VolumeSnapshotNamespace: {ID: VolumeSnapshotNamespace, Label: "Volume snapshot namespace", From: report.FromLatest, Priority: 3},
 	}
 
+	NetworkPolicyMetadataTemplates = report.MetadataTemplates{
+		NodeType:     {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
+		Namespace:    {ID: Namespace, Label: "Namespace", From: report.FromLatest, Priority: 2},
+		Created:      {ID: Created, Label: "Created", From: report.FromLatest, Datatype: report.DateTime, Priority: 3},
+		PodSelector:  {ID: PodSelector, Label: "Pod selector", From: report.FromLatest, Priority: 4},
+		PolicyTypes:  {ID: PolicyTypes, Label: "Policy types", From: report.FromLatest, Priority: 5},
+		IngressRules: {ID: IngressRules, Label: "Ingress rules", From: report.FromLatest, Datatype: report.Number, Priority: 6},
+		EgressRules:  {ID: EgressRules, Label: "Egress rules", From: report.FromLatest, Datatype: report.Number, Priority: 7},
+		report.Pod:   {ID: report.Pod, Label: "# Pods", From: report.FromCounters, Datatype: report.Number, Priority: 8},
+	}
+
+	IngressMetadataTemplates = report.MetadataTemplates{
+		NodeType:     {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
+		Namespace:    {ID: Namespace, Label: "Namespace", From: report.FromLatest, Priority: 2},
+		Created:      {ID: Created, Label: "Created", From: report.FromLatest, Datatype: report.DateTime, Priority: 3},
+		IngressClass: {ID: IngressClass, Label: "Class", From: report.FromLatest, Priority: 4},
+		Hosts:        {ID: Hosts, Label: "Hosts", From: report.FromLatest, Priority: 5},
+		TLSHosts:     {ID: TLSHosts, Label: "TLS hosts", From: report.FromLatest, Priority: 6},
+		PublicIP:     {ID: PublicIP, Label: "Address", From: report.FromLatest, Priority: 7},
+	}
+
+	IngressTableTemplates = report.TableTemplates{
+		IngressRulesPrefix: {
+			ID:     IngressRulesPrefix,
+			Label:  "Rules",
+			Type:   report.MulticolumnTableType,
+			Prefix: IngressRulesPrefix,
+			Columns: []report.Column{
+				{ID: "host", Label: "Host"},
+				{ID: "path", Label: "Path"},
+				{ID: "backend", Label: "Backend"},
+			},
+		},
+	}
+
+	CSIDriverMetadataTemplates = report.MetadataTemplates{
+		NodeType:             {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
+		Created:              {ID: Created, Label: "Created", From: report.FromLatest, Datatype: report.DateTime, Priority: 2},
+		report.KubernetesNode: {ID: report.KubernetesNode, Label: "# Nodes", From: report.FromCounters, Datatype: report.Number, Priority: 3},
+		SnapshotSupport:      {ID: SnapshotSupport, Label: "Snapshot support", From: report.FromLatest, Priority: 4},
+		AttachRequired:       {ID: AttachRequired, Label: "Attach required", From: report.FromLatest, Priority: 5},
+		VolumeLifecycleModes: {ID: VolumeLifecycleModes, Label: "Volume lifecycle modes", From: report.FromLatest, Priority: 6},
+	}
+
+	CStorVolumeConfigMetadataTemplates = report.MetadataTemplates{
+		NodeType:           {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
+		Status:             {ID: Status, Label: "Status", From: report.FromLatest, Priority: 2},
+		VolumeCapacity:     {ID: VolumeCapacity, Label: "Capacity", From: report.FromLatest, Priority: 3},
+		Replicas:           {ID: Replicas, Label: "Replicas", From: report.FromLatest, Datatype: report.Number, Priority: 4},
+		CStorPoolInstances: {ID: CStorPoolInstances, Label: "Pool instances", From: report.FromLatest, Priority: 5},
+		PublishedNode:      {ID: PublishedNode, Label: "Attached to", From: report.FromLatest, Priority: 6},
+	}
+
+	CStorVolumeAttachmentMetadataTemplates = report.MetadataTemplates{
+		NodeType:       {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
+		Status:         {ID: Status, Label: "Status", From: report.FromLatest, Priority: 2},
+		PublishedNode:  {ID: PublishedNode, Label: "Node", From: report.FromLatest, Priority: 3},
+		VolumeCapacity: {ID: VolumeCapacity, Label: "Capacity", From: report.FromLatest, Priority: 4},
+		DevicePath:     {ID: DevicePath, Label: "Device path", From: report.FromLatest, Priority: 5},
+	}
+
+	GenericControllerMetadataTemplates = report.MetadataTemplates{
+		NodeType:   {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
+		Namespace:  {ID: Namespace, Label: "Namespace", From: report.FromLatest, Priority: 2},
+		APIVersion: {ID: APIVersion, Label: "API Version", From: report.FromLatest, Priority: 3},
+		Created:    {ID: Created, Label: "Created", From: report.FromLatest, Datatype: report.DateTime, Priority: 4},
+	}
+
+	CustomResourceMetadataTemplates = report.MetadataTemplates{
+		NodeType:  {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
+		Namespace: {ID: Namespace, Label: "Namespace", From: report.FromLatest, Priority: 2},
+		Name:      {ID: Name, Label: "Name", From: report.FromLatest, Priority: 3},
+		Status:    {ID: Status, Label: "Status", From: report.FromLatest, Priority: 4},
+		Created:   {ID: Created, Label: "Created", From: report.FromLatest, Datatype: report.DateTime, Priority: 5},
+	}
+
+	JivaVolumeMetadataTemplates = report.MetadataTemplates{
+		NodeType:               {ID: NodeType, Label: "Type", From: report.FromLatest, Priority: 1},
+		Namespace:              {ID: Namespace, Label: "Namespace", From: report.FromLatest, Priority: 2},
+		JivaController:         {ID: JivaController, Label: "Controller", From: report.FromLatest, Priority: 3},
+		Status:                 {ID: Status, Label: "Status", From: report.FromLatest, Priority: 4},
+		ProvisionedReplicas:    {ID: ProvisionedReplicas, Label: "Replicas", From: report.FromLatest, Datatype: report.Number, Priority: 5},
+		HealthyReplicas:        {ID: HealthyReplicas, Label: "Healthy replicas", From: report.FromLatest, Datatype: report.Number, Priority: 6},
+		JivaRebuildingReplicas: {ID: JivaRebuildingReplicas, Label: "Rebuilding replicas", From: report.FromLatest, Datatype: report.Number, Priority: 7},
+	}
+
+	JivaVolumeTableTemplates = report.TableTemplates{
+		JivaReplicasPrefix: {
+			ID:     JivaReplicasPrefix,
+			Label:  "Replicas",
+			Type:   report.MulticolumnTableType,
+			Prefix: JivaReplicasPrefix,
+			Columns: []report.Column{
+				{ID: "pod", Label: "Pod"},
+				{ID: "node", Label: "Node"},
+				{ID: "host_path", Label: "Host path"},
+				{ID: "address", Label: "Address", DataType: report.IP},
+				{ID: "mode", Label: "Mode"},
+				{ID: "rebuilding", Label: "Rebuilding"},
+			},
+		},
+	}
+
+	NodeMetadataTemplates = report.MetadataTemplates{
+		NodeReady:         {ID: NodeReady, Label: "Ready", From: report.FromLatest, Priority: 16},
+		NodeConditions:    {ID: NodeConditions, Label: "Conditions", From: report.FromLatest, Priority: 17},
+		Unschedulable:     {ID: Unschedulable, Label: "Cordoned", From: report.FromLatest, Priority: 18},
+		Taints:            {ID: Taints, Label: "Taints", From: report.FromLatest, Priority: 19},
+		AllocatableCPU:    {ID: AllocatableCPU, Label: "Allocatable CPU", From: report.FromLatest, Priority: 20},
+		AllocatableMemory: {ID: AllocatableMemory, Label: "Allocatable memory", From: report.FromLatest, Priority: 21},
+		AllocatablePods:   {ID: AllocatablePods, Label: "Allocatable pods", From: report.FromLatest, Datatype: report.Number, Priority: 22},
+		KubeletVersion:    {ID: KubeletVersion, Label: "Kubelet version", From: report.FromLatest, Priority: 23},
+	}
+
+	// AutoscalerMetadataTemplates are merged into the topologies of the
+	// controllers HorizontalPodAutoscalers scale
+	AutoscalerMetadataTemplates = report.MetadataTemplates{
+		Autoscaler:  {ID: Autoscaler, Label: "Autoscaler", From: report.FromLatest, Priority: 20},
+		MinReplicas: {ID: MinReplicas, Label: "Min replicas", From: report.FromLatest, Datatype: report.Number, Priority: 21},
+		MaxReplicas: {ID: MaxReplicas, Label: "Max replicas", From: report.FromLatest, Datatype: report.Number, Priority: 22},
+	}
+
+	AutoscalerTableTemplates = report.TableTemplates{
+		AutoscalerMetricsPrefix: {
+			ID:     AutoscalerMetricsPrefix,
+			Label:  "Autoscaler metrics",
+			Type:   report.MulticolumnTableType,
+			Prefix: AutoscalerMetricsPrefix,
+			Columns: []report.Column{
+				{ID: "metric", Label: "Metric"},
+				{ID: "current", Label: "Current"},
+				{ID: "target", Label: "Target"},
+			},
+		},
+	}
+
+	RolloutHistoryTableTemplates = report.TableTemplates{
+		RolloutHistoryPrefix: {
+			ID:     RolloutHistoryPrefix,
+			Label:  "Rollout history",
+			Type:   report.MulticolumnTableType,
+			Prefix: RolloutHistoryPrefix,
+			Columns: []report.Column{
+				{ID: "revision", Label: "Revision", DataType: report.Number},
+				{ID: "replica_set", Label: "Replica set"},
+				{ID: "created", Label: "Created", DataType: report.DateTime},
+				{ID: "images", Label: "Images"},
+				{ID: "replicas", Label: "Ready"},
+				{ID: "change_cause", Label: "Change cause"},
+			},
+		},
+	}
+
+	EventTableTemplates = report.TableTemplates{
+		EventsPrefix: {
+			ID:     EventsPrefix,
+			Label:  "Events",
+			Type:   report.MulticolumnTableType,
+			Prefix: EventsPrefix,
+			Columns: []report.Column{
+				{ID: "last_seen", Label: "Last seen", DataType: report.DateTime},
+				{ID: "type", Label: "Type"},
+				{ID: "reason", Label: "Reason"},
+				{ID: "message", Label: "Message"},
+				{ID: "count", Label: "Count", DataType: report.Number},
+			},
+		},
+	}
+
 	TableTemplates = report.TableTemplates{
 		LabelPrefix: {
 			ID:     LabelPrefix,
@@ -350,6 +566,92 @@ var (
 		},
 	}
 
+	SetReplicasControl = report.Control{
+		ID:       SetReplicas,
+		Human:    "Set replicas",
+		Category: report.AdminControl,
+		Icon:     "fa fa-sliders-h",
+		Rank:     1,
+		Params: []report.ControlParam{
+			{Name: "replicas", Label: "Replicas", Type: report.ControlParamInt, Required: true, Min: &minReplicas},
+		},
+	}
+
+	RestartRolloutControl = report.Control{
+		ID:           RestartRollout,
+		Human:        "Restart",
+		Category:     report.AdminControl,
+		Icon:         "fa fa-redo",
+		Confirmation: "Are you sure you want to restart all the pods of this controller?",
+		Rank:         3,
+	}
+
+	RollbackDeploymentControl = report.Control{
+		ID:           RollbackDeployment,
+		Human:        "Roll back",
+		Category:     report.AdminControl,
+		Icon:         "fa fa-undo",
+		Confirmation: "Are you sure you want to roll this deployment back to its previous revision?",
+		Rank:         4,
+		Params: []report.ControlParam{
+			{Name: "revision", Label: "Revision", Type: report.ControlParamInt, Min: &minRevision},
+		},
+	}
+
+	PersistentVolumeClaimControls = []report.Control{
+		{
+			ID:       CreateVolumeSnapshot,
+			Human:    "Create snapshot",
+			Category: report.AdminControl,
+			Icon:     "fa fa-camera",
+			Rank:     0,
+		},
+		{
+			ID:           ExpandVolumeClaim,
+			Human:        "Expand",
+			Category:     report.AdminControl,
+			Icon:         "fa fa-expand",
+			Confirmation: "Are you sure you want to expand the volume of this persistent volume claim? Volumes cannot be shrunk again.",
+			Rank:         1,
+			Params: []report.ControlParam{
+				{Name: "capacity", Label: "Capacity", Type: report.ControlParamString, Required: true, Pattern: quantityPattern},
+			},
+		},
+		{
+			ID:           DeleteVolumeClaim,
+			Human:        "Delete",
+			Category:     report.AdminControl,
+			Icon:         "far fa-trash-alt",
+			Confirmation: "Are you sure you want to delete this persistent volume claim? Depending on the reclaim policy, its volume and data may be deleted too.",
+			Rank:         3,
+		},
+	}
+
+	NodeControls = []report.Control{
+		{
+			ID:       CordonNode,
+			Human:    "Cordon",
+			Category: report.AdminControl,
+			Icon:     "fa fa-ban",
+			Rank:     3,
+		},
+		{
+			ID:       UncordonNode,
+			Human:    "Uncordon",
+			Category: report.AdminControl,
+			Icon:     "fa fa-check-circle",
+			Rank:     3,
+		},
+		{
+			ID:           DrainNode,
+			Human:        "Drain",
+			Category:     report.AdminControl,
+			Icon:         "fa fa-sign-out",
+			Rank:         4,
+			Confirmation: "Are you sure you want to drain this node? It will be cordoned and its pods evicted, except DaemonSet pods, pods with data on the node and pods no controller manages.",
+		},
+	}
+
 	DescribeControl = report.Control{
 		ID:       Describe,
 		Human:    "Describe",
@@ -369,6 +671,8 @@ type Reporter struct {
 	handlerRegistry *controls.HandlerRegistry
 	nodeName        string
 	kubeletPort     uint
+	customResources []CustomResourceConfig
+	jiva            *jivaPoller
 }
 
 // NewReporter makes a new Reporter
@@ -382,15 +686,24 @@ func NewReporter(client Client, pipes controls.PipeClient, probeID string, hostI
 		handlerRegistry: handlerRegistry,
 		nodeName:        nodeName,
 		kubeletPort:     kubeletPort,
+		jiva:            newJivaPoller(client),
 	}
 	reporter.registerControls()
 	client.WatchPods(reporter.podEvent)
 	return reporter
 }
 
-// Stop unregisters controls.
+// ReportCustomResources makes the reporter report the custom resources
+// configured, each in its own topology. It must be called before the
+// first report.
+func (r *Reporter) ReportCustomResources(configs []CustomResourceConfig) {
+	r.customResources = configs
+}
+
+// Stop unregisters controls and stops polling Jiva controllers.
 func (r *Reporter) Stop() {
 	r.deregisterControls()
+	r.jiva.stop()
 }
 
 // Name of this reporter, for metrics gathering
@@ -491,7 +804,7 @@ func (r *Reporter) Report() (report.Report, error) {
 	if err != nil {
 		return result, err
 	}
-	podTopology, err := r.podTopology(services, deployments, daemonSets, statefulSets, cronJobs, jobs)
+	podTopology, genericControllerTopology, err := r.podTopology(services, deployments, daemonSets, statefulSets, cronJobs, jobs)
 	if err != nil {
 		return result, err
 	}
@@ -503,11 +816,15 @@ func (r *Reporter) Report() (report.Report, error) {
 	if err != nil {
 		return result, err
 	}
-	persistentVolumeClaimTopology, _, err := r.persistentVolumeClaimTopology()
+	storageClassTopology, storageClasses, err := r.storageClassTopology()
+	if err != nil {
+		return result, err
+	}
+	csiDriverTopology, csiDrivers, err := r.csiDriverTopology()
 	if err != nil {
 		return result, err
 	}
-	storageClassTopology, _, err := r.storageClassTopology()
+	persistentVolumeClaimTopology, _, err := r.persistentVolumeClaimTopology(storageClasses, csiDrivers)
 	if err != nil {
 		return result, err
 	}
@@ -570,6 +887,34 @@ func (r *Reporter) Report() (report.Report, error) {
 	if err != nil {
 		return result, nil
 	}
+	networkPolicyTopology, _, err := r.networkPolicyTopology()
+	if err != nil {
+		return result, err
+	}
+	ingressTopology, _, err := r.ingressTopology()
+	if err != nil {
+		return result, err
+	}
+	nodeTopology, _, err := r.nodeTopology()
+	if err != nil {
+		return result, err
+	}
+	jivaVolumeTopology, _, err := r.jivaVolumeTopology()
+	if err != nil {
+		return result, err
+	}
+	cStorVolumeConfigTopology, _, err := r.cStorVolumeConfigTopology()
+	if err != nil {
+		return result, err
+	}
+	cStorVolumeAttachmentTopology, _, err := r.cStorVolumeAttachmentTopology()
+	if err != nil {
+		return result, err
+	}
+	customResourceTopologies, err := r.customResourceTopologies()
+	if err != nil {
+		return result, err
+	}
 	result.Pod = result.Pod.Merge(podTopology)
 	result.Service = result.Service.Merge(serviceTopology)
 	result.DaemonSet = result.DaemonSet.Merge(daemonSetTopology)
@@ -595,6 +940,26 @@ func (r *Reporter) Report() (report.Report, error) {
 	result.CsiVolumeSnapshot = result.CsiVolumeSnapshot.Merge(csiVolumeSnapshotTopology)
 	result.VolumeSnapshotClass = result.VolumeSnapshotClass.Merge(volumeSnapshotClassTopology)
 	result.VolumeSnapshotContent = result.VolumeSnapshotContent.Merge(volumeSnapshotContentTopology)
+	result.NetworkPolicy = result.NetworkPolicy.Merge(networkPolicyTopology)
+	result.Ingress = result.Ingress.Merge(ingressTopology)
+	result.KubernetesNode = result.KubernetesNode.Merge(nodeTopology)
+	result.CSIDriver = result.CSIDriver.Merge(csiDriverTopology)
+	result.JivaVolume = result.JivaVolume.Merge(jivaVolumeTopology)
+	result.CStorVolumeConfig = result.CStorVolumeConfig.Merge(cStorVolumeConfigTopology)
+	result.CStorVolumeAttachment = result.CStorVolumeAttachment.Merge(cStorVolumeAttachmentTopology)
+	result.GenericController = result.GenericController.Merge(genericControllerTopology)
+	for crd, topology := range customResourceTopologies {
+		t := result.CustomResourceTopology(crd)
+		*t = t.Merge(topology)
+	}
+	if err := r.attachHorizontalPodAutoscalers(&result); err != nil {
+		return result, err
+	}
+	if err := r.attachEvents(&result); err != nil {
+		return result, err
+	}
+	// The node metadata is shown on the hosts the nodes run on
+	result.Host = result.Host.WithMetadataTemplates(NodeMetadataTemplates)
 	return result, nil
 }
 
@@ -607,8 +972,19 @@ func (r *Reporter) serviceTopology() (report.Topology, []Service, error) {
 		services = []Service{}
 	)
 	result.Controls.AddControl(DescribeControl)
+	endpoints := map[string]ServiceEndpoints{}
+	if err := r.client.WalkEndpoints(func(e ServiceEndpoints) error {
+		endpoints[e.Namespace+"/"+e.Service] = e
+		return nil
+	}); err != nil {
+		return result, services, err
+	}
 	err := r.client.WalkServices(func(s Service) error {
-		result.AddNode(s.GetNode(r.probeID))
+		node := s.GetNode(r.probeID)
+		if e, ok := endpoints[s.Namespace()+"/"+s.Name()]; ok {
+			node = e.AddToNode(node)
+		}
+		result.AddNode(node)
 		services = append(services, s)
 		return nil
 	})
@@ -624,13 +1000,32 @@ func (r *Reporter) deploymentTopology() (report.Topology, []Deployment, error) {
 		deployments = []Deployment{}
 	)
 	result.Controls.AddControls(ScalingControls)
+	result.Controls.AddControls([]report.Control{SetReplicasControl, RestartRolloutControl, RollbackDeploymentControl})
 	result.Controls.AddControl(DescribeControl)
 
-	err := r.client.WalkDeployments(func(d Deployment) error {
-		result.AddNode(d.GetNode(r.probeID))
+	history := map[string][]report.Row{}
+	err := r.client.WalkReplicaSets(func(rs ReplicaSet) error {
+		if uid := rs.ControllerUID(); uid != "" && rs.Revision() > 0 {
+			history[uid] = append(history[uid], rs.Row())
+		}
+		return nil
+	})
+	if err != nil {
+		return result, deployments, err
+	}
+
+	err = r.client.WalkDeployments(func(d Deployment) error {
+		node := d.GetNode(r.probeID)
+		if rows, ok := history[d.UID()]; ok {
+			node = node.AddPrefixMulticolumnTable(RolloutHistoryPrefix, rows)
+		}
+		result.AddNode(node)
 		deployments = append(deployments, d)
 		return nil
 	})
+	if len(history) > 0 {
+		result.TableTemplates = result.TableTemplates.Merge(RolloutHistoryTableTemplates)
+	}
 	return result, deployments, err
 }
 
@@ -640,6 +1035,7 @@ func (r *Reporter) daemonSetTopology() (report.Topology, []DaemonSet, error) {
 		WithMetadataTemplates(DaemonSetMetadataTemplates).
 		WithMetricTemplates(DaemonSetMetricTemplates).
 		WithTableTemplates(TableTemplates)
+	result.Controls.AddControl(RestartRolloutControl)
 	result.Controls.AddControl(DescribeControl)
 	err := r.client.WalkDaemonSets(func(d DaemonSet) error {
 		result.AddNode(d.GetNode(r.probeID))
@@ -655,6 +1051,7 @@ func (r *Reporter) statefulSetTopology() (report.Topology, []StatefulSet, error)
 		WithMetadataTemplates(StatefulSetMetadataTemplates).
 		WithMetricTemplates(StatefulSetMetricTemplates).
 		WithTableTemplates(TableTemplates)
+	result.Controls.AddControls([]report.Control{SetReplicasControl, RestartRolloutControl})
 	result.Controls.AddControl(DescribeControl)
 	err := r.client.WalkStatefulSets(func(s StatefulSet) error {
 		result.AddNode(s.GetNode(r.probeID))
@@ -683,6 +1080,7 @@ func (r *Reporter) persistentVolumeTopology() (report.Topology, []PersistentVolu
 	persistentVolumes := []PersistentVolume{}
 	result := report.MakeTopology().
 		WithMetadataTemplates(PersistentVolumeMetadataTemplates).
+		WithMetricTemplates(PersistentVolumeMetricTemplates).
 		WithTableTemplates(TableTemplates)
 	result.Controls.AddControl(DescribeControl)
 	err := r.client.WalkPersistentVolumes(func(p PersistentVolume) error {
@@ -693,27 +1091,114 @@ func (r *Reporter) persistentVolumeTopology() (report.Topology, []PersistentVolu
 	return result, persistentVolumes, err
 }
 
-func (r *Reporter) persistentVolumeClaimTopology() (report.Topology, []PersistentVolumeClaim, error) {
+func (r *Reporter) persistentVolumeClaimTopology(storageClasses []StorageClass, csiDrivers map[string]CSIDriverInfo) (report.Topology, []PersistentVolumeClaim, error) {
+	provisioners := map[string]string{}
+	expandable := map[string]bool{}
+	for _, s := range storageClasses {
+		provisioners[s.Name()] = s.GetProvisioner()
+		expandable[s.Name()] = s.AllowsExpansion()
+	}
 	persistentVolumeClaims := []PersistentVolumeClaim{}
 	result := report.MakeTopology().
 		WithMetadataTemplates(PersistentVolumeClaimMetadataTemplates).
+		WithMetricTemplates(PersistentVolumeClaimMetricTemplates).
 		WithTableTemplates(TableTemplates)
-	result.Controls.AddControl(report.Control{
-		ID:       CreateVolumeSnapshot,
-		Human:    "Create snapshot",
-		Category: report.AdminControl,
-		Icon:     "fa fa-camera",
-		Rank:     0,
-	})
+	result.Controls.AddControls(PersistentVolumeClaimControls)
 	result.Controls.AddControl(DescribeControl)
 	err := r.client.WalkPersistentVolumeClaims(func(p PersistentVolumeClaim) error {
-		result.AddNode(p.GetNode(r.probeID))
+		node := p.GetNode(r.probeID)
+		// Volumes of CSI drivers which can't snapshot them can't be
+		// snapshotted either way.
+		if driver, ok := csiDrivers[provisioners[p.GetStorageClass()]]; ok && !driver.SupportsSnapshots() {
+			node = node.WithLatestControls(map[string]report.NodeControlData{
+				CreateVolumeSnapshot: {Dead: true},
+			})
+		}
+		// Only bound claims of storage classes allowing it can be expanded
+		if status, _ := node.Latest.Lookup(Status); !expandable[p.GetStorageClass()] || status != string(apiv1.ClaimBound) {
+			node = node.WithLatestControls(map[string]report.NodeControlData{
+				ExpandVolumeClaim: {Dead: true},
+			})
+		}
+		result.AddNode(node)
 		persistentVolumeClaims = append(persistentVolumeClaims, p)
 		return nil
 	})
 	return result, persistentVolumeClaims, err
 }
 
+// csiDriverTopology reports the CSI drivers of the cluster: those with a
+// CSIDriver object, and those registered on nodes without one.
+func (r *Reporter) csiDriverTopology() (report.Topology, map[string]CSIDriverInfo, error) {
+	drivers := map[string]CSIDriverInfo{}
+	result := report.MakeTopology().
+		WithMetadataTemplates(CSIDriverMetadataTemplates).
+		WithTableTemplates(TableTemplates)
+	result.Controls.AddControl(DescribeControl)
+	err := r.client.WalkCSIDrivers(func(d CSIDriver) error {
+		drivers[d.Name()] = CSIDriverInfo{Name: d.Name(), Driver: d}
+		return nil
+	})
+	if err != nil {
+		return result, drivers, err
+	}
+	err = r.client.WalkCSINodes(func(n CSINode) error {
+		for _, name := range n.Drivers() {
+			info, ok := drivers[name]
+			if !ok {
+				info = CSIDriverInfo{Name: name}
+			}
+			info.Nodes = append(info.Nodes, n.Name())
+			drivers[name] = info
+		}
+		return nil
+	})
+	for _, info := range drivers {
+		result.AddNode(info.GetNode(r.probeID))
+	}
+	return result, drivers, err
+}
+
+// jivaVolumeTopology groups the controller and replica pods of Jiva
+// volumes by persistent volume, and asks each controller for the state of
+// its replicas.
+func (r *Reporter) jivaVolumeTopology() (report.Topology, []JivaVolume, error) {
+	jivaVolumes := []JivaVolume{}
+	result := report.MakeTopology().
+		WithMetadataTemplates(JivaVolumeMetadataTemplates).
+		WithTableTemplates(JivaVolumeTableTemplates)
+	volumes := map[string]*JivaVolume{}
+	err := r.client.WalkPods(func(p Pod) error {
+		name := p.JivaVolumeName()
+		if name == "" {
+			return nil
+		}
+		v, ok := volumes[name]
+		if !ok {
+			v = &JivaVolume{Name: name}
+			volumes[name] = v
+		}
+		if p.JivaRole() == JivaControllerRole {
+			v.Controller = p
+		} else {
+			v.ReplicaPods = append(v.ReplicaPods, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, jivaVolumes, err
+	}
+	for _, v := range volumes {
+		if v.Controller != nil && v.Controller.PodIP() != "" {
+			replicas, ok := r.jiva.replicas(v.Controller.PodIP())
+			v.Replicas, v.Unreachable = replicas, !ok
+		}
+		result.AddNode(v.GetNode(r.probeID))
+		jivaVolumes = append(jivaVolumes, *v)
+	}
+	return result, jivaVolumes, nil
+}
+
 func (r *Reporter) storageClassTopology() (report.Topology, []StorageClass, error) {
 	storageClasses := []StorageClass{}
 	result := report.MakeTopology().
@@ -826,6 +1311,125 @@ func (r *Reporter) volumeSnapshotContentTopology() (report.Topology, []VolumeSna
 	return result, vsc, err
 }
 
+func (r *Reporter) networkPolicyTopology() (report.Topology, []NetworkPolicy, error) {
+	networkPolicies := []NetworkPolicy{}
+	result := report.MakeTopology().
+		WithMetadataTemplates(NetworkPolicyMetadataTemplates).
+		WithTableTemplates(TableTemplates)
+	result.Controls.AddControl(DescribeControl)
+	err := r.client.WalkNetworkPolicies(func(p NetworkPolicy) error {
+		result.AddNode(p.GetNode(r.probeID))
+		networkPolicies = append(networkPolicies, p)
+		return nil
+	})
+	return result, networkPolicies, err
+}
+
+// attachEvents adds the most recent events about each reported resource
+// to its node, as a table.
+func (r *Reporter) attachEvents(rpt *report.Report) error {
+	events := map[string][]ResourceEvent{}
+	err := r.client.WalkEvents(func(e ResourceEvent) error {
+		if nodeID, ok := EventNodeID(e); ok {
+			events[nodeID] = append(events[nodeID], e)
+		}
+		return nil
+	})
+	if err != nil || len(events) == 0 {
+		return err
+	}
+	rpt.WalkTopologies(func(t *report.Topology) {
+		attached := false
+		for nodeID, nodeEvents := range events {
+			n, ok := t.Nodes[nodeID]
+			if !ok {
+				continue
+			}
+			sort.Slice(nodeEvents, func(i, j int) bool {
+				return nodeEvents[i].LastSeen().After(nodeEvents[j].LastSeen())
+			})
+			if len(nodeEvents) > maxNodeEvents {
+				nodeEvents = nodeEvents[:maxNodeEvents]
+			}
+			rows := make([]report.Row, 0, len(nodeEvents))
+			for i, e := range nodeEvents {
+				rows = append(rows, e.Row(fmt.Sprintf("event%02d", i)))
+			}
+			t.Nodes[nodeID] = n.AddPrefixMulticolumnTable(EventsPrefix, rows)
+			attached = true
+		}
+		if attached {
+			t.TableTemplates = t.TableTemplates.Merge(EventTableTemplates)
+		}
+	})
+	return nil
+}
+
+// attachHorizontalPodAutoscalers shows HorizontalPodAutoscalers on the
+// controllers they scale.
+func (r *Reporter) attachHorizontalPodAutoscalers(rpt *report.Report) error {
+	autoscalers := map[string]HorizontalPodAutoscaler{}
+	err := r.client.WalkHorizontalPodAutoscalers(func(h HorizontalPodAutoscaler) error {
+		kind, name := h.ScaleTarget()
+		autoscalers[kind+"/"+h.Namespace()+"/"+name] = h
+		return nil
+	})
+	if err != nil || len(autoscalers) == 0 {
+		return err
+	}
+	// Controllers are matched by their type, which is the kind of
+	// generic controllers
+	for _, t := range []*report.Topology{&rpt.Deployment, &rpt.StatefulSet, &rpt.GenericController} {
+		attached := false
+		for id, n := range t.Nodes {
+			kind, _ := n.Latest.Lookup(NodeType)
+			namespace, _ := n.Latest.Lookup(Namespace)
+			name, _ := n.Latest.Lookup(Name)
+			h, ok := autoscalers[kind+"/"+namespace+"/"+name]
+			if !ok {
+				continue
+			}
+			t.Nodes[id] = n.WithLatests(h.Latests()).
+				AddPrefixMulticolumnTable(AutoscalerMetricsPrefix, h.MetricRows())
+			attached = true
+		}
+		if attached {
+			t.MetadataTemplates = t.MetadataTemplates.Merge(AutoscalerMetadataTemplates)
+			t.TableTemplates = t.TableTemplates.Merge(AutoscalerTableTemplates)
+		}
+	}
+	return nil
+}
+
+func (r *Reporter) nodeTopology() (report.Topology, []Node, error) {
+	nodes := []Node{}
+	result := report.MakeTopology().
+		WithMetadataTemplates(NodeMetadataTemplates).
+		WithTableTemplates(TableTemplates)
+	result.Controls.AddControls(NodeControls)
+	result.Controls.AddControl(DescribeControl)
+	err := r.client.WalkNodes(func(n Node) error {
+		result.AddNode(n.GetNode(r.probeID))
+		nodes = append(nodes, n)
+		return nil
+	})
+	return result, nodes, err
+}
+
+func (r *Reporter) ingressTopology() (report.Topology, []Ingress, error) {
+	ingresses := []Ingress{}
+	result := report.MakeTopology().
+		WithMetadataTemplates(IngressMetadataTemplates).
+		WithTableTemplates(TableTemplates.Merge(IngressTableTemplates))
+	result.Controls.AddControl(DescribeControl)
+	err := r.client.WalkIngresses(func(i Ingress) error {
+		result.AddNode(i.GetNode(r.probeID))
+		ingresses = append(ingresses, i)
+		return nil
+	})
+	return result, ingresses, err
+}
+
 func (r *Reporter) jobTopology() (report.Topology, []Job, error) {
 	jobs := []Job{}
 	result := report.MakeTopology().
@@ -859,6 +1463,7 @@ func (r *Reporter) blockDeviceTopology() (report.Topology, []BlockDevice, error)
 	blockDevices := []BlockDevice{}
 	result := report.MakeTopology().
 		WithMetadataTemplates(BlockDeviceMetadataTemplates).
+		WithMetricTemplates(BlockDeviceMetricTemplates).
 		WithTableTemplates(TableTemplates)
 	result.Controls.AddControl(DescribeControl)
 	err := r.client.WalkBlockDevices(func(p BlockDevice) error {
@@ -915,6 +1520,7 @@ func (r *Reporter) cStorPoolTopology() (report.Topology, []CStorPool, error) {
 	cStorPool := []CStorPool{}
 	result := report.MakeTopology().
 		WithMetadataTemplates(CStorPoolMetadataTemplates).
+		WithMetricTemplates(CStorPoolMetricTemplates).
 		WithTableTemplates(TableTemplates)
 	result.Controls.AddControl(DescribeControl)
 	err := r.client.WalkCStorPools(func(p CStorPool) error {
@@ -957,6 +1563,7 @@ func (r *Reporter) cStorPoolInstanceTopology() (report.Topology, []CStorPoolInst
 	cStorPoolInstance := []CStorPoolInstance{}
 	result := report.MakeTopology().
 		WithMetadataTemplates(CStorPoolInstanceMetadataTemplates).
+		WithMetricTemplates(CStorPoolInstanceMetricTemplates).
 		WithTableTemplates(TableTemplates)
 	result.Controls.AddControl(DescribeControl)
 	err := r.client.WalkCStorPoolInstances(func(p CStorPoolInstance) error {
@@ -967,6 +1574,63 @@ func (r *Reporter) cStorPoolInstanceTopology() (report.Topology, []CStorPoolInst
 	return result, cStorPoolInstance, err
 }
 
+func (r *Reporter) cStorVolumeConfigTopology() (report.Topology, []CStorVolumeConfig, error) {
+	cStorVolumeConfigs := []CStorVolumeConfig{}
+	result := report.MakeTopology().
+		WithMetadataTemplates(CStorVolumeConfigMetadataTemplates).
+		WithTableTemplates(TableTemplates)
+	result.Controls.AddControl(DescribeControl)
+	err := r.client.WalkCStorVolumeConfigs(func(c CStorVolumeConfig) error {
+		result.AddNode(c.GetNode(r.probeID))
+		cStorVolumeConfigs = append(cStorVolumeConfigs, c)
+		return nil
+	})
+	return result, cStorVolumeConfigs, err
+}
+
+func (r *Reporter) cStorVolumeAttachmentTopology() (report.Topology, []CStorVolumeAttachment, error) {
+	cStorVolumeAttachments := []CStorVolumeAttachment{}
+	result := report.MakeTopology().
+		WithMetadataTemplates(CStorVolumeAttachmentMetadataTemplates).
+		WithTableTemplates(TableTemplates)
+	result.Controls.AddControl(DescribeControl)
+	err := r.client.WalkCStorVolumeAttachments(func(a CStorVolumeAttachment) error {
+		result.AddNode(a.GetNode(r.probeID))
+		cStorVolumeAttachments = append(cStorVolumeAttachments, a)
+		return nil
+	})
+	return result, cStorVolumeAttachments, err
+}
+
+// customResourceTopologies returns the topologies of the configured custom
+// resources, by CustomResourceDefinition. Parents are looked up once all
+// the resources are known, as they may be custom resources too.
+func (r *Reporter) customResourceTopologies() (map[string]report.Topology, error) {
+	var (
+		result    = map[string]report.Topology{}
+		idx       = customResourceIndex{}
+		resources = make([][]CustomResource, len(r.customResources))
+	)
+	for i, c := range r.customResources {
+		err := r.client.WalkCustomResources(c.GroupVersionResource(), func(cr CustomResource) error {
+			idx.add(c, cr)
+			resources[i] = append(resources[i], cr)
+			return nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+	for i, c := range r.customResources {
+		topology := c.MakeTopology()
+		for _, cr := range resources[i] {
+			topology.AddNode(c.GetNode(cr, r.probeID).WithParents(c.parents(cr, idx)))
+		}
+		result[c.CRD()] = topology
+	}
+	return result, nil
+}
+
 type labelledChild interface {
 	Labels() map[string]string
 	AddParent(string, string)
@@ -982,12 +1646,15 @@ func match(namespace string, selector labels.Selector, topology, id string) func
 	}
 }
 
-func (r *Reporter) podTopology(services []Service, deployments []Deployment, daemonSets []DaemonSet, statefulSets []StatefulSet, cronJobs []CronJob, jobs []Job) (report.Topology, error) {
+func (r *Reporter) podTopology(services []Service, deployments []Deployment, daemonSets []DaemonSet, statefulSets []StatefulSet, cronJobs []CronJob, jobs []Job) (report.Topology, report.Topology, error) {
 	var (
 		pods = report.MakeTopology().
 			WithMetadataTemplates(PodMetadataTemplates).
 			WithMetricTemplates(PodMetricTemplates).
 			WithTableTemplates(TableTemplates)
+		genericControllers = report.MakeTopology().
+					WithMetadataTemplates(GenericControllerMetadataTemplates).
+					WithTableTemplates(TableTemplates)
 		selectors = []func(labelledChild){}
 	)
 	pods.Controls.AddControl(report.Control{
@@ -1017,7 +1684,7 @@ func (r *Reporter) podTopology(services []Service, deployments []Deployment, dae
 	for _, deployment := range deployments {
 		selector, err := deployment.Selector()
 		if err != nil {
-			return pods, err
+			return pods, genericControllers, err
 		}
 		selectors = append(selectors, match(
 			deployment.Namespace(),
@@ -1029,7 +1696,7 @@ func (r *Reporter) podTopology(services []Service, deployments []Deployment, dae
 	for _, daemonSet := range daemonSets {
 		selector, err := daemonSet.Selector()
 		if err != nil {
-			return pods, err
+			return pods, genericControllers, err
 		}
 		selectors = append(selectors, match(
 			daemonSet.Namespace(),
@@ -1041,7 +1708,7 @@ func (r *Reporter) podTopology(services []Service, deployments []Deployment, dae
 	for _, statefulSet := range statefulSets {
 		selector, err := statefulSet.Selector()
 		if err != nil {
-			return pods, err
+			return pods, genericControllers, err
 		}
 		selectors = append(selectors, match(
 			statefulSet.Namespace(),
@@ -1053,7 +1720,7 @@ func (r *Reporter) podTopology(services []Service, deployments []Deployment, dae
 	for _, cronJob := range cronJobs {
 		cronJobSelectors, err := cronJob.Selectors()
 		if err != nil {
-			return pods, err
+			return pods, genericControllers, err
 		}
 		for _, selector := range cronJobSelectors {
 			selectors = append(selectors, match(
@@ -1066,7 +1733,7 @@ func (r *Reporter) podTopology(services []Service, deployments []Deployment, dae
 		for _, job := range jobs {
 			selector, err := job.Selector()
 			if err != nil {
-				return pods, err
+				return pods, genericControllers, err
 			}
 			selectors = append(selectors, match(
 				job.Namespace(),
@@ -1086,6 +1753,15 @@ func (r *Reporter) podTopology(services []Service, deployments []Deployment, dae
 			log.Debugf("No node name and cannot obtain local pods, reporting all (which may impact performance): %v", err)
 		}
 	}
+	nodeCPUCapacities := map[string]string{}
+	if err := r.client.WalkNodes(func(n Node) error {
+		if cpu := n.CPUCapacity(); cpu != "" {
+			nodeCPUCapacities[n.Name()] = cpu
+		}
+		return nil
+	}); err != nil {
+		return pods, genericControllers, err
+	}
 	err := r.client.WalkPods(func(p Pod) error {
 		// filter out non-local pods: we only want to report local ones for performance reasons.
 		if r.nodeName != "" {
@@ -1100,10 +1776,22 @@ func (r *Reporter) podTopology(services []Service, deployments []Deployment, dae
 		for _, selector := range selectors {
 			selector(p)
 		}
-		pods.AddNode(p.GetNode(r.probeID))
+		node := p.GetNode(r.probeID)
+		if cpu, ok := nodeCPUCapacities[p.NodeName()]; ok {
+			// Docker reports the CPU usage of containers relative to
+			// all the CPUs of the node, which requests compare against
+			node = node.WithLatest(NodeCPUCapacity, mtime.Now(), cpu)
+		}
+		if !hasControllerParent(node) {
+			if owner, ok := r.genericControllerOf(p); ok {
+				genericControllers.AddNode(GetGenericControllerNode(owner, r.probeID))
+				node = node.WithParent(report.GenericController, report.MakeGenericControllerNodeID(owner.UID()))
+			}
+		}
+		pods.AddNode(node)
 		return nil
 	})
-	return pods, err
+	return pods, genericControllers, err
 }
 
 func (r *Reporter) namespaceTopology() (report.Topology, error) {
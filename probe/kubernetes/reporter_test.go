@@ -7,11 +7,18 @@ import (
 	"io/ioutil"
 	"strings"
 	"testing"
+	"time"
 
+	cstorv1 "github.com/openebs/api/pkg/apis/cstor/v1"
+	mayav1alpha1 "github.com/openebs/maya/pkg/apis/openebs.io/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	apiv1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	k8smeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -101,6 +108,20 @@ var (
 			},
 		},
 	}
+	apiEndpoints1 = apiv1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pongservice",
+			Namespace: "ping",
+		},
+		Subsets: []apiv1.EndpointSubset{{
+			Addresses: []apiv1.EndpointAddress{
+				{IP: "10.0.2.1", TargetRef: &apiv1.ObjectReference{Kind: "Pod", UID: types.UID(pod1UID)}},
+			},
+			NotReadyAddresses: []apiv1.EndpointAddress{
+				{IP: "10.0.2.2", TargetRef: &apiv1.ObjectReference{Kind: "Pod", UID: types.UID(pod2UID)}},
+			},
+		}},
+	}
 	pod1     = kubernetes.NewPod(&apiPod1)
 	pod2     = kubernetes.NewPod(&apiPod2)
 	service1 = kubernetes.NewService(&apiService1)
@@ -108,9 +129,10 @@ var (
 
 func newMockClient() *mockClient {
 	return &mockClient{
-		pods:     []kubernetes.Pod{pod1, pod2},
-		services: []kubernetes.Service{service1},
-		logs:     map[string]io.ReadCloser{},
+		pods:      []kubernetes.Pod{pod1, pod2},
+		services:  []kubernetes.Service{service1},
+		endpoints: []kubernetes.ServiceEndpoints{kubernetes.NewServiceEndpoints(&apiEndpoints1)},
+		logs:      map[string]io.ReadCloser{},
 	}
 }
 
@@ -118,7 +140,29 @@ type mockClient struct {
 	pods        []kubernetes.Pod
 	services    []kubernetes.Service
 	deployments []kubernetes.Deployment
+	endpoints   []kubernetes.ServiceEndpoints
+	nodes       []kubernetes.Node
+	events      []kubernetes.ResourceEvent
+	csiDrivers  []kubernetes.CSIDriver
+	csiNodes    []kubernetes.CSINode
 	logs        map[string]io.ReadCloser
+
+	persistentVolumes      []kubernetes.PersistentVolume
+	persistentVolumeClaims []kubernetes.PersistentVolumeClaim
+	storageClasses         []kubernetes.StorageClass
+	jivaReplicas           map[string][]kubernetes.JivaReplica
+	cStorVolumeConfigs     []kubernetes.CStorVolumeConfig
+	cStorVolumeAttachments []kubernetes.CStorVolumeAttachment
+	cStorPools             []kubernetes.CStorPool
+	blockDevices           []kubernetes.BlockDevice
+	customResources        map[schema.GroupVersionResource][]kubernetes.CustomResource
+	owners                 map[types.UID]kubernetes.CustomResource
+	autoscalers            []kubernetes.HorizontalPodAutoscaler
+	statefulSets           []kubernetes.StatefulSet
+	replicaSets            []kubernetes.ReplicaSet
+	ownerLookups           []string
+	expanded               []string
+	rollouts               []string
 }
 
 func (c *mockClient) Stop() {}
@@ -142,6 +186,11 @@ func (c *mockClient) WalkDaemonSets(f func(kubernetes.DaemonSet) error) error {
 	return nil
 }
 func (c *mockClient) WalkStatefulSets(f func(kubernetes.StatefulSet) error) error {
+	for _, s := range c.statefulSets {
+		if err := f(s); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 func (c *mockClient) WalkCronJobs(f func(kubernetes.CronJob) error) error {
@@ -159,12 +208,27 @@ func (c *mockClient) WalkNamespaces(f func(kubernetes.NamespaceResource) error)
 	return nil
 }
 func (c *mockClient) WalkPersistentVolumes(f func(kubernetes.PersistentVolume) error) error {
+	for _, p := range c.persistentVolumes {
+		if err := f(p); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 func (c *mockClient) WalkPersistentVolumeClaims(f func(kubernetes.PersistentVolumeClaim) error) error {
+	for _, p := range c.persistentVolumeClaims {
+		if err := f(p); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 func (c *mockClient) WalkStorageClasses(f func(kubernetes.StorageClass) error) error {
+	for _, s := range c.storageClasses {
+		if err := f(s); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 func (c *mockClient) WalkVolumeSnapshots(f func(kubernetes.VolumeSnapshot) error) error {
@@ -189,9 +253,19 @@ func (c *mockClient) WalkCStorVolumeReplicas(f func(kubernetes.CStorVolumeReplic
 	return nil
 }
 func (c *mockClient) WalkCStorPools(f func(kubernetes.CStorPool) error) error {
+	for _, p := range c.cStorPools {
+		if err := f(p); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 func (c *mockClient) WalkBlockDevices(f func(kubernetes.BlockDevice) error) error {
+	for _, b := range c.blockDevices {
+		if err := f(b); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 func (c *mockClient) WalkBlockDeviceClaims(f func(kubernetes.BlockDeviceClaim) error) error {
@@ -212,6 +286,100 @@ func (c *mockClient) WalkVolumeSnapshotClasses(f func(kubernetes.VolumeSnapshotC
 func (c *mockClient) WalkVolumeSnapshotContents(f func(kubernetes.VolumeSnapshotContent) error) error {
 	return nil
 }
+func (c *mockClient) WalkNetworkPolicies(f func(kubernetes.NetworkPolicy) error) error {
+	return nil
+}
+func (c *mockClient) WalkNodes(f func(kubernetes.Node) error) error {
+	for _, node := range c.nodes {
+		if err := f(node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *mockClient) WalkEvents(f func(kubernetes.ResourceEvent) error) error {
+	for _, e := range c.events {
+		if err := f(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *mockClient) WalkCSIDrivers(f func(kubernetes.CSIDriver) error) error {
+	for _, d := range c.csiDrivers {
+		if err := f(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *mockClient) WalkCSINodes(f func(kubernetes.CSINode) error) error {
+	for _, n := range c.csiNodes {
+		if err := f(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *mockClient) WalkCStorVolumeConfigs(f func(kubernetes.CStorVolumeConfig) error) error {
+	for _, cvc := range c.cStorVolumeConfigs {
+		if err := f(cvc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *mockClient) WalkCStorVolumeAttachments(f func(kubernetes.CStorVolumeAttachment) error) error {
+	for _, cva := range c.cStorVolumeAttachments {
+		if err := f(cva); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *mockClient) WalkCustomResources(gvr schema.GroupVersionResource, f func(kubernetes.CustomResource) error) error {
+	for _, cr := range c.customResources[gvr] {
+		if err := f(cr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *mockClient) WalkHorizontalPodAutoscalers(f func(kubernetes.HorizontalPodAutoscaler) error) error {
+	for _, h := range c.autoscalers {
+		if err := f(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *mockClient) WalkReplicaSets(f func(kubernetes.ReplicaSet) error) error {
+	for _, r := range c.replicaSets {
+		if err := f(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+func (c *mockClient) GetOwner(namespace string, ref metav1.OwnerReference) (kubernetes.CustomResource, error) {
+	c.ownerLookups = append(c.ownerLookups, ref.Kind+"/"+ref.Name)
+	owner, ok := c.owners[ref.UID]
+	if !ok {
+		return nil, fmt.Errorf("%s %s/%s not found", ref.Kind, namespace, ref.Name)
+	}
+	return owner, nil
+}
+func (c *mockClient) WalkIngresses(f func(kubernetes.Ingress) error) error {
+	return nil
+}
+func (c *mockClient) WalkEndpoints(f func(kubernetes.ServiceEndpoints) error) error {
+	for _, e := range c.endpoints {
+		if err := f(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 func (*mockClient) WatchPods(func(kubernetes.Event, kubernetes.Pod)) {}
 func (c *mockClient) GetLogs(ctx context.Context, namespaceID, podName string, _ []string) (io.ReadCloser, error) {
 	r, ok := c.logs[namespaceID+";"+podName]
@@ -229,6 +397,38 @@ func (c *mockClient) ScaleUp(ctx context.Context, namespaceID, id string) error
 func (c *mockClient) ScaleDown(ctx context.Context, namespaceID, id string) error {
 	return nil
 }
+func (c *mockClient) SetReplicas(ctx context.Context, kind, namespaceID, id string, replicas int32) error {
+	c.rollouts = append(c.rollouts, fmt.Sprintf("scale %s %s/%s=%d", kind, namespaceID, id, replicas))
+	return nil
+}
+func (c *mockClient) RestartRollout(ctx context.Context, kind, namespaceID, id string) error {
+	c.rollouts = append(c.rollouts, fmt.Sprintf("restart %s %s/%s", kind, namespaceID, id))
+	return nil
+}
+func (c *mockClient) RollbackDeployment(ctx context.Context, namespaceID, id, replicaSetID string) error {
+	c.rollouts = append(c.rollouts, fmt.Sprintf("rollback %s/%s to %s", namespaceID, id, replicaSetID))
+	return nil
+}
+func (c *mockClient) CordonNode(ctx context.Context, name string, unschedulable bool) error {
+	return nil
+}
+func (c *mockClient) DrainNode(ctx context.Context, name string) error {
+	return nil
+}
+func (c *mockClient) ExpandPersistentVolumeClaim(ctx context.Context, namespaceID, persistentVolumeClaimID, capacity string) error {
+	c.expanded = append(c.expanded, namespaceID+"/"+persistentVolumeClaimID+"="+capacity)
+	return nil
+}
+func (c *mockClient) DeletePersistentVolumeClaim(ctx context.Context, namespaceID, persistentVolumeClaimID string) error {
+	return nil
+}
+func (c *mockClient) GetJivaReplicas(ctx context.Context, controllerIP string) ([]kubernetes.JivaReplica, error) {
+	replicas, ok := c.jivaReplicas[controllerIP]
+	if !ok {
+		return nil, fmt.Errorf("Not found")
+	}
+	return replicas, nil
+}
 func (c *mockClient) CloneVolumeSnapshot(ctx context.Context, namespaceID, VolumeSnapshotID, persistentVolumeClaimID, capacity string) error {
 	return nil
 }
@@ -331,11 +531,18 @@ func TestReporter(t *testing.T) {
 			kubernetes.Name:      "pongservice",
 			kubernetes.Namespace: "ping",
 			kubernetes.Created:   service1.Created(),
+
+			kubernetes.ReadyEndpoints:    "1",
+			kubernetes.NotReadyEndpoints: "1",
 		} {
 			if have, ok := node.Latest.Lookup(k); !ok || have != want {
 				t.Errorf("Expected service %s latest %q: %q, got %q", serviceID, k, want, have)
 			}
 		}
+
+		if pods, ok := node.Sets.Lookup(kubernetes.EndpointPods); !ok || !pods.Contains(pod1ID) || !pods.Contains(pod2ID) {
+			t.Errorf("Expected service %s to have endpoint pods %q and %q, got %q", serviceID, pod1ID, pod2ID, pods)
+		}
 	}
 
 	// Reporter should allow controls for k8s topologies by providing a probe ID
@@ -397,6 +604,797 @@ func BenchmarkReporter(b *testing.B) {
 	}
 }
 
+func TestReporterNodes(t *testing.T) {
+	mockK8s := newMockClient()
+	mockK8s.nodes = []kubernetes.Node{kubernetes.NewNode(&apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName, UID: types.UID("node1234")},
+		Spec: apiv1.NodeSpec{
+			Unschedulable: true,
+			Taints:        []apiv1.Taint{{Key: "dedicated", Value: "storage", Effect: apiv1.TaintEffectNoSchedule}},
+		},
+		Status: apiv1.NodeStatus{
+			Conditions: []apiv1.NodeCondition{
+				{Type: apiv1.NodeReady, Status: apiv1.ConditionFalse},
+				{Type: apiv1.NodeMemoryPressure, Status: apiv1.ConditionTrue},
+				{Type: apiv1.NodeDiskPressure, Status: apiv1.ConditionFalse},
+			},
+			NodeInfo: apiv1.NodeSystemInfo{KubeletVersion: "v1.19.2"},
+		},
+	})}
+	hr := controls.NewDefaultHandlerRegistry()
+	rpt, _ := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, hr, "", 0).Report()
+
+	nodeID := report.MakeKubernetesNodeNodeID("node1234")
+	node, ok := rpt.KubernetesNode.Nodes[nodeID]
+	if !ok {
+		t.Fatalf("Expected report to have node %q, but not found", nodeID)
+	}
+	for k, want := range map[string]string{
+		kubernetes.Name:           nodeName,
+		kubernetes.NodeReady:      "False",
+		kubernetes.NodeConditions: "NotReady,MemoryPressure",
+		kubernetes.Taints:         "dedicated=storage:NoSchedule",
+		kubernetes.KubeletVersion: "v1.19.2",
+		kubernetes.Unschedulable:  "true",
+	} {
+		if have, ok := node.Latest.Lookup(k); !ok || have != want {
+			t.Errorf("Expected node %s latest %q: %q, got %q", nodeID, k, want, have)
+		}
+	}
+	if _, ok := node.LatestControls.Lookup(kubernetes.UncordonNode); !ok {
+		t.Errorf("Expected cordoned node %s to have the uncordon control", nodeID)
+	}
+	if _, ok := node.LatestControls.Lookup(kubernetes.CordonNode); ok {
+		t.Errorf("Expected cordoned node %s not to have the cordon control", nodeID)
+	}
+	if _, ok := rpt.Host.MetadataTemplates[kubernetes.NodeReady]; !ok {
+		t.Errorf("Expected host topology to have the node metadata templates")
+	}
+}
+
+func TestReporterHorizontalPodAutoscalers(t *testing.T) {
+	utilization := int32(80)
+	mockK8s := newMockClient()
+	mockK8s.deployments = []kubernetes.Deployment{kubernetes.NewDeployment(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "pong", UID: types.UID("deployment-uid"), Namespace: "ping"},
+	})}
+	mockK8s.autoscalers = []kubernetes.HorizontalPodAutoscaler{kubernetes.NewHorizontalPodAutoscaler(&autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "pong-hpa", Namespace: "ping"},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2beta2.CrossVersionObjectReference{Kind: "Deployment", Name: "pong"},
+			MaxReplicas:    10,
+			Metrics: []autoscalingv2beta2.MetricSpec{
+				{
+					Type: autoscalingv2beta2.ResourceMetricSourceType,
+					Resource: &autoscalingv2beta2.ResourceMetricSource{
+						Name:   apiv1.ResourceCPU,
+						Target: autoscalingv2beta2.MetricTarget{Type: autoscalingv2beta2.UtilizationMetricType, AverageUtilization: &utilization},
+					},
+				},
+				{
+					Type: autoscalingv2beta2.PodsMetricSourceType,
+					Pods: &autoscalingv2beta2.PodsMetricSource{
+						Metric: autoscalingv2beta2.MetricIdentifier{Name: "requests_per_second"},
+						Target: autoscalingv2beta2.MetricTarget{Type: autoscalingv2beta2.AverageValueMetricType, AverageValue: resource.NewQuantity(100, resource.DecimalSI)},
+					},
+				},
+			},
+		},
+		Status: autoscalingv2beta2.HorizontalPodAutoscalerStatus{
+			CurrentMetrics: []autoscalingv2beta2.MetricStatus{{
+				Type: autoscalingv2beta2.ResourceMetricSourceType,
+				Resource: &autoscalingv2beta2.ResourceMetricStatus{
+					Name:    apiv1.ResourceCPU,
+					Current: autoscalingv2beta2.MetricValueStatus{AverageUtilization: &utilization},
+				},
+			}},
+		},
+	})}
+	hr := controls.NewDefaultHandlerRegistry()
+	rpt, err := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, hr, "", 0).Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := rpt.Deployment.Nodes[report.MakeDeploymentNodeID("deployment-uid")]
+	for key, want := range map[string]string{
+		kubernetes.Autoscaler:  "pong-hpa",
+		kubernetes.MinReplicas: "1",
+		kubernetes.MaxReplicas: "10",
+	} {
+		if have, _ := node.Latest.Lookup(key); have != want {
+			t.Errorf("Expected %s of the deployment to be %q, got %q", key, want, have)
+		}
+	}
+	template, ok := rpt.Deployment.TableTemplates[kubernetes.AutoscalerMetricsPrefix]
+	if !ok {
+		t.Fatalf("Expected deployment topology to have the autoscaler metrics table template")
+	}
+	want := []report.Row{
+		{ID: "metric00", Entries: map[string]string{"metric": "cpu", "current": "80%", "target": "80%"}},
+		{ID: "metric01", Entries: map[string]string{"metric": "requests_per_second", "current": "", "target": "100 (avg)"}},
+	}
+	if have := node.ExtractMulticolumnTable(template); !reflect.DeepEqual(want, have) {
+		t.Errorf("Expected autoscaler metrics %v, got %v", want, have)
+	}
+	if _, ok := rpt.StatefulSet.MetadataTemplates[kubernetes.Autoscaler]; ok {
+		t.Errorf("Expected only the topologies of scaled controllers to have the autoscaler templates")
+	}
+}
+
+func TestReporterPodResources(t *testing.T) {
+	pod := apiPod1
+	pod.Spec.Containers = []apiv1.Container{
+		{Resources: apiv1.ResourceRequirements{
+			Requests: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("250m"), apiv1.ResourceMemory: resource.MustParse("64Mi")},
+			Limits:   apiv1.ResourceList{apiv1.ResourceMemory: resource.MustParse("128Mi")},
+		}},
+		{Resources: apiv1.ResourceRequirements{
+			Requests: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("500m")},
+			Limits:   apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("1"), apiv1.ResourceMemory: resource.MustParse("128Mi")},
+		}},
+	}
+	mockK8s := newMockClient()
+	mockK8s.pods = []kubernetes.Pod{kubernetes.NewPod(&pod)}
+	mockK8s.nodes = []kubernetes.Node{kubernetes.NewNode(&apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Status:     apiv1.NodeStatus{Capacity: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("4")}},
+	})}
+	hr := controls.NewDefaultHandlerRegistry()
+	rpt, err := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, hr, "", 0).Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := rpt.Pod.Nodes[report.MakePodNodeID(pod1UID)]
+	for key, want := range map[string]string{
+		kubernetes.CPURequest:      "750m",
+		kubernetes.MemoryRequest:   "64Mi",
+		kubernetes.MemoryLimit:     "256Mi",
+		kubernetes.NodeCPUCapacity: "4",
+	} {
+		if have, _ := node.Latest.Lookup(key); have != want {
+			t.Errorf("Expected %s of the pod to be %q, got %q", key, want, have)
+		}
+	}
+	if have, ok := node.Latest.Lookup(kubernetes.CPULimit); ok {
+		t.Errorf("Expected no CPU limit for a pod with an unlimited container, got %q", have)
+	}
+}
+
+func TestReporterEvents(t *testing.T) {
+	mockK8s := newMockClient()
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 12; i++ {
+		mockK8s.events = append(mockK8s.events, kubernetes.NewResourceEvent(&apiv1.Event{
+			InvolvedObject: apiv1.ObjectReference{Kind: "Pod", UID: types.UID(pod1UID)},
+			Type:           apiv1.EventTypeWarning,
+			Reason:         fmt.Sprintf("Reason%d", i),
+			LastTimestamp:  metav1.NewTime(start.Add(time.Duration(i) * time.Minute)),
+		}))
+	}
+	mockK8s.events = append(mockK8s.events, kubernetes.NewResourceEvent(&apiv1.Event{
+		InvolvedObject: apiv1.ObjectReference{Kind: "ConfigMap", UID: types.UID("configmap")},
+		Reason:         "Ignored",
+	}))
+	hr := controls.NewDefaultHandlerRegistry()
+	rpt, _ := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, hr, "", 0).Report()
+
+	template, ok := rpt.Pod.TableTemplates[kubernetes.EventsPrefix]
+	if !ok {
+		t.Fatalf("Expected pod topology to have the events table template")
+	}
+	rows := rpt.Pod.Nodes[report.MakePodNodeID(pod1UID)].ExtractMulticolumnTable(template)
+	if len(rows) != 10 {
+		t.Fatalf("Expected 10 events, got %d", len(rows))
+	}
+	if have := rows[0].Entries["reason"]; have != "Reason11" {
+		t.Errorf("Expected most recent event first, got %q", have)
+	}
+	if _, ok := rpt.Pod.Nodes[report.MakePodNodeID(pod2UID)].Latest.Lookup(kubernetes.EventsPrefix + "event00___reason"); ok {
+		t.Errorf("Expected no events on pod %s", pod2UID)
+	}
+}
+
+func TestReporterCSIDrivers(t *testing.T) {
+	mockK8s := newMockClient()
+	mockK8s.csiDrivers = []kubernetes.CSIDriver{
+		kubernetes.NewCSIDriver(&storagev1.CSIDriver{
+			ObjectMeta: metav1.ObjectMeta{Name: "inline.csi.example.com"},
+			Spec: storagev1.CSIDriverSpec{
+				VolumeLifecycleModes: []storagev1.VolumeLifecycleMode{storagev1.VolumeLifecycleEphemeral},
+			},
+		}),
+	}
+	mockK8s.csiNodes = []kubernetes.CSINode{
+		kubernetes.NewCSINode(&storagev1.CSINode{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+			Spec: storagev1.CSINodeSpec{Drivers: []storagev1.CSINodeDriver{
+				{Name: "inline.csi.example.com"},
+				{Name: "block.csi.example.com"},
+			}},
+		}),
+		kubernetes.NewCSINode(&storagev1.CSINode{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-b"},
+			Spec: storagev1.CSINodeSpec{Drivers: []storagev1.CSINodeDriver{
+				{Name: "block.csi.example.com"},
+			}},
+		}),
+	}
+	storageClass := func(name, provisioner string) kubernetes.StorageClass {
+		return kubernetes.NewStorageClass(&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: name},
+			Provisioner: provisioner,
+		})
+	}
+	mockK8s.storageClasses = []kubernetes.StorageClass{
+		storageClass("inline", "inline.csi.example.com"),
+		storageClass("block", "block.csi.example.com"),
+	}
+	pvc := func(uid, storageClass string) kubernetes.PersistentVolumeClaim {
+		return kubernetes.NewPersistentVolumeClaim(&apiv1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: uid, Namespace: "ping", UID: types.UID(uid)},
+			Spec:       apiv1.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+		})
+	}
+	mockK8s.persistentVolumeClaims = []kubernetes.PersistentVolumeClaim{
+		pvc("inline-claim", "inline"),
+		pvc("block-claim", "block"),
+	}
+	hr := controls.NewDefaultHandlerRegistry()
+	rpt, _ := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, hr, "", 0).Report()
+
+	for name, want := range map[string]struct {
+		snapshots string
+		nodes     int
+	}{
+		"inline.csi.example.com": {"false", 1},
+		"block.csi.example.com":  {"true", 2},
+	} {
+		node, ok := rpt.CSIDriver.Nodes[report.MakeCSIDriverNodeID(name)]
+		if !ok {
+			t.Fatalf("Expected report to have CSI driver %q, but not found", name)
+		}
+		if have, _ := node.Latest.Lookup(kubernetes.SnapshotSupport); have != want.snapshots {
+			t.Errorf("Expected CSI driver %s snapshot support %q, got %q", name, want.snapshots, have)
+		}
+		if have, _ := node.Counters.Lookup(report.KubernetesNode); have != want.nodes {
+			t.Errorf("Expected CSI driver %s to run on %d nodes, got %d", name, want.nodes, have)
+		}
+	}
+
+	for uid, want := range map[string]bool{"inline-claim": true, "block-claim": false} {
+		node := rpt.PersistentVolumeClaim.Nodes[report.MakePersistentVolumeClaimNodeID(uid)]
+		if data, ok := node.LatestControls.Lookup(kubernetes.CreateVolumeSnapshot); !ok || data.Dead != want {
+			t.Errorf("Expected snapshot control of %s to be dead: %v, got %v", uid, want, data)
+		}
+	}
+}
+
+func TestReporterJivaVolumes(t *testing.T) {
+	jivaPod := func(name, ip string, labels map[string]string) kubernetes.Pod {
+		labels[kubernetes.PersistentVolumeLabel] = "pvc-jiva"
+		return kubernetes.NewPod(&apiv1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "openebs", UID: types.UID(name), Labels: labels},
+			Status:     apiv1.PodStatus{PodIP: ip},
+			Spec: apiv1.PodSpec{
+				NodeName: nodeName,
+				Volumes: []apiv1.Volume{{
+					Name:         "openebs",
+					VolumeSource: apiv1.VolumeSource{HostPath: &apiv1.HostPathVolumeSource{Path: "/var/openebs/pvc-jiva"}},
+				}},
+			},
+		})
+	}
+	mockK8s := newMockClient()
+	mockK8s.pods = append(mockK8s.pods,
+		jivaPod("pvc-jiva-ctrl", "10.0.3.1", map[string]string{kubernetes.ControllerPodLabel: kubernetes.JivaControllerValue}),
+		jivaPod("pvc-jiva-rep-1", "10.0.3.2", map[string]string{kubernetes.ReplicaPodLabel: kubernetes.JivaReplicaValue}),
+		jivaPod("pvc-jiva-rep-2", "10.0.3.3", map[string]string{kubernetes.ReplicaPodLabel: kubernetes.JivaReplicaValue}),
+	)
+	mockK8s.jivaReplicas = map[string][]kubernetes.JivaReplica{
+		"10.0.3.1": {
+			{Address: "tcp://10.0.3.2:9502", Mode: kubernetes.JivaReplicaModeRW},
+			{Address: "tcp://10.0.3.3:9502", Mode: kubernetes.JivaReplicaModeWO},
+		},
+	}
+	hr := controls.NewDefaultHandlerRegistry()
+	reporter := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, hr, "", 0)
+	defer reporter.Stop()
+
+	// Controllers are polled in the background, from the first report on
+	var node report.Node
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		rpt, _ := reporter.Report()
+		var ok bool
+		if node, ok = rpt.JivaVolume.Nodes[report.MakeJivaVolumeNodeID("pvc-jiva")]; !ok {
+			t.Fatalf("Expected report to have Jiva volume pvc-jiva, but not found")
+		}
+		if status, _ := node.Latest.Lookup(kubernetes.Status); status != "Unknown" || time.Now().After(deadline) {
+			break
+		}
+	}
+	for key, want := range map[string]string{
+		kubernetes.JivaController:         "pvc-jiva-ctrl",
+		kubernetes.Status:                 "Rebuilding",
+		kubernetes.ProvisionedReplicas:    "2",
+		kubernetes.HealthyReplicas:        "1",
+		kubernetes.JivaRebuildingReplicas: "1",
+	} {
+		if have, _ := node.Latest.Lookup(key); have != want {
+			t.Errorf("Expected Jiva volume %s %q, got %q", key, want, have)
+		}
+	}
+	if pods, _ := node.Sets.Lookup(kubernetes.JivaReplicaPods); !pods.Equal(report.MakeStringSet(
+		report.MakePodNodeID("pvc-jiva-rep-1"), report.MakePodNodeID("pvc-jiva-rep-2"))) {
+		t.Errorf("Unexpected Jiva replica pods %v", pods)
+	}
+	rows := node.ExtractMulticolumnTable(kubernetes.JivaVolumeTableTemplates[kubernetes.JivaReplicasPrefix])
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 replica rows, got %v", rows)
+	}
+	for i, want := range []map[string]string{
+		{"pod": "pvc-jiva-rep-1", "mode": kubernetes.JivaReplicaModeRW, "rebuilding": "false", "host_path": "/var/openebs/pvc-jiva", "node": nodeName},
+		{"pod": "pvc-jiva-rep-2", "mode": kubernetes.JivaReplicaModeWO, "rebuilding": "true", "host_path": "/var/openebs/pvc-jiva", "node": nodeName},
+	} {
+		for column, value := range want {
+			if have := rows[i].Entries[column]; have != value {
+				t.Errorf("Expected replica row %d %s %q, got %q", i, column, value, have)
+			}
+		}
+	}
+
+	// Without an answer from the controller, the replica state is unknown
+	mockK8s.jivaReplicas = nil
+	other := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, hr, "", 0)
+	defer other.Stop()
+	rpt, _ := other.Report()
+	node = rpt.JivaVolume.Nodes[report.MakeJivaVolumeNodeID("pvc-jiva")]
+	if have, _ := node.Latest.Lookup(kubernetes.Status); have != "Unknown" {
+		t.Errorf("Expected Jiva volume status %q, got %q", "Unknown", have)
+	}
+}
+
+func TestReporterCStorCSIVolumes(t *testing.T) {
+	mockK8s := newMockClient()
+	mockK8s.cStorVolumeConfigs = []kubernetes.CStorVolumeConfig{
+		kubernetes.NewCStorVolumeConfig(&cstorv1.CStorVolumeConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-csi", Namespace: "openebs", UID: types.UID("cvc")},
+			Spec:       cstorv1.CStorVolumeConfigSpec{Provision: cstorv1.VolumeProvision{ReplicaCount: 2}},
+			Publish:    cstorv1.CStorVolumeConfigPublish{NodeID: "node-a"},
+			Status: cstorv1.CStorVolumeConfigStatus{
+				Phase:    cstorv1.CStorVolumeConfigPhaseBound,
+				PoolInfo: []string{"pool-a", "pool-b"},
+			},
+		}),
+	}
+	mockK8s.cStorVolumeAttachments = []kubernetes.CStorVolumeAttachment{
+		kubernetes.NewCStorVolumeAttachment(&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "cstor.openebs.io/v1",
+			"kind":       "CStorVolumeAttachment",
+			"metadata":   map[string]interface{}{"name": "pvc-csi-node-a", "namespace": "openebs", "uid": "cva"},
+			"spec": map[string]interface{}{
+				"volume": map[string]interface{}{"name": "pvc-csi", "ownerNodeID": "node-a", "devicePath": "/dev/sdb"},
+			},
+			"status": "Mounted",
+		}}),
+	}
+	hr := controls.NewDefaultHandlerRegistry()
+	rpt, _ := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, hr, "", 0).Report()
+
+	for id, want := range map[string]map[string]string{
+		report.MakeCStorVolumeConfigNodeID("cvc"): {
+			kubernetes.VolumeName:         "pvc-csi",
+			kubernetes.Status:             "Bound",
+			kubernetes.Replicas:           "2",
+			kubernetes.CStorPoolInstances: "pool-a" + report.ScopeDelim + "pool-b",
+			kubernetes.PublishedNode:      "node-a",
+		},
+		report.MakeCStorVolumeAttachmentNodeID("cva"): {
+			kubernetes.Name:          "pvc-csi-node-a",
+			kubernetes.VolumeName:    "pvc-csi",
+			kubernetes.Status:        "Mounted",
+			kubernetes.PublishedNode: "node-a",
+			kubernetes.DevicePath:    "/dev/sdb",
+		},
+	} {
+		node, ok := rpt.CStorVolumeConfig.Nodes[id]
+		if !ok {
+			node, ok = rpt.CStorVolumeAttachment.Nodes[id]
+		}
+		if !ok {
+			t.Fatalf("Expected report to have node %s, but not found", id)
+		}
+		for key, value := range want {
+			if have, _ := node.Latest.Lookup(key); have != value {
+				t.Errorf("Expected %s %s %q, got %q", id, key, value, have)
+			}
+		}
+	}
+}
+
+func TestVolumeStatsReporter(t *testing.T) {
+	oldGetVolumeStats := kubernetes.GetVolumeStats
+	defer func() { kubernetes.GetVolumeStats = oldGetVolumeStats }()
+	kubernetes.GetVolumeStats = func(string) (map[string]kubernetes.VolumeStats, error) {
+		return map[string]kubernetes.VolumeStats{
+			"ping/data": {CapacityBytes: 1000, UsedBytes: 400, AvailableBytes: 600, Inodes: 10, InodesUsed: 3, InodesFree: 7},
+		}, nil
+	}
+
+	rpt, _ := kubernetes.NewVolumeStatsReporter(10255).Report()
+	node, ok := rpt.PersistentVolumeClaim.Nodes[report.MakeVolumeClaimStatsNodeID("ping/data")]
+	if !ok {
+		t.Fatalf("Expected the statistics of claim ping/data, have %v", rpt.PersistentVolumeClaim.Nodes)
+	}
+	if namespace, _ := node.Latest.Lookup(kubernetes.Namespace); namespace != "ping" {
+		t.Errorf("Expected the statistics in namespace ping, got %q", namespace)
+	}
+	for id, want := range map[string][2]float64{
+		kubernetes.VolumeUsedBytes:      {400, 1000},
+		kubernetes.VolumeAvailableBytes: {600, 1000},
+		kubernetes.VolumeInodesUsed:     {3, 10},
+		kubernetes.VolumeInodesFree:     {7, 10},
+	} {
+		metric, ok := node.Metrics.Lookup(id)
+		if !ok {
+			t.Fatalf("Expected metric %s, have %v", id, node.Metrics)
+		}
+		if value, _ := metric.LastSample(); value.Value != want[0] || metric.Max != want[1] {
+			t.Errorf("Expected %s to be %v of %v, got %v of %v", id, want[0], want[1], value.Value, metric.Max)
+		}
+	}
+	if _, ok := rpt.PersistentVolumeClaim.MetricTemplates[kubernetes.VolumeUsedBytes]; !ok {
+		t.Errorf("Expected a metric template for the used space of claims")
+	}
+}
+
+func TestReporterStorageMetrics(t *testing.T) {
+	mockK8s := newMockClient()
+	mockK8s.cStorPools = []kubernetes.CStorPool{
+		kubernetes.NewCStorPool(&mayav1alpha1.CStorPool{
+			ObjectMeta: metav1.ObjectMeta{Name: "pool", UID: types.UID("pool")},
+			Status: mayav1alpha1.CStorPoolStatus{Capacity: mayav1alpha1.CStorPoolCapacityAttr{
+				Total: "10G", Used: "1.5G", Free: "8.5G",
+			}},
+		}),
+	}
+	mockK8s.blockDevices = []kubernetes.BlockDevice{
+		kubernetes.NewBlockDevice(&mayav1alpha1.BlockDevice{
+			ObjectMeta: metav1.ObjectMeta{Name: "bd", UID: types.UID("bd")},
+			Spec:       mayav1alpha1.DeviceSpec{Capacity: mayav1alpha1.DeviceCapacity{Storage: 2048}},
+		}),
+	}
+	hr := controls.NewDefaultHandlerRegistry()
+	rpt, _ := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, hr, "", 0).Report()
+
+	pool := rpt.CStorPool.Nodes[report.MakeCStorPoolNodeID("pool")]
+	if metric, ok := pool.Metrics.Lookup(kubernetes.PoolUsedBytes); !ok || metric.Max != 10*1024*1024*1024 {
+		t.Errorf("Expected the pool used space out of 10G, got %v", metric)
+	}
+	device := rpt.BlockDevice.Nodes[report.MakeBlockDeviceNodeID("bd")]
+	if metric, ok := device.Metrics.Lookup(kubernetes.DeviceCapacityBytes); !ok {
+		t.Errorf("Expected the block device capacity, got %v", metric)
+	}
+}
+
+func TestReporterVolumeClaimControls(t *testing.T) {
+	allowExpansion := true
+	mockK8s := newMockClient()
+	mockK8s.storageClasses = []kubernetes.StorageClass{
+		kubernetes.NewStorageClass(&storagev1.StorageClass{
+			ObjectMeta:           metav1.ObjectMeta{Name: "expandable"},
+			AllowVolumeExpansion: &allowExpansion,
+		}),
+		kubernetes.NewStorageClass(&storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{Name: "fixed"},
+		}),
+	}
+	pvc := func(uid, storageClass string, conditions ...apiv1.PersistentVolumeClaimCondition) kubernetes.PersistentVolumeClaim {
+		return kubernetes.NewPersistentVolumeClaim(&apiv1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: uid, Namespace: "ping", UID: types.UID(uid)},
+			Spec:       apiv1.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+			Status: apiv1.PersistentVolumeClaimStatus{
+				Phase:      apiv1.ClaimBound,
+				Capacity:   apiv1.ResourceList{apiv1.ResourceStorage: resource.MustParse("5Gi")},
+				Conditions: conditions,
+			},
+		})
+	}
+	mockK8s.persistentVolumeClaims = []kubernetes.PersistentVolumeClaim{
+		pvc("expandable-claim", "expandable", apiv1.PersistentVolumeClaimCondition{
+			Type:   apiv1.PersistentVolumeClaimFileSystemResizePending,
+			Status: apiv1.ConditionTrue,
+		}),
+		pvc("fixed-claim", "fixed"),
+	}
+	hr := controls.NewDefaultHandlerRegistry()
+	rpt, _ := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, hr, "", 0).Report()
+
+	for uid, want := range map[string]bool{"expandable-claim": false, "fixed-claim": true} {
+		node := rpt.PersistentVolumeClaim.Nodes[report.MakePersistentVolumeClaimNodeID(uid)]
+		if data, ok := node.LatestControls.Lookup(kubernetes.ExpandVolumeClaim); !ok || data.Dead != want {
+			t.Errorf("Expected expand control of %s to be dead: %v, got %v", uid, want, data)
+		}
+		if _, ok := node.LatestControls.Lookup(kubernetes.DeleteVolumeClaim); !ok {
+			t.Errorf("Expected %s to have the delete control", uid)
+		}
+	}
+	node := rpt.PersistentVolumeClaim.Nodes[report.MakePersistentVolumeClaimNodeID("expandable-claim")]
+	if have, _ := node.Latest.Lookup(kubernetes.ResizeStatus); have != "File system resize pending" {
+		t.Errorf("Expected the claim to be pending a file system resize, got %q", have)
+	}
+
+	if resp := hr.HandleControlRequest(xfer.Request{
+		NodeID:  report.MakePersistentVolumeClaimNodeID("expandable-claim"),
+		Control: kubernetes.ExpandVolumeClaim,
+	}); resp.Error == "" {
+		t.Errorf("Expected an error expanding the claim without a capacity")
+	}
+	if resp := hr.HandleControlRequest(xfer.Request{
+		NodeID:      report.MakePersistentVolumeClaimNodeID("expandable-claim"),
+		Control:     kubernetes.ExpandVolumeClaim,
+		ControlArgs: map[string]string{"capacity": "20Gi"},
+	}); resp.Error != "" {
+		t.Fatalf("Unexpected error expanding the claim: %s", resp.Error)
+	}
+	if want := []string{"ping/expandable-claim=20Gi"}; !reflect.DeepEqual(mockK8s.expanded, want) {
+		t.Errorf("Expected the claim to be expanded to %v, got %v", want, mockK8s.expanded)
+	}
+
+	resp := hr.HandleControlRequest(xfer.Request{
+		NodeID:  report.MakePersistentVolumeClaimNodeID("fixed-claim"),
+		Control: kubernetes.DeleteVolumeClaim,
+	})
+	if resp.RemovedNode != report.MakePersistentVolumeClaimNodeID("fixed-claim") {
+		t.Errorf("Expected the deleted claim to be removed, got %v", resp)
+	}
+}
+
+func TestReporterRolloutControls(t *testing.T) {
+	isController := true
+	deployment := &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "ping",
+			UID:         "web-uid",
+			Annotations: map[string]string{"deployment.kubernetes.io/revision": "3"},
+		},
+	}
+	replicaSet := func(name string, revision int, image string) kubernetes.ReplicaSet {
+		return kubernetes.NewReplicaSet(&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ping",
+				UID:       types.UID(name),
+				Annotations: map[string]string{
+					"deployment.kubernetes.io/revision": fmt.Sprint(revision),
+					"kubernetes.io/change-cause":        "deploy " + image,
+				},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &isController},
+				},
+			},
+			Spec: appsv1.ReplicaSetSpec{
+				Template: apiv1.PodTemplateSpec{
+					Spec: apiv1.PodSpec{Containers: []apiv1.Container{{Image: image}}},
+				},
+			},
+		})
+	}
+	mockK8s := newMockClient()
+	mockK8s.deployments = []kubernetes.Deployment{kubernetes.NewDeployment(deployment)}
+	mockK8s.replicaSets = []kubernetes.ReplicaSet{
+		replicaSet("web-1", 1, "web:1"),
+		replicaSet("web-3", 3, "web:3"),
+		replicaSet("web-2", 2, "web:2"),
+	}
+	mockK8s.statefulSets = []kubernetes.StatefulSet{kubernetes.NewStatefulSet(&appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "ping", UID: "db-uid"},
+	})}
+	hr := controls.NewDefaultHandlerRegistry()
+	rpt, _ := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, hr, "", 0).Report()
+
+	deploymentID := report.MakeDeploymentNodeID("web-uid")
+	node, ok := rpt.Deployment.Nodes[deploymentID]
+	if !ok {
+		t.Fatalf("Expected the deployment to be reported")
+	}
+	rows := node.ExtractMulticolumnTable(rpt.Deployment.TableTemplates[kubernetes.RolloutHistoryPrefix])
+	var revisions []string
+	for _, row := range rows {
+		revisions = append(revisions, row.Entries["revision"]+"="+row.Entries["images"])
+	}
+	if want := []string{"1=web:1", "2=web:2", "3=web:3"}; !reflect.DeepEqual(revisions, want) {
+		t.Errorf("Expected rollout history %v, got %v", want, revisions)
+	}
+	for _, control := range []string{kubernetes.SetReplicas, kubernetes.RestartRollout, kubernetes.RollbackDeployment} {
+		if _, ok := node.LatestControls.Lookup(control); !ok {
+			t.Errorf("Expected the deployment to have the %s control", control)
+		}
+	}
+
+	statefulSetID := report.MakeStatefulSetNodeID("db-uid")
+	for _, req := range []xfer.Request{
+		{NodeID: deploymentID, Control: kubernetes.RestartRollout},
+		{NodeID: statefulSetID, Control: kubernetes.SetReplicas, ControlArgs: map[string]string{"replicas": "5"}},
+		{NodeID: deploymentID, Control: kubernetes.RollbackDeployment},
+		{NodeID: deploymentID, Control: kubernetes.RollbackDeployment, ControlArgs: map[string]string{"revision": "1"}},
+	} {
+		if resp := hr.HandleControlRequest(req); resp.Error != "" {
+			t.Fatalf("Unexpected error from %s: %s", req.Control, resp.Error)
+		}
+	}
+	want := []string{
+		"restart Deployment ping/web",
+		"scale StatefulSet ping/db=5",
+		"rollback ping/web to web-2",
+		"rollback ping/web to web-1",
+	}
+	if !reflect.DeepEqual(mockK8s.rollouts, want) {
+		t.Errorf("Expected %v, got %v", want, mockK8s.rollouts)
+	}
+
+	for _, req := range []xfer.Request{
+		{NodeID: statefulSetID, Control: kubernetes.SetReplicas},
+		{NodeID: statefulSetID, Control: kubernetes.SetReplicas, ControlArgs: map[string]string{"replicas": "-1"}},
+		{NodeID: deploymentID, Control: kubernetes.RollbackDeployment, ControlArgs: map[string]string{"revision": "3"}},
+		{NodeID: deploymentID, Control: kubernetes.RollbackDeployment, ControlArgs: map[string]string{"revision": "7"}},
+	} {
+		if resp := hr.HandleControlRequest(req); resp.Error == "" {
+			t.Errorf("Expected %s with %v to fail", req.Control, req.ControlArgs)
+		}
+	}
+}
+
+func TestReporterCustomResources(t *testing.T) {
+	configs, err := kubernetes.ParseCustomResourceConfigs([]byte(`
+- group: kafka.strimzi.io
+  version: v1beta2
+  resource: kafkas
+  kind: Kafka
+  label: Kafka cluster
+  labelPath: '{.spec.kafka.version}'
+  statusPath: '{.status.conditions[?(@.type=="Ready")].status}'
+  parents:
+  - kind: Deployment
+- group: kafka.strimzi.io
+  version: v1beta2
+  resource: kafkatopics
+  kind: KafkaTopic
+  namePath: '{.spec.topicName}'
+  parents:
+  - kind: Kafka
+    namePath: '{.metadata.labels.strimzi\.io/cluster}'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	customResource := func(kind, name, uid string, object map[string]interface{}, owners ...metav1.OwnerReference) kubernetes.CustomResource {
+		u := &unstructured.Unstructured{Object: object}
+		u.SetKind(kind)
+		u.SetName(name)
+		u.SetNamespace("kafka")
+		u.SetUID(types.UID(uid))
+		u.SetOwnerReferences(owners)
+		return kubernetes.NewCustomResource(u)
+	}
+	kafka := customResource("Kafka", "my-cluster", "kafka-uid", map[string]interface{}{
+		"spec":   map[string]interface{}{"kafka": map[string]interface{}{"version": "3.0.0"}},
+		"status": map[string]interface{}{"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}}},
+	}, metav1.OwnerReference{Kind: "Deployment", Name: "operator", UID: types.UID("deployment-uid")})
+	topic := customResource("KafkaTopic", "topic-resource", "topic-uid", map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"strimzi.io/cluster": "my-cluster"},
+		},
+		"spec": map[string]interface{}{"topicName": "orders"},
+	})
+
+	mockK8s := newMockClient()
+	mockK8s.customResources = map[schema.GroupVersionResource][]kubernetes.CustomResource{
+		configs[0].GroupVersionResource(): {kafka},
+		configs[1].GroupVersionResource(): {topic},
+	}
+	reporter := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, controls.NewDefaultHandlerRegistry(), "", 0)
+	reporter.ReportCustomResources(configs)
+	rpt, err := reporter.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kafkaTopology, ok := rpt.Topology(configs[0].Topology())
+	if !ok || kafkaTopology.Label != "Kafka cluster" {
+		t.Fatalf("Expected the Kafka cluster topology, got %v", kafkaTopology)
+	}
+	kafkaID := report.MakeCustomResourceNodeID(configs[0].Topology(), "kafka-uid")
+	node := kafkaTopology.Nodes[kafkaID]
+	for key, want := range map[string]string{
+		kubernetes.Name:                "my-cluster",
+		kubernetes.CustomResourceLabel: "3.0.0",
+		kubernetes.Status:              "True",
+		kubernetes.NodeType:            "Kafka",
+	} {
+		if have, _ := node.Latest.Lookup(key); have != want {
+			t.Errorf("Expected %s of the Kafka cluster to be %q, got %q", key, want, have)
+		}
+	}
+	if have, _ := node.Parents.Lookup(report.Deployment); !have.Equal(report.MakeStringSet(report.MakeDeploymentNodeID("deployment-uid"))) {
+		t.Errorf("Expected the Kafka cluster to be owned by its deployment, got %v", have)
+	}
+
+	topicTopology, _ := rpt.Topology(configs[1].Topology())
+	node = topicTopology.Nodes[report.MakeCustomResourceNodeID(configs[1].Topology(), "topic-uid")]
+	if have, _ := node.Latest.Lookup(kubernetes.CustomResourceName); have != "orders" {
+		t.Errorf("Expected the topic to be named after its spec, got %q", have)
+	}
+	if have, _ := node.Parents.Lookup(configs[0].Topology()); !have.Equal(report.MakeStringSet(kafkaID)) {
+		t.Errorf("Expected the topic to belong to the Kafka cluster, got %v", have)
+	}
+
+	if _, err := kubernetes.ParseCustomResourceConfigs([]byte(`[{group: a, version: v1, resource: b, kind: B, statusPath: "{.status"}]`)); err == nil {
+		t.Errorf("Expected an invalid JSONPath to be rejected")
+	}
+}
+
+func TestReporterGenericControllers(t *testing.T) {
+	owner := func(kind, name, uid string, owners ...metav1.OwnerReference) kubernetes.CustomResource {
+		u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		u.SetAPIVersion("argoproj.io/v1alpha1")
+		u.SetKind(kind)
+		u.SetName(name)
+		u.SetNamespace("ping")
+		u.SetUID(types.UID(uid))
+		u.SetOwnerReferences(owners)
+		return kubernetes.NewCustomResource(u)
+	}
+	ownerRef := func(kind, name, uid string) metav1.OwnerReference {
+		controller := true
+		return metav1.OwnerReference{Kind: kind, Name: name, UID: types.UID(uid), Controller: &controller}
+	}
+	rolloutPod, daemonSetPod := apiPod1, apiPod2
+	rolloutPod.OwnerReferences = []metav1.OwnerReference{ownerRef("ReplicaSet", "pong-5d8f", "replicaset-uid")}
+	daemonSetPod.OwnerReferences = []metav1.OwnerReference{ownerRef("DaemonSet", "pong", "daemonset-uid")}
+
+	mockK8s := newMockClient()
+	mockK8s.pods = []kubernetes.Pod{kubernetes.NewPod(&rolloutPod), kubernetes.NewPod(&daemonSetPod)}
+	mockK8s.owners = map[types.UID]kubernetes.CustomResource{
+		"replicaset-uid": owner("ReplicaSet", "pong-5d8f", "replicaset-uid", ownerRef("Rollout", "pong", "rollout-uid")),
+		"rollout-uid":    owner("Rollout", "pong", "rollout-uid"),
+	}
+	reporter := kubernetes.NewReporter(mockK8s, nil, "probe-id", "foo", nil, controls.NewDefaultHandlerRegistry(), "", 0)
+	rpt, err := reporter.Report()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rolloutID := report.MakeGenericControllerNodeID("rollout-uid")
+	node, ok := rpt.GenericController.Nodes[rolloutID]
+	if !ok || len(rpt.GenericController.Nodes) != 1 {
+		t.Fatalf("Expected the rollout to be the only generic controller, got %v", rpt.GenericController.Nodes)
+	}
+	for key, want := range map[string]string{
+		kubernetes.Name:       "pong",
+		kubernetes.Namespace:  "ping",
+		kubernetes.NodeType:   "Rollout",
+		kubernetes.APIVersion: "argoproj.io/v1alpha1",
+	} {
+		if have, _ := node.Latest.Lookup(key); have != want {
+			t.Errorf("Expected %s of the rollout to be %q, got %q", key, want, have)
+		}
+	}
+	pod := rpt.Pod.Nodes[report.MakePodNodeID(pod1UID)]
+	if have, _ := pod.Parents.Lookup(report.GenericController); !have.Equal(report.MakeStringSet(rolloutID)) {
+		t.Errorf("Expected the pod to belong to the rollout, got %v", have)
+	}
+	if have, ok := rpt.Pod.Nodes[report.MakePodNodeID(pod2UID)].Parents.Lookup(report.GenericController); ok {
+		t.Errorf("Expected the pod of the daemonset to have no generic controller, got %v", have)
+	}
+	if want := []string{"ReplicaSet/pong-5d8f", "Rollout/pong"}; !reflect.DeepEqual(mockK8s.ownerLookups, want) {
+		t.Errorf("Expected owners %v to be looked up, got %v", want, mockK8s.ownerLookups)
+	}
+}
+
 func TestTagger(t *testing.T) {
 	rpt := report.MakeReport()
 	rpt.Container.AddNode(report.MakeNodeWith("container1", map[string]string{
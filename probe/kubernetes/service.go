@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/weaveworks/scope/report"
 
@@ -11,7 +12,8 @@ import (
 
 // These constants are keys used in node metadata
 const (
-	PublicIP = report.KubernetesPublicIP
+	PublicIP              = report.KubernetesPublicIP
+	LoadBalancerAddresses = report.KubernetesLoadBalancerAddresses
 )
 
 // Service represents a Kubernetes service
@@ -56,6 +58,9 @@ func (s *service) GetNode(probeID string) report.Node {
 	if s.Spec.LoadBalancerIP != "" {
 		latest[PublicIP] = s.Spec.LoadBalancerIP
 	}
+	if addresses := s.loadBalancerAddresses(); len(addresses) > 0 {
+		latest[LoadBalancerAddresses] = strings.Join(addresses, ",")
+	}
 	if len(s.Spec.Ports) != 0 {
 		portStr := ""
 		for _, p := range s.Spec.Ports {
@@ -68,6 +73,20 @@ func (s *service) GetNode(probeID string) report.Node {
 		WithLatestActiveControls(Describe)
 }
 
+// loadBalancerAddresses returns the addresses the service is reached at
+// from outside of the cluster, as ingresses report them in their status.
+func (s *service) loadBalancerAddresses() []string {
+	addresses := append([]string{}, s.Spec.ExternalIPs...)
+	for _, lb := range s.Status.LoadBalancer.Ingress {
+		if lb.IP != "" {
+			addresses = append(addresses, lb.IP)
+		} else if lb.Hostname != "" {
+			addresses = append(addresses, lb.Hostname)
+		}
+	}
+	return addresses
+}
+
 func (s *service) ClusterIP() string {
 	return s.Spec.ClusterIP
 }
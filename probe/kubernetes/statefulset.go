@@ -52,5 +52,5 @@ func (s *statefulSet) GetNode(probeID string) report.Node {
 	}
 	return s.MetaNode(report.MakeStatefulSetNodeID(s.UID())).
 		WithLatests(latests).
-		WithLatestActiveControls(Describe)
+		WithLatestActiveControls(SetReplicas, RestartRollout, Describe)
 }
@@ -1,6 +1,8 @@
 package kubernetes
 
 import (
+	"strconv"
+
 	"github.com/weaveworks/scope/report"
 	storagev1 "k8s.io/api/storage/v1"
 )
@@ -10,6 +12,7 @@ type StorageClass interface {
 	Meta
 	GetNode(probeID string) report.Node
 	GetProvisioner() string
+	AllowsExpansion() bool
 }
 
 // storageClass represents kubernetes storage classes
@@ -28,12 +31,19 @@ func (p *storageClass) GetProvisioner() string {
 	return p.Provisioner
 }
 
+// AllowsExpansion tells whether the volumes of the storage class can be
+// expanded by editing their claims
+func (p *storageClass) AllowsExpansion() bool {
+	return p.AllowVolumeExpansion != nil && *p.AllowVolumeExpansion
+}
+
 // GetNode returns StorageClass as Node
 func (p *storageClass) GetNode(probeID string) report.Node {
 	return p.MetaNode(report.MakeStorageClassNodeID(p.UID())).WithLatests(map[string]string{
 		NodeType:              "Storage Class",
 		Name:                  p.GetName(),
 		Provisioner:           p.GetProvisioner(),
+		VolumeExpansion:       strconv.FormatBool(p.AllowsExpansion()),
 		report.ControlProbeID: probeID,
 	}).WithLatestActiveControls(Describe)
 }
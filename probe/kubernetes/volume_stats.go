@@ -0,0 +1,53 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/weaveworks/common/mtime"
+
+	"github.com/weaveworks/scope/report"
+)
+
+// VolumeStatsReporter reports the usage statistics kubelet keeps of the
+// persistent volume claims mounted on its node, so it runs in host probes.
+// Kubelet only knows claims by namespace and name, so the statistics are
+// reported on nodes of their own in the PersistentVolumeClaim topology,
+// which the app attaches to the claims and their volumes.
+type VolumeStatsReporter struct {
+	kubeletPort uint
+}
+
+// NewVolumeStatsReporter makes a new VolumeStatsReporter, reading the
+// statistics from the kubelet listening on localhost at kubeletPort.
+func NewVolumeStatsReporter(kubeletPort uint) *VolumeStatsReporter {
+	return &VolumeStatsReporter{kubeletPort: kubeletPort}
+}
+
+// Name of this reporter, for metrics gathering
+func (VolumeStatsReporter) Name() string { return "K8s volume stats" }
+
+// Report implements Reporter
+func (r *VolumeStatsReporter) Report() (report.Report, error) {
+	rpt := report.MakeReport()
+	stats, err := GetVolumeStats(fmt.Sprintf("127.0.0.1:%d", r.kubeletPort))
+	if err != nil {
+		log.Debugf("Cannot obtain volume stats from kubelet: %v", err)
+		return rpt, nil
+	}
+	rpt.PersistentVolumeClaim = rpt.PersistentVolumeClaim.WithMetricTemplates(PersistentVolumeClaimMetricTemplates)
+	rpt.PersistentVolume = rpt.PersistentVolume.WithMetricTemplates(PersistentVolumeMetricTemplates)
+	now := mtime.Now()
+	for claim, s := range stats {
+		namespace, name := claim, ""
+		if i := strings.Index(claim, "/"); i >= 0 {
+			namespace, name = claim[:i], claim[i+1:]
+		}
+		rpt.PersistentVolumeClaim.AddNode(report.MakeNodeWith(report.MakeVolumeClaimStatsNodeID(claim), map[string]string{
+			Namespace: namespace,
+			Name:      name,
+		}).WithMetrics(s.Metrics(now)))
+	}
+	return rpt, nil
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
 	"fmt"
 	"math/rand"
 	"net/http"
@@ -55,7 +58,7 @@ func registerAppMetrics() {
 var registerAppMetricsOnce sync.Once
 
 // Router creates the mux for all the various app components.
-func router(collector app.Collector, controlRouter app.ControlRouter, pipeRouter app.PipeRouter, externalUI bool, capabilities map[string]bool, metricsGraphURL string) http.Handler {
+func router(collector app.Collector, controlRouter app.ControlRouter, pipeRouter app.PipeRouter, auditLog *app.AuditLog, rbac *app.RBAC, externalUI bool, capabilities map[string]bool, metricsGraphURL string, topologyMetrics, topologyMetricEdges bool) http.Handler {
 	router := mux.NewRouter().SkipClean(true)
 
 	// We pull in the http.DefaultServeMux to get the pprof routes
@@ -63,10 +66,18 @@ func router(collector app.Collector, controlRouter app.ControlRouter, pipeRouter
 	router.Path("/metrics").Handler(prometheus.Handler())
 
 	app.RegisterReportPostHandler(collector, router)
-	app.RegisterControlRoutes(router, controlRouter)
-	app.RegisterPipeRoutes(router, pipeRouter)
-	app.RegisterTopologyRoutes(router, app.WebReporter{Reporter: collector, MetricsGraphURL: metricsGraphURL}, capabilities)
-	app.RegisterAdminRoutes(router, collector)
+	app.RegisterControlRoutes(router, controlRouter, collector, auditLog)
+	app.RegisterPipeRoutes(router, pipeRouter, auditLog)
+	app.RegisterAuditRoutes(router, auditLog)
+	var reporter app.Reporter = collector
+	if rbac != nil {
+		reporter = app.AccessReporter{Reporter: collector}
+	}
+	app.RegisterTopologyRoutes(router, app.WebReporter{Reporter: reporter, MetricsGraphURL: metricsGraphURL}, capabilities)
+	app.RegisterAdminRoutes(router, reporter)
+	if topologyMetrics {
+		app.RegisterTopologyMetricsRoute(router, reporter, topologyMetricEdges)
+	}
 
 	uiHandler := http.FileServer(GetFS(externalUI))
 	router.PathPrefix("/ui").Name("static").Handler(
@@ -81,12 +92,15 @@ func router(collector app.Collector, controlRouter app.ControlRouter, pipeRouter
 		},
 		middleware.Tracer{},
 	)
+	if rbac != nil {
+		middlewares = middleware.Merge(middlewares, rbac)
+	}
 
 	return middlewares.Wrap(router)
 }
 
 func collectorFactory(userIDer multitenant.UserIDer, collectorURL, s3URL, natsHostname string,
-	memcacheConfig multitenant.MemcacheConfig, window time.Duration, maxTopNodes int, createTables bool) (app.Collector, error) {
+	memcacheConfig multitenant.MemcacheConfig, historyConfig app.HistoryConfig, window time.Duration, maxTopNodes int, createTables bool) (app.Collector, error) {
 	if collectorURL == "local" {
 		return app.NewCollector(window), nil
 	}
@@ -99,6 +113,9 @@ func collectorFactory(userIDer multitenant.UserIDer, collectorURL, s3URL, natsHo
 	switch parsed.Scheme {
 	case "file":
 		return app.NewFileCollector(parsed.Path, window)
+	case "history":
+		historyConfig.Path = parsed.Path
+		return app.NewHistoryCollector(historyConfig, window)
 	case "dynamodb":
 		s3, err := url.Parse(s3URL)
 		if err != nil {
@@ -206,6 +223,61 @@ func pipeRouterFactory(userIDer multitenant.UserIDer, pipeRouterURL, consulInf s
 	return nil, fmt.Errorf("Invalid pipe router '%s'", pipeRouterURL)
 }
 
+func auditLogFactory(userIDer multitenant.UserIDer, sinkURLs string, capacity int) (*app.AuditLog, error) {
+	var sinks []app.AuditSink
+	for _, sinkURL := range strings.Split(sinkURLs, ",") {
+		if sinkURL = strings.TrimSpace(sinkURL); sinkURL == "" {
+			continue
+		}
+		sink, err := app.NewAuditSink(sinkURL)
+		if err != nil {
+			for _, s := range sinks {
+				s.Close()
+			}
+			return nil, err
+		}
+		log.Infof("Writing audit records to %s", sinkURL)
+		sinks = append(sinks, sink)
+	}
+	return app.NewAuditLog(capacity, userIDer, sinks...), nil
+}
+
+// probeCredentials tells whether requests carry the credentials probes
+// authenticate with when users log in with OIDC: a client certificate,
+// those of basic authentication, or a token. Client certificates only
+// authenticate the requests of probes.
+func probeCredentials(flags appFlags) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		if _, ok := app.ClientCertIdentity(r); ok {
+			return app.IsProbeRoute(r)
+		}
+		if flags.oidcProbeToken != "" {
+			token := "Scope-Probe token=" + flags.oidcProbeToken
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(token)) == 1 {
+				return true
+			}
+		}
+		if flags.basicAuth {
+			username, password, ok := r.BasicAuth()
+			return ok &&
+				subtle.ConstantTimeCompare([]byte(username), []byte(flags.username)) == 1 &&
+				subtle.ConstantTimeCompare([]byte(password), []byte(flags.password)) == 1
+		}
+		return false
+	}
+}
+
+func rbacFactory(policyPath, userHeader, groupsHeader string) (*app.RBAC, error) {
+	if policyPath == "" {
+		return nil, nil
+	}
+	policy, err := app.LoadPolicy(policyPath)
+	if err != nil {
+		return nil, err
+	}
+	return &app.RBAC{Policy: policy, UserHeader: userHeader, GroupsHeader: groupsHeader}, nil
+}
+
 // Main runs the app
 func appMain(flags appFlags) {
 	setLogLevel(flags.logLevel)
@@ -239,6 +311,10 @@ func appMain(flags appFlags) {
 			Service:          flags.memcachedService,
 			CompressionLevel: flags.memcachedCompressionLevel,
 		},
+		app.HistoryConfig{
+			Retention:  flags.historyRetention,
+			Resolution: flags.historyResolution,
+		},
 		flags.window, flags.maxTopNodes, flags.awsCreateTables)
 	if err != nil {
 		log.Fatalf("Error creating collector: %v", err)
@@ -267,6 +343,22 @@ func appMain(flags appFlags) {
 		return
 	}
 
+	auditLog, err := auditLogFactory(userIDer, flags.auditSinks, flags.auditRecords)
+	if err != nil {
+		log.Fatalf("Error creating audit log: %v", err)
+		return
+	}
+	defer auditLog.Close()
+
+	rbac, err := rbacFactory(flags.rbacPolicy, flags.rbacUserHeader, flags.rbacGroupsHeader)
+	if err != nil {
+		log.Fatalf("Error loading access control policy: %v", err)
+		return
+	}
+	if rbac != nil {
+		log.Infof("Role-based access control enabled with %s", flags.rbacPolicy)
+	}
+
 	// Periodically try and register our IP address in WeaveDNS.
 	if flags.weaveEnabled && flags.weaveHostname != "" {
 		weave, err := newWeavePublisher(
@@ -283,7 +375,7 @@ func appMain(flags appFlags) {
 		xfer.HistoricReportsCapability: collector.HasHistoricReports(),
 	}
 	logger := logging.Logrus(log.StandardLogger())
-	handler := router(collector, controlRouter, pipeRouter, flags.externalUI, capabilities, flags.metricsGraphURL)
+	handler := router(collector, controlRouter, pipeRouter, auditLog, rbac, flags.externalUI, capabilities, flags.metricsGraphURL, flags.topologyMetrics, flags.topologyMetricEdges)
 	if flags.logHTTP {
 		handler = middleware.Log{
 			Log:               logger,
@@ -291,13 +383,48 @@ func appMain(flags appFlags) {
 		}.Wrap(handler)
 	}
 
-	if flags.basicAuth {
+	if flags.oidcIssuerURL != "" {
+		oidc, err := app.NewOIDC(context.Background(), app.OIDCConfig{
+			IssuerURL:        flags.oidcIssuerURL,
+			ClientID:         flags.oidcClientID,
+			ClientSecret:     flags.oidcClientSecret,
+			AppURL:           flags.oidcAppURL,
+			Scopes:           strings.Split(flags.oidcScopes, ","),
+			UsernameClaim:    flags.oidcUsernameClaim,
+			GroupsClaim:      flags.oidcGroupsClaim,
+			CookieSecret:     flags.oidcCookieSecret,
+			SessionTTL:       flags.oidcSessionTTL,
+			ProbeCredentials: probeCredentials(flags),
+		})
+		if err != nil {
+			log.Fatalf("Error setting up OIDC login: %v", err)
+			return
+		}
+		log.Infof("OIDC login enabled with %s", flags.oidcIssuerURL)
+		handler = oidc.Wrap(handler)
+	} else if flags.basicAuth {
 		log.Infof("Basic authentication enabled")
-		handler = httpauth.SimpleBasicAuth(flags.username, flags.password)(handler)
+		handler = httpauth.SimpleBasicAuth(flags.username, flags.password)(app.BasicAuthIdentity(handler))
 	} else {
 		log.Infof("Basic authentication disabled")
 	}
 
+	var tlsConfig *tls.Config
+	if flags.tlsCert != "" {
+		tlsConfig, err = app.ServerTLSConfig(flags.tlsCert, flags.tlsKey, flags.tlsClientCA)
+		if err != nil {
+			log.Fatalf("Error loading TLS configuration: %v", err)
+			return
+		}
+		if flags.tlsClientCA != "" {
+			log.Infof("Probes must present client certificates signed by %s", flags.tlsClientCA)
+			handler = app.ProbeCertAuth{}.Wrap(handler)
+		}
+	} else if flags.tlsClientCA != "" {
+		log.Fatalf("-app.tls.client-ca needs -app.tls.cert and -app.tls.key")
+		return
+	}
+
 	server := &graceful.Server{
 		// we want to manage the stop condition ourselves below
 		NoSignalHandling: true,
@@ -311,7 +438,13 @@ func appMain(flags appFlags) {
 	}
 	go func() {
 		log.Infof("listening on %s", flags.listen)
-		if err := server.ListenAndServe(); err != nil {
+		var err error
+		if tlsConfig != nil {
+			err = server.ListenAndServeTLSConfig(tlsConfig)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil {
 			log.Error(err)
 		}
 	}()
@@ -31,11 +31,17 @@ var (
 	probeTokenFlag         = "probe.token"
 	kubernetesPasswordFlag = "probe.kubernetes.password"
 	kubernetesTokenFlag    = "probe.kubernetes.token"
+	oidcClientSecretFlag   = "app.oidc.client-secret"
+	oidcCookieSecretFlag   = "app.oidc.cookie-secret"
+	oidcProbeTokenFlag     = "app.oidc.probe-token"
 	sensitiveFlags         = []string{
 		serviceTokenFlag,
 		probeTokenFlag,
 		kubernetesPasswordFlag,
 		kubernetesTokenFlag,
+		oidcClientSecretFlag,
+		oidcCookieSecretFlag,
+		oidcProbeTokenFlag,
 	}
 	colonFinder         = regexp.MustCompile(`[^\\](:)`)
 	unescapeBackslashes = regexp.MustCompile(`\\(.)`)
@@ -102,6 +108,9 @@ type probeFlags struct {
 	spyInterval            time.Duration
 	pluginsRoot            string
 	insecure               bool
+	tlsCert                string
+	tlsKey                 string
+	tlsCA                  string
 	logPrefix              string
 	logLevel               string
 	resolver               string
@@ -117,6 +126,7 @@ type probeFlags struct {
 	spyProcs    bool // Associate endpoints with processes (must be root)
 	procEnabled bool // Produce process topology & process nodes in endpoint
 	useEbpfConn bool // Enable connection tracking with eBPF
+	trackUDP    bool // Also track UDP flows
 	procRoot    string
 
 	dockerEnabled  bool
@@ -126,11 +136,12 @@ type probeFlags struct {
 	criEnabled  bool
 	criEndpoint string
 
-	kubernetesEnabled      bool
-	kubernetesRole         string
-	kubernetesNodeName     string
-	kubernetesClientConfig kubernetes.ClientConfig
-	kubernetesKubeletPort  uint
+	kubernetesEnabled         bool
+	kubernetesRole            string
+	kubernetesNodeName        string
+	kubernetesClientConfig    kubernetes.ClientConfig
+	kubernetesKubeletPort     uint
+	kubernetesCustomResources string
 
 	ecsEnabled       bool
 	ecsCacheSize     int
@@ -173,9 +184,31 @@ type appFlags struct {
 	memcachedService          string
 	memcachedExpiration       time.Duration
 	memcachedCompressionLevel int
+	historyRetention          time.Duration
+	historyResolution         time.Duration
 	userIDHeader              string
+	auditSinks                string
+	auditRecords              int
+	rbacPolicy                string
+	rbacUserHeader            string
+	rbacGroupsHeader          string
+	oidcIssuerURL             string
+	oidcClientID              string
+	oidcClientSecret          string
+	oidcAppURL                string
+	oidcScopes                string
+	oidcUsernameClaim         string
+	oidcGroupsClaim           string
+	oidcCookieSecret          string
+	oidcSessionTTL            time.Duration
+	oidcProbeToken            string
+	tlsCert                   string
+	tlsKey                    string
+	tlsClientCA               string
 	externalUI                bool
 	metricsGraphURL           string
+	topologyMetrics           bool
+	topologyMetricEdges       bool
 	serviceName               string
 
 	blockProfileRate int
@@ -293,17 +326,21 @@ func setupFlags(flags *flags) {
 	flag.BoolVar(&flags.probe.noEnvironmentVariables, "probe.omit.env-vars", true, "Disable collection of environment variables")
 
 	flag.BoolVar(&flags.probe.insecure, "probe.insecure", false, "(SSL) explicitly allow \"insecure\" SSL connections and transfers")
+	flag.StringVar(&flags.probe.tlsCert, "probe.tls.cert", "", "(SSL) client certificate to authenticate with to the app, whose common name identifies the probe")
+	flag.StringVar(&flags.probe.tlsKey, "probe.tls.key", "", "(SSL) key of the client certificate")
+	flag.StringVar(&flags.probe.tlsCA, "probe.tls.ca", "", "(SSL) CA certificates to verify the app with, instead of those of the system")
 	flag.StringVar(&flags.probe.resolver, "probe.resolver", "", "IP address & port of resolver to use.  Default is to use system resolver.")
 	flag.StringVar(&flags.probe.logPrefix, "probe.log.prefix", "<probe>", "prefix for each log line")
 	flag.StringVar(&flags.probe.logLevel, "probe.log.level", "info", "logging threshold level: debug|info|warn|error|fatal|panic")
 
 	// Proc & endpoint
-	flag.BoolVar(&flags.probe.useConntrack, "probe.conntrack", true, "also use conntrack to track connections")
+	flag.BoolVar(&flags.probe.useConntrack, "probe.conntrack", true, "also use conntrack to track connections (connection throughput also needs conntrack accounting: sysctl net.netfilter.nf_conntrack_acct=1)")
 	flag.IntVar(&flags.probe.conntrackBufferSize, "probe.conntrack.buffersize", 4096*1024, "conntrack buffer size")
 	flag.BoolVar(&flags.probe.spyProcs, "probe.proc.spy", true, "associate endpoints with processes (needs root)")
 	flag.StringVar(&flags.probe.procRoot, "probe.proc.root", "/proc", "location of the proc filesystem")
 	flag.BoolVar(&flags.probe.procEnabled, "probe.processes", true, "produce process topology & include procspied connections")
-	flag.BoolVar(&flags.probe.useEbpfConn, "probe.ebpf.connections", true, "enable connection tracking with eBPF")
+	flag.BoolVar(&flags.probe.useEbpfConn, "probe.ebpf.connections", true, "enable connection tracking with eBPF (connection throughput still comes from conntrack accounting)")
+	flag.BoolVar(&flags.probe.trackUDP, "probe.udp", false, "also track UDP flows, from conntrack and /proc/net/udp")
 
 	// Docker
 	flag.BoolVar(&flags.probe.dockerEnabled, "probe.docker", false, "collect Docker-related attributes for processes")
@@ -332,6 +369,7 @@ func setupFlags(flags *flags) {
 	flag.StringVar(&flags.probe.kubernetesClientConfig.Username, "probe.kubernetes.username", "", "Username for basic authentication to the API server")
 	flag.StringVar(&flags.probe.kubernetesNodeName, "probe.kubernetes.node-name", "", "Name of this node, for filtering pods")
 	flag.UintVar(&flags.probe.kubernetesKubeletPort, "probe.kubernetes.kubelet-port", 10255, "Node-local TCP port for contacting kubelet (zero to disable)")
+	flag.StringVar(&flags.probe.kubernetesCustomResources, "probe.kubernetes.custom-resources", "", "Path to a YAML or JSON file listing the custom resources to report (the probe needs get, list and watch on them)")
 
 	// AWS ECS
 	flag.BoolVar(&flags.probe.ecsEnabled, "probe.ecs", false, "Collect ecs-related attributes for containers on this node")
@@ -364,7 +402,7 @@ func setupFlags(flags *flags) {
 	flag.Var(&flags.containerLabelFilterFlags, "app.container-label-filter", "Add container label-based view filter, specified as title:label. Multiple flags are accepted. Example: --app.container-label-filter='Database Containers:role=db'")
 	flag.Var(&flags.containerLabelFilterFlagsExclude, "app.container-label-filter-exclude", "Add container label-based view filter that excludes containers with the given label, specified as title:label. Multiple flags are accepted. Example: --app.container-label-filter-exclude='Database Containers:role=db'")
 
-	flag.StringVar(&flags.app.collectorURL, "app.collector", "local", "Collector to use (local, dynamodb, or file/directory)")
+	flag.StringVar(&flags.app.collectorURL, "app.collector", "local", "Collector to use (local, dynamodb, file/directory, or history:///path/to/directory)")
 	flag.StringVar(&flags.app.s3URL, "app.collector.s3", "local", "S3 URL to use (when collector is dynamodb)")
 	flag.StringVar(&flags.app.controlRouterURL, "app.control.router", "local", "Control router to use (local or sqs)")
 	flag.DurationVar(&flags.app.controlRPCTimeout, "app.control.rpctimeout", time.Minute, "Timeout for control RPC")
@@ -375,9 +413,31 @@ func setupFlags(flags *flags) {
 	flag.DurationVar(&flags.app.memcachedExpiration, "app.memcached.expiration", 2*15*time.Second, "How long reports stay in the memcache.")
 	flag.StringVar(&flags.app.memcachedService, "app.memcached.service", "memcached", "SRV service used to discover memcache servers.")
 	flag.IntVar(&flags.app.memcachedCompressionLevel, "app.memcached.compression", gzip.DefaultCompression, "How much to compress reports stored in memcached.")
+	flag.DurationVar(&flags.app.historyRetention, "app.history.retention", 24*time.Hour, "How long to keep historic reports (when collector is history, 0 to keep forever)")
+	flag.DurationVar(&flags.app.historyResolution, "app.history.resolution", time.Minute, "Historic reports received within this interval are merged and stored as one (when collector is history)")
 	flag.StringVar(&flags.app.userIDHeader, "app.userid.header", "", "HTTP header to use as userid")
+	flag.StringVar(&flags.app.auditSinks, "app.audit.sinks", "", "Comma-separated sinks to write audit records of controls and pipes to (file:///path/to/audit.jsonl, syslog://[host:port], or an http(s) webhook URL)")
+	flag.IntVar(&flags.app.auditRecords, "app.audit.records", 1000, "How many of the latest audit records to keep in memory, to be queried by admins at /api/audit")
+	flag.StringVar(&flags.app.rbacPolicy, "app.rbac.policy", "", "File of the role-based access control policy deciding which controls users may use, and which Kubernetes namespaces they may see")
+	flag.StringVar(&flags.app.rbacUserHeader, "app.rbac.user-header", "", "HTTP header set by a trusted authenticating proxy to the name of the user (defaults to the basic authentication user)")
+	flag.StringVar(&flags.app.rbacGroupsHeader, "app.rbac.groups-header", "", "HTTP header set by a trusted authenticating proxy to the comma-separated groups of the user")
+	flag.StringVar(&flags.app.oidcIssuerURL, "app.oidc.issuer-url", "", "URL of the OpenID Connect issuer to log users in with, instead of basic authentication")
+	flag.StringVar(&flags.app.oidcClientID, "app.oidc.client-id", "", "OpenID Connect client ID of the app")
+	flag.StringVar(&flags.app.oidcClientSecret, oidcClientSecretFlag, "", "OpenID Connect client secret of the app")
+	flag.StringVar(&flags.app.oidcAppURL, "app.oidc.app-url", "", "URL users reach the app at, which the OpenID Connect issuer redirects them back to at /oidc/callback")
+	flag.StringVar(&flags.app.oidcScopes, "app.oidc.scopes", "openid,profile,email", "Comma-separated OpenID Connect scopes to request")
+	flag.StringVar(&flags.app.oidcUsernameClaim, "app.oidc.username-claim", "email", "ID token claim naming users")
+	flag.StringVar(&flags.app.oidcGroupsClaim, "app.oidc.groups-claim", "groups", "ID token claim listing the groups of users")
+	flag.StringVar(&flags.app.oidcCookieSecret, oidcCookieSecretFlag, "", "Secret to sign and encrypt session cookies with (random if not set, logging users out on restart)")
+	flag.DurationVar(&flags.app.oidcSessionTTL, "app.oidc.session-ttl", 12*time.Hour, "How long users stay logged in")
+	flag.StringVar(&flags.app.oidcProbeToken, oidcProbeTokenFlag, "", "Token probes authenticate with (-probe.token) when users log in with OpenID Connect, besides basic authentication")
+	flag.StringVar(&flags.app.tlsCert, "app.tls.cert", "", "Certificate to serve HTTPS with, instead of HTTP")
+	flag.StringVar(&flags.app.tlsKey, "app.tls.key", "", "Key of the certificate to serve HTTPS with")
+	flag.StringVar(&flags.app.tlsClientCA, "app.tls.client-ca", "", "CA certificates of the client certificates probes must present to post reports and connect controls and pipes")
 	flag.BoolVar(&flags.app.externalUI, "app.externalUI", false, "Point to externally hosted static UI assets")
 	flag.StringVar(&flags.app.metricsGraphURL, "app.metrics-graph", "", "Enable extended metrics graph by providing a templated URL (supports :instanceID and :query). Example: --app.metrics-graph=/prom/:instanceID/notebook/new")
+	flag.BoolVar(&flags.app.topologyMetrics, "app.topology-metrics", false, "Expose the latest metrics and edges of rendered topology nodes for Prometheus at /api/metrics")
+	flag.BoolVar(&flags.app.topologyMetricEdges, "app.topology-metrics.edges", false, "Also expose every edge between rendered topology nodes as a series of its own at /api/metrics (high cardinality)")
 	flag.StringVar(&flags.app.serviceName, "app.service-name", "app", "The name for this service which should be reported in instrumentation")
 
 	flag.IntVar(&flags.app.blockProfileRate, "app.block.profile.rate", 0, "If more than 0, enable block profiling. The profiler aims to sample an average of one blocking event per rate nanoseconds spent blocked.")
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"net"
 	"net/http"
@@ -124,6 +127,28 @@ func probeMain(flags probeFlags, targets []appclient.Target) {
 	)
 	log.Infof("probe starting, version %s, ID %s", version, probeID)
 
+	var (
+		clientCert *tls.Certificate
+		rootCAs    *x509.CertPool
+	)
+	if flags.tlsCert != "" {
+		cert, err := tls.LoadX509KeyPair(flags.tlsCert, flags.tlsKey)
+		if err != nil {
+			log.Fatalf("Error loading client certificate: %v", err)
+		}
+		clientCert = &cert
+	}
+	if flags.tlsCA != "" {
+		pem, err := ioutil.ReadFile(flags.tlsCA)
+		if err != nil {
+			log.Fatalf("Error loading app CA: %v", err)
+		}
+		rootCAs = x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(pem) {
+			log.Fatalf("No certificates in %s", flags.tlsCA)
+		}
+	}
+
 	handlerRegistry := controls.NewDefaultHandlerRegistry()
 	clientFactory := func(hostname string, url url.URL) (appclient.AppClient, error) {
 		token := flags.token
@@ -142,6 +167,8 @@ func probeMain(flags probeFlags, targets []appclient.Target) {
 			ProbeVersion: version,
 			ProbeID:      probeID,
 			Insecure:     flags.insecure,
+			ClientCert:   clientCert,
+			RootCAs:      rootCAs,
 		}
 		return appclient.NewAppClient(
 			probeConfig, hostname, url,
@@ -236,6 +263,7 @@ func probeMain(flags probeFlags, targets []appclient.Target) {
 			UseConntrack: flags.useConntrack,
 			WalkProc:     flags.procEnabled,
 			UseEbpfConn:  flags.useEbpfConn,
+			TrackUDP:     flags.trackUDP,
 			ProcRoot:     flags.procRoot,
 			BufferSize:   flags.conntrackBufferSize,
 			ProcessCache: processCache,
@@ -287,6 +315,13 @@ func probeMain(flags probeFlags, targets []appclient.Target) {
 			defer client.Stop()
 			reporter := kubernetes.NewReporter(client, clients, probeID, hostID, p, handlerRegistry, flags.kubernetesNodeName, flags.kubernetesKubeletPort)
 			defer reporter.Stop()
+			if flags.kubernetesCustomResources != "" {
+				if configs, err := kubernetes.LoadCustomResourceConfigs(flags.kubernetesCustomResources); err == nil {
+					reporter.ReportCustomResources(configs)
+				} else {
+					log.Errorf("Kubernetes: failed to load custom resources: %v", err)
+				}
+			}
 			p.AddReporter(reporter)
 		} else {
 			log.Errorf("Kubernetes: failed to start client: %v", err)
@@ -296,6 +331,9 @@ func probeMain(flags probeFlags, targets []appclient.Target) {
 
 	if flags.kubernetesEnabled {
 		p.AddTagger(&kubernetes.Tagger{})
+		if flags.kubernetesKubeletPort != 0 {
+			p.AddReporter(kubernetes.NewVolumeStatsReporter(flags.kubernetesKubeletPort))
+		}
 	}
 
 	if flags.ecsEnabled {
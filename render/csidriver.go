@@ -0,0 +1,45 @@
+package render
+
+import (
+	"context"
+	"strings"
+
+	"github.com/weaveworks/scope/probe/kubernetes"
+	"github.com/weaveworks/scope/report"
+)
+
+// CSIDriverRenderer is a Renderer which produces a renderable graph of
+// the CSI drivers of the cluster: the storage classes they provision
+// volumes for are linked to them, and they are linked to the hosts they
+// run on.
+var CSIDriverRenderer = ConditionalRenderer(renderKubernetesTopologies, csiDriverRenderer{})
+
+type csiDriverRenderer struct{}
+
+// Render implements Renderer
+func (csiDriverRenderer) Render(ctx context.Context, rpt report.Report) Nodes {
+	hosts := hostsByKubernetesNodeName(rpt)
+
+	nodes := report.Nodes{}
+	drivers := map[string]string{}
+	for id, n := range rpt.CSIDriver.Nodes {
+		n = n.WithTopology(report.CSIDriver)
+		name, _ := n.Latest.Lookup(kubernetes.Name)
+		drivers[name] = id
+		csiNodes, _ := n.Latest.Lookup(kubernetes.CSINodes)
+		for _, nodeName := range strings.Split(csiNodes, report.ScopeDelim) {
+			if h, ok := hosts[nodeName]; ok {
+				n = n.WithAdjacent(h.ID)
+				nodes[h.ID] = h.WithTopology(report.Host)
+			}
+		}
+		nodes[id] = n
+	}
+	for id, n := range rpt.StorageClass.Nodes {
+		provisioner, _ := n.Latest.Lookup(kubernetes.Provisioner)
+		if driverID, ok := drivers[provisioner]; ok {
+			nodes[id] = n.WithTopology(report.StorageClass).WithAdjacent(driverID)
+		}
+	}
+	return Nodes{Nodes: nodes}
+}
@@ -0,0 +1,44 @@
+package render_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/weaveworks/scope/probe/kubernetes"
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
+)
+
+func TestCSIDriverRenderer(t *testing.T) {
+	var (
+		rpt      = report.MakeReport()
+		driverID = report.MakeCSIDriverNodeID("block.csi.example.com")
+		hostA    = report.MakeHostNodeID("host-a")
+		hostB    = report.MakeHostNodeID("host-b")
+	)
+	rpt.Host.AddNode(report.MakeNodeWith(hostA, map[string]string{report.KubernetesNodeName: "node-a"}))
+	rpt.Host.AddNode(report.MakeNodeWith(hostB, map[string]string{report.KubernetesNodeName: "node-b"}))
+	rpt.CSIDriver.AddNode(kubernetes.CSIDriverInfo{
+		Name:  "block.csi.example.com",
+		Nodes: []string{"node-a"},
+	}.GetNode(""))
+	rpt.StorageClass.AddNode(report.MakeNodeWith(report.MakeStorageClassNodeID("block"), map[string]string{
+		kubernetes.Provisioner: "block.csi.example.com",
+	}))
+	rpt.StorageClass.AddNode(report.MakeNodeWith(report.MakeStorageClassNodeID("other"), map[string]string{
+		kubernetes.Provisioner: "kubernetes.io/no-provisioner",
+	}))
+
+	nodes := render.CSIDriverRenderer.Render(context.Background(), rpt).Nodes
+	if have := nodes[driverID].Adjacency; !have.Equal(report.MakeIDList(hostA)) {
+		t.Errorf("expected driver to run on %s, have %v", hostA, have)
+	}
+	if have := nodes[report.MakeStorageClassNodeID("block")].Adjacency; !have.Equal(report.MakeIDList(driverID)) {
+		t.Errorf("expected storage class to use the driver, have %v", have)
+	}
+	for _, id := range []string{hostB, report.MakeStorageClassNodeID("other")} {
+		if _, ok := nodes[id]; ok {
+			t.Errorf("expected %s not to be rendered", id)
+		}
+	}
+}
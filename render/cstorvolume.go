@@ -2,6 +2,7 @@ package render
 
 import (
 	"context"
+	"strings"
 
 	"github.com/weaveworks/scope/probe/kubernetes"
 	"github.com/weaveworks/scope/report"
@@ -59,3 +60,59 @@ func (v cStorVolumeRenderer) Render(ctx context.Context, rpt report.Report) Node
 	}
 	return Nodes{Nodes: cStorNodes}
 }
+
+// CStorVolumeConfigRenderer is a Renderer which produces a renderable graph
+// of cStor volumes provisioned through CSI: each persistent volume is linked
+// to its CStorVolumeConfig and CStorVolumeAttachments, the config to the
+// pool instances holding the replicas, and both to the host the volume is
+// attached to.
+var CStorVolumeConfigRenderer = cStorVolumeConfigRenderer{}
+
+// cStorVolumeConfigRenderer is a Renderer to render CVCs & CVAs.
+type cStorVolumeConfigRenderer struct{}
+
+// Render renders the CVC & CVA nodes with adjacency.
+func (v cStorVolumeConfigRenderer) Render(ctx context.Context, rpt report.Report) Nodes {
+	nodes := make(report.Nodes)
+	hosts := hostsByKubernetesNodeName(rpt)
+	pvs := map[string]report.Node{}
+	for _, pvNode := range rpt.PersistentVolume.Nodes {
+		pvName, _ := pvNode.Latest.Lookup(kubernetes.Name)
+		pvs[pvName] = pvNode.WithTopology(report.PersistentVolume)
+	}
+	poolInstances := map[string]string{}
+	for cspiID, cspiNode := range rpt.CStorPoolInstance.Nodes {
+		cspiName, _ := cspiNode.Latest.Lookup(kubernetes.Name)
+		poolInstances[cspiName] = cspiID
+	}
+
+	attach := func(id string, n report.Node) {
+		volumeName, _ := n.Latest.Lookup(kubernetes.VolumeName)
+		if pvNode, ok := pvs[volumeName]; ok {
+			if existing, ok := nodes[pvNode.ID]; ok {
+				pvNode = existing
+			}
+			nodes[pvNode.ID] = pvNode.WithAdjacent(id)
+		}
+		nodeName, _ := n.Latest.Lookup(kubernetes.PublishedNode)
+		if h, ok := hosts[nodeName]; ok {
+			n = n.WithAdjacent(h.ID)
+			nodes[h.ID] = h.WithTopology(report.Host)
+		}
+		nodes[id] = n
+	}
+	for cvcID, cvcNode := range rpt.CStorVolumeConfig.Nodes {
+		cvcNode = cvcNode.WithTopology(report.CStorVolumeConfig)
+		cspiNames, _ := cvcNode.Latest.Lookup(kubernetes.CStorPoolInstances)
+		for _, cspiName := range strings.Split(cspiNames, report.ScopeDelim) {
+			if cspiID, ok := poolInstances[cspiName]; ok {
+				cvcNode = cvcNode.WithAdjacent(cspiID)
+			}
+		}
+		attach(cvcID, cvcNode)
+	}
+	for cvaID, cvaNode := range rpt.CStorVolumeAttachment.Nodes {
+		attach(cvaID, cvaNode.WithTopology(report.CStorVolumeAttachment))
+	}
+	return Nodes{Nodes: nodes}
+}
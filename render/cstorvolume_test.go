@@ -0,0 +1,106 @@
+package render_test
+
+import (
+	"context"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/weaveworks/scope/probe/kubernetes"
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
+)
+
+func TestVolumeBackingHostsAndDevices(t *testing.T) {
+	var (
+		rpt      = report.MakeReport()
+		localID  = report.MakePersistentVolumeNodeID("local")
+		deviceID = report.MakePersistentVolumeNodeID("device")
+		bdcID    = report.MakeBlockDeviceClaimNodeID("bdc")
+		bdID     = report.MakeBlockDeviceNodeID("bd")
+		csiID    = report.MakePersistentVolumeNodeID("csi")
+		cvcID    = report.MakeCStorVolumeConfigNodeID("cvc")
+		cvaID    = report.MakeCStorVolumeAttachmentNodeID("cva")
+		cspiID   = report.MakeCStorPoolInstanceNodeID("cspi")
+		hostA    = report.MakeHostNodeID("host-a")
+		hostB    = report.MakeHostNodeID("host-b")
+	)
+	rpt.Host.AddNode(report.MakeNodeWith(hostA, map[string]string{report.KubernetesNodeName: "node-a"}))
+	rpt.Host.AddNode(report.MakeNodeWith(hostB, map[string]string{report.KubernetesNodeName: "node-b"}))
+
+	// A LocalPV hostpath volume is pinned to its host by node affinity
+	rpt.PersistentVolume.AddNode(kubernetes.NewPersistentVolume(&apiv1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "local",
+			UID:    types.UID("local"),
+			Labels: map[string]string{"openebs.io/cas-type": kubernetes.LocalHostpathCASType},
+		},
+		Spec: apiv1.PersistentVolumeSpec{
+			PersistentVolumeSource: apiv1.PersistentVolumeSource{
+				Local: &apiv1.LocalVolumeSource{Path: "/var/openebs/local/pvc-local"},
+			},
+			NodeAffinity: &apiv1.VolumeNodeAffinity{Required: &apiv1.NodeSelector{
+				NodeSelectorTerms: []apiv1.NodeSelectorTerm{{MatchExpressions: []apiv1.NodeSelectorRequirement{{
+					Key:      "kubernetes.io/hostname",
+					Operator: apiv1.NodeSelectorOpIn,
+					Values:   []string{"node-b"},
+				}}}},
+			}},
+		},
+	}).GetNode(""))
+
+	// A LocalPV device volume is linked to its block device through its claim
+	rpt.PersistentVolume.AddNode(kubernetes.NewPersistentVolume(&apiv1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "device",
+			UID:         types.UID("device"),
+			Labels:      map[string]string{"openebs.io/cas-type": kubernetes.LocalDeviceCASType},
+			Annotations: map[string]string{"local.openebs.io/blockdeviceclaim": "bdc-device"},
+		},
+	}).GetNode(""))
+	rpt.BlockDeviceClaim.AddNode(report.MakeNodeWith(bdcID, map[string]string{
+		kubernetes.Name:            "bdc-device",
+		kubernetes.Namespace:       "openebs",
+		kubernetes.BlockDeviceName: "blockdevice-1",
+	}))
+	rpt.BlockDevice.AddNode(report.MakeNodeWith(bdID, map[string]string{
+		kubernetes.Name:      "blockdevice-1",
+		kubernetes.Namespace: "openebs",
+	}))
+
+	// A cStor CSI volume is linked to its config and attachment
+	rpt.PersistentVolume.AddNode(report.MakeNodeWith(csiID, map[string]string{kubernetes.Name: "csi"}))
+	rpt.CStorPoolInstance.AddNode(report.MakeNodeWith(cspiID, map[string]string{kubernetes.Name: "pool-a"}))
+	rpt.CStorVolumeConfig.AddNode(report.MakeNodeWith(cvcID, map[string]string{
+		kubernetes.VolumeName:         "csi",
+		kubernetes.CStorPoolInstances: "pool-a" + report.ScopeDelim + "pool-gone",
+		kubernetes.PublishedNode:      "node-a",
+	}))
+	rpt.CStorVolumeAttachment.AddNode(report.MakeNodeWith(cvaID, map[string]string{
+		kubernetes.VolumeName:    "csi",
+		kubernetes.PublishedNode: "node-a",
+	}))
+
+	nodes := render.KubernetesVolumesRenderer.Render(context.Background(), rpt).Nodes
+	for id, want := range map[string][]string{
+		localID:  {hostB},
+		deviceID: {bdcID},
+		bdcID:    {bdID},
+		csiID:    {cvaID, cvcID},
+		cvcID:    {cspiID, hostA},
+		cvaID:    {hostA},
+	} {
+		n, ok := nodes[id]
+		if !ok {
+			t.Fatalf("expected node %s, have %v", id, nodes)
+		}
+		if !n.Adjacency.Equal(report.MakeIDList(want...)) {
+			t.Errorf("expected %s to be adjacent to %v, have %v", id, want, n.Adjacency)
+		}
+	}
+	if path, _ := nodes[localID].Latest.Lookup(kubernetes.LocalPath); path != "/var/openebs/local/pvc-local" {
+		t.Errorf("expected local path of the volume, have %q", path)
+	}
+}
@@ -0,0 +1,61 @@
+package render
+
+import (
+	"context"
+	"strings"
+
+	"github.com/weaveworks/scope/report"
+)
+
+// CustomResourceAPITopologyPrefix prefixes the IDs of the API topologies
+// of custom resources.
+const CustomResourceAPITopologyPrefix = "custom-resources-"
+
+// CustomResourceAPITopologyID returns the ID of the API topology showing
+// the custom resources of a report topology.
+func CustomResourceAPITopologyID(topology string) string {
+	return CustomResourceAPITopologyPrefix + strings.TrimPrefix(topology, report.CustomResourcePrefix)
+}
+
+// CustomResourceRenderer returns a Renderer which produces a renderable
+// graph of the custom resources of a topology, each linked from the
+// parents the probes found for it.
+func CustomResourceRenderer(topology string) Renderer {
+	return customResourceRenderer{topology: topology}
+}
+
+type customResourceRenderer struct {
+	topology string
+}
+
+// Render implements Renderer
+func (c customResourceRenderer) Render(ctx context.Context, rpt report.Report) Nodes {
+	t, ok := rpt.Topology(c.topology)
+	if !ok {
+		return Nodes{}
+	}
+	nodes := report.Nodes{}
+	for id, n := range t.Nodes {
+		nodes[id] = n.WithTopology(c.topology)
+	}
+	for id, n := range t.Nodes {
+		for _, parentTopology := range n.Parents.Keys() {
+			pt, ok := rpt.Topology(parentTopology)
+			if !ok {
+				continue
+			}
+			parents, _ := n.Parents.Lookup(parentTopology)
+			for _, parentID := range parents {
+				parent, ok := nodes[parentID]
+				if !ok {
+					if parent, ok = pt.Nodes[parentID]; !ok {
+						continue
+					}
+					parent = parent.WithTopology(parentTopology)
+				}
+				nodes[parentID] = parent.WithAdjacent(id)
+			}
+		}
+	}
+	return Nodes{Nodes: nodes}
+}
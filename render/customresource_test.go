@@ -0,0 +1,48 @@
+package render_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
+)
+
+func TestCustomResourceRenderer(t *testing.T) {
+	var (
+		rpt          = report.MakeReport()
+		kafkas       = report.MakeCustomResourceTopology("kafkas.kafka.strimzi.io")
+		topics       = report.MakeCustomResourceTopology("kafkatopics.kafka.strimzi.io")
+		deploymentID = report.MakeDeploymentNodeID("operator")
+		kafkaID      = report.MakeCustomResourceNodeID(kafkas, "kafka")
+		topicID      = report.MakeCustomResourceNodeID(topics, "topic")
+		otherID      = report.MakeCustomResourceNodeID(topics, "other")
+	)
+	rpt.Deployment.AddNode(report.MakeNode(deploymentID))
+	rpt.CustomResourceTopology("kafkas.kafka.strimzi.io").AddNode(report.MakeNode(kafkaID).
+		WithParent(report.Deployment, deploymentID))
+	rpt.CustomResourceTopology("kafkatopics.kafka.strimzi.io").AddNode(report.MakeNode(topicID).
+		WithParent(kafkas, kafkaID))
+	rpt.CustomResourceTopology("kafkatopics.kafka.strimzi.io").AddNode(report.MakeNode(otherID).
+		WithParent(kafkas, report.MakeCustomResourceNodeID(kafkas, "gone")))
+
+	nodes := render.CustomResourceRenderer(kafkas).Render(context.Background(), rpt).Nodes
+	if have := nodes[deploymentID].Adjacency; !have.Equal(report.MakeIDList(kafkaID)) {
+		t.Errorf("expected the deployment to be linked to the Kafka cluster, have %v", have)
+	}
+	if have := nodes[kafkaID].Topology; have != kafkas {
+		t.Errorf("expected the Kafka cluster in its topology, have %q", have)
+	}
+
+	nodes = render.CustomResourceRenderer(topics).Render(context.Background(), rpt).Nodes
+	if have := nodes[kafkaID].Adjacency; !have.Equal(report.MakeIDList(topicID)) {
+		t.Errorf("expected the Kafka cluster to be linked to its topic, have %v", have)
+	}
+	if len(nodes) != 3 {
+		t.Errorf("expected the topics and their Kafka cluster, have %v", nodes)
+	}
+
+	if have := render.CustomResourceAPITopologyID(kafkas); have != "custom-resources-kafkas.kafka.strimzi.io" {
+		t.Errorf("unexpected API topology ID %q", have)
+	}
+}
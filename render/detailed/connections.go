@@ -17,6 +17,8 @@ const (
 	countLabel  = "Count"
 	remoteKey   = "remote"
 	remoteLabel = "Remote"
+	bytesKey    = "bytes_per_second"
+	bytesLabel  = "Bytes/s"
 	number      = "number"
 )
 
@@ -31,6 +33,9 @@ var (
 		{ID: portKey, Label: portLabel, Datatype: report.Number},
 		{ID: countKey, Label: countLabel, Datatype: report.Number, DefaultSort: true},
 	}
+	// ThroughputColumn is added to the columns when the throughput of
+	// some connections is known.
+	ThroughputColumn = Column{ID: bytesKey, Label: bytesLabel, Datatype: report.Number}
 )
 
 // ConnectionsSummary is the table of connection to/form a node
@@ -65,15 +70,24 @@ type connection struct {
 }
 
 type connectionCounters struct {
-	counted map[string]struct{}
-	counts  map[connection]int
+	counted     map[string]struct{}
+	counts      map[connection]int
+	throughputs map[connection]render.Throughput
 }
 
 func newConnectionCounters() *connectionCounters {
-	return &connectionCounters{counted: map[string]struct{}{}, counts: map[connection]int{}}
+	return &connectionCounters{
+		counted:     map[string]struct{}{},
+		counts:      map[connection]int{},
+		throughputs: map[connection]render.Throughput{},
+	}
 }
 
-func (c *connectionCounters) add(dns report.DNSRecords, outgoing bool, localNode, remoteNode, localEndpoint, remoteEndpoint report.Node) {
+// add counts the connection between two endpoints, unless it has already
+// been counted. It returns whether the connection was counted. adjacentID
+// is the ID the source endpoint is adjacent to, before NAT copies are
+// resolved, which keys the counters of the connection.
+func (c *connectionCounters) add(dns report.DNSRecords, outgoing bool, localNode, remoteNode, localEndpoint, remoteEndpoint report.Node, adjacentID string) bool {
 	// We identify connections by their source endpoint, pre-NAT, to
 	// ensure we only count them once.
 	srcEndpoint, dstEndpoint := remoteEndpoint, localEndpoint
@@ -85,24 +99,38 @@ func (c *connectionCounters) add(dns report.DNSRecords, outgoing bool, localNode
 		connectionID = copySrcEndpointID
 	}
 	if _, ok := c.counted[connectionID]; ok {
-		return
+		return false
 	}
 
 	conn := connection{remoteNodeID: remoteNode.ID}
 	var ok bool
 	if _, _, conn.port, ok = report.ParseEndpointNodeID(dstEndpoint.ID); !ok {
-		return
+		return false
 	}
 	// For internet nodes we break out individual addresses
 	if conn.remoteAddr, ok = internetAddr(dns, remoteNode, remoteEndpoint); !ok {
-		return
+		return false
 	}
 	if conn.localAddr, ok = internetAddr(dns, localNode, localEndpoint); !ok {
-		return
+		return false
 	}
 
 	c.counted[connectionID] = struct{}{}
 	c.counts[conn]++
+	// Counters are reported on the endpoint the connection is made from
+	if throughput, ok := render.EndpointThroughput(srcEndpoint, adjacentID); ok {
+		c.throughputs[conn] = c.throughputs[conn].Add(throughput)
+	}
+	return true
+}
+
+// columns returns the table columns, with the throughput column if the
+// throughput of any connection is known.
+func (c *connectionCounters) columns(columns []Column) []Column {
+	if len(c.throughputs) == 0 {
+		return columns
+	}
+	return append(append([]Column{}, columns...), ThroughputColumn)
 }
 
 func internetAddr(dns report.DNSRecords, node report.Node, ep report.Node) (string, bool) {
@@ -153,6 +181,13 @@ func (c *connectionCounters) rows(r report.Report, ns report.Nodes, includeLocal
 				Value: strconv.Itoa(count),
 			},
 		)
+		if throughput, ok := c.throughputs[row]; ok {
+			connection.Metadata = append(connection.Metadata,
+				report.MetadataRow{
+					ID:    bytesKey,
+					Value: strconv.FormatFloat(throughput.BytesPerSecond, 'f', 0, 64),
+				})
+		}
 		output = append(output, connection)
 	}
 	sort.Sort(connectionsByID(output))
@@ -169,9 +204,9 @@ func incomingConnectionsSummary(topologyID string, r report.Report, n report.Nod
 			continue
 		}
 		for _, remoteEndpoint := range endpointChildrenOf(node) {
-			for _, localEndpointID := range remoteEndpoint.Adjacency.Intersection(localEndpointIDs) {
-				localEndpointID = canonicalEndpointID(localEndpointIDCopies, localEndpointID)
-				counts.add(r.DNS, false, n, node, r.Endpoint.Nodes[localEndpointID], remoteEndpoint)
+			for _, adjacentID := range remoteEndpoint.Adjacency.Intersection(localEndpointIDs) {
+				localEndpointID := canonicalEndpointID(localEndpointIDCopies, adjacentID)
+				counts.add(r.DNS, false, n, node, r.Endpoint.Nodes[localEndpointID], remoteEndpoint, adjacentID)
 			}
 		}
 	}
@@ -184,7 +219,7 @@ func incomingConnectionsSummary(topologyID string, r report.Report, n report.Nod
 		ID:          "incoming-connections",
 		TopologyID:  topologyID,
 		Label:       "Inbound",
-		Columns:     columnHeaders,
+		Columns:     counts.columns(columnHeaders),
 		Connections: counts.rows(r, ns, render.IsInternetNode(n)),
 	}
 }
@@ -201,9 +236,9 @@ func outgoingConnectionsSummary(topologyID string, r report.Report, n report.Nod
 		}
 		remoteEndpointIDs, remoteEndpointIDCopies := endpointChildIDsAndCopyMapOf(node)
 		for _, localEndpoint := range localEndpoints {
-			for _, remoteEndpointID := range localEndpoint.Adjacency.Intersection(remoteEndpointIDs) {
-				remoteEndpointID = canonicalEndpointID(remoteEndpointIDCopies, remoteEndpointID)
-				counts.add(r.DNS, true, n, node, localEndpoint, r.Endpoint.Nodes[remoteEndpointID])
+			for _, adjacentID := range localEndpoint.Adjacency.Intersection(remoteEndpointIDs) {
+				remoteEndpointID := canonicalEndpointID(remoteEndpointIDCopies, adjacentID)
+				counts.add(r.DNS, true, n, node, localEndpoint, r.Endpoint.Nodes[remoteEndpointID], adjacentID)
 			}
 		}
 	}
@@ -216,7 +251,7 @@ func outgoingConnectionsSummary(topologyID string, r report.Report, n report.Nod
 		ID:          "outgoing-connections",
 		TopologyID:  topologyID,
 		Label:       "Outbound",
-		Columns:     columnHeaders,
+		Columns:     counts.columns(columnHeaders),
 		Connections: counts.rows(r, ns, render.IsInternetNode(n)),
 	}
 }
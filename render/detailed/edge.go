@@ -0,0 +1,157 @@
+package detailed
+
+import (
+	"net"
+	"sort"
+
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
+)
+
+// Endpoints without a protocol are TCP endpoints.
+const defaultProtocol = "tcp"
+
+// Verdicts of the network policies on an edge between pods.
+const (
+	PolicyAllowed = "allowed"
+	PolicyDenied  = "denied"
+)
+
+// EdgeSummary describes the connections behind the edge between two
+// rendered nodes.
+type EdgeSummary struct {
+	Source     BasicNodeSummary   `json:"source"`
+	Target     BasicNodeSummary   `json:"target"`
+	Count      int                `json:"count"`
+	Throughput *render.Throughput `json:"throughput,omitempty"`
+	Policy     string             `json:"policy,omitempty"`
+	Ports      []EdgePort         `json:"ports"`
+	Endpoints  []EndpointPair     `json:"endpoints"`
+}
+
+// EdgePort aggregates the connections of an edge by destination port.
+type EdgePort struct {
+	Port       string             `json:"port"`
+	Protocol   string             `json:"protocol"`
+	Count      int                `json:"count"`
+	Throughput *render.Throughput `json:"throughput,omitempty"`
+}
+
+// EndpointPair is a single connection of an edge.
+type EndpointPair struct {
+	Source     string             `json:"source"`
+	Target     string             `json:"target"`
+	Protocol   string             `json:"protocol"`
+	Throughput *render.Throughput `json:"throughput,omitempty"`
+}
+
+type edgePortKey struct {
+	port, protocol string
+}
+
+// MakeEdgeSummary summarizes the connections from node src to node dst,
+// counting each connection once, as in the connection tables of the node
+// details.
+func MakeEdgeSummary(r report.Report, src, dst report.Node) EdgeSummary {
+	summary := EdgeSummary{Ports: []EdgePort{}, Endpoints: []EndpointPair{}}
+	summary.Source, _ = MakeBasicNodeSummary(r, src)
+	summary.Target, _ = MakeBasicNodeSummary(r, dst)
+	summary.Policy = edgePolicy(src, dst)
+
+	var (
+		counts      = newConnectionCounters()
+		ports       = map[edgePortKey]*EdgePort{}
+		throughput  render.Throughput
+		throughputs int
+
+		dstEndpointIDs, dstEndpointIDCopies = endpointChildIDsAndCopyMapOf(dst)
+	)
+	for _, srcEndpoint := range endpointChildrenOf(src) {
+		for _, adjacentID := range srcEndpoint.Adjacency.Intersection(dstEndpointIDs) {
+			dstEndpointID := canonicalEndpointID(dstEndpointIDCopies, adjacentID)
+			dstEndpoint := r.Endpoint.Nodes[dstEndpointID]
+			if !counts.add(r.DNS, true, src, dst, srcEndpoint, dstEndpoint, adjacentID) {
+				continue
+			}
+			srcAddr, _, ok := endpointAddr(srcEndpoint.ID)
+			if !ok {
+				continue
+			}
+			dstAddr, dstPort, ok := endpointAddr(dstEndpointID)
+			if !ok {
+				continue
+			}
+			pair := EndpointPair{Source: srcAddr, Target: dstAddr, Protocol: endpointProtocol(srcEndpoint)}
+			key := edgePortKey{dstPort, pair.Protocol}
+			port, ok := ports[key]
+			if !ok {
+				port = &EdgePort{Port: dstPort, Protocol: pair.Protocol}
+				ports[key] = port
+			}
+			port.Count++
+			if t, ok := render.EndpointThroughput(srcEndpoint, adjacentID); ok {
+				pair.Throughput = &t
+				port.Throughput = addThroughput(port.Throughput, t)
+				throughput = throughput.Add(t)
+				throughputs++
+			}
+			summary.Count++
+			summary.Endpoints = append(summary.Endpoints, pair)
+		}
+	}
+	if throughputs > 0 {
+		summary.Throughput = &throughput
+	}
+	for _, port := range ports {
+		summary.Ports = append(summary.Ports, *port)
+	}
+	sort.Slice(summary.Ports, func(i, j int) bool {
+		if summary.Ports[i].Count != summary.Ports[j].Count {
+			return summary.Ports[i].Count > summary.Ports[j].Count
+		}
+		return summary.Ports[i].Port < summary.Ports[j].Port
+	})
+	sort.Slice(summary.Endpoints, func(i, j int) bool {
+		if summary.Endpoints[i].Source != summary.Endpoints[j].Source {
+			return summary.Endpoints[i].Source < summary.Endpoints[j].Source
+		}
+		return summary.Endpoints[i].Target < summary.Endpoints[j].Target
+	})
+	return summary
+}
+
+// edgePolicy returns the verdict of the network policies on the edge, if
+// the renderer judged it.
+func edgePolicy(src, dst report.Node) string {
+	if denied, ok := src.Sets.Lookup(report.KubernetesNetworkPolicyDenied); ok && denied.Contains(dst.ID) {
+		return PolicyDenied
+	}
+	if allowed, ok := src.Sets.Lookup(report.KubernetesNetworkPolicyAllowed); ok && allowed.Contains(dst.ID) {
+		return PolicyAllowed
+	}
+	return ""
+}
+
+// endpointAddr returns the host:port address, and the port, of an endpoint.
+func endpointAddr(endpointID string) (string, string, bool) {
+	_, addr, port, ok := report.ParseEndpointNodeID(endpointID)
+	if !ok {
+		return "", "", false
+	}
+	return net.JoinHostPort(addr, port), port, true
+}
+
+func endpointProtocol(n report.Node) string {
+	if protocol, ok := n.Latest.Lookup(report.Protocol); ok {
+		return protocol
+	}
+	return defaultProtocol
+}
+
+func addThroughput(t *render.Throughput, other render.Throughput) *render.Throughput {
+	if t == nil {
+		return &other
+	}
+	sum := t.Add(other)
+	return &sum
+}
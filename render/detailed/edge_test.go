@@ -0,0 +1,40 @@
+package detailed_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/weaveworks/common/test"
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/render/detailed"
+	"github.com/weaveworks/scope/test/fixture"
+	"github.com/weaveworks/scope/test/reflect"
+)
+
+func TestMakeEdgeSummary(t *testing.T) {
+	nodes := render.HostRenderer.Render(context.Background(), fixture.Report).Nodes
+	have := detailed.MakeEdgeSummary(fixture.Report, nodes[fixture.ClientHostNodeID], nodes[fixture.ServerHostNodeID])
+
+	if want := "client"; have.Source.Label != want {
+		t.Errorf("want source %q, have %q", want, have.Source.Label)
+	}
+	if want := "server"; have.Target.Label != want {
+		t.Errorf("want target %q, have %q", want, have.Target.Label)
+	}
+	wantPorts := []detailed.EdgePort{
+		{Port: fixture.ServerPort, Protocol: "tcp", Count: 2},
+	}
+	if !reflect.DeepEqual(wantPorts, have.Ports) {
+		t.Error(test.Diff(wantPorts, have.Ports))
+	}
+	wantEndpoints := []detailed.EndpointPair{
+		{Source: fixture.ClientIP + ":" + fixture.ClientPort54001, Target: fixture.ServerIP + ":" + fixture.ServerPort, Protocol: "tcp"},
+		{Source: fixture.ClientIP + ":" + fixture.ClientPort54002, Target: fixture.ServerIP + ":" + fixture.ServerPort, Protocol: "tcp"},
+	}
+	if !reflect.DeepEqual(wantEndpoints, have.Endpoints) {
+		t.Error(test.Diff(wantEndpoints, have.Endpoints))
+	}
+	if have.Count != 2 || have.Throughput != nil {
+		t.Errorf("want 2 connections without throughput, have %d, %v", have.Count, have.Throughput)
+	}
+}
@@ -43,6 +43,14 @@ var (
 			ID:    idTransmitBytes,
 			Label: "Tx/s",
 		},
+		{
+			ID:    kubernetes.VolumeUsedBytes,
+			Label: "Used",
+		},
+		{
+			ID:    kubernetes.VolumeAvailableBytes,
+			Label: "Available",
+		},
 	}
 
 	// Queries on pod names of the format `name-<id>-<hash>`
@@ -62,14 +70,25 @@ var (
 			`pod_name="{{label}}",namespace="{{namespace}}"`,
 			[]string{docker.MemoryUsage, docker.CPUTotalUsage},
 		),
-		report.DaemonSet:   formatMetricQueries(`pod_name=~"^{{label}}-[^-]+$",namespace="{{namespace}}"`, []string{docker.MemoryUsage, docker.CPUTotalUsage}),
-		report.Deployment:  podIDHashQueries,
-		report.StatefulSet: podIDHashQueries,
-		report.CronJob:     podIDHashQueries,
+		report.DaemonSet:         formatMetricQueries(`pod_name=~"^{{label}}-[^-]+$",namespace="{{namespace}}"`, []string{docker.MemoryUsage, docker.CPUTotalUsage}),
+		report.Deployment:        podIDHashQueries,
+		report.StatefulSet:       podIDHashQueries,
+		report.CronJob:           podIDHashQueries,
+		report.GenericController: podIDHashQueries,
 		report.Service: {
 			docker.CPUTotalUsage: `sum(rate(container_cpu_usage_seconds_total{image!="",namespace="{{namespace}}",_weave_pod_name="{{label}}",job="cadvisor",container_name!="POD"}[5m]))`,
 			docker.MemoryUsage:   `sum(rate(container_memory_usage_bytes{image!="",namespace="{{namespace}}",_weave_pod_name="{{label}}",job="cadvisor",container_name!="POD"}[5m]))`,
 		},
+
+		// Storage topologies
+
+		// All `kubelet_volume_stats_*` metrics are provided by Kubelets
+		report.PersistentVolumeClaim: {
+			kubernetes.VolumeUsedBytes:      `sum(kubelet_volume_stats_used_bytes{persistentvolumeclaim="{{label}}",namespace="{{namespace}}"})`,
+			kubernetes.VolumeAvailableBytes: `sum(kubelet_volume_stats_available_bytes{persistentvolumeclaim="{{label}}",namespace="{{namespace}}"})`,
+			kubernetes.VolumeInodesUsed:     `sum(kubelet_volume_stats_inodes_used{persistentvolumeclaim="{{label}}",namespace="{{namespace}}"})`,
+			kubernetes.VolumeInodesFree:     `sum(kubelet_volume_stats_inodes_free{persistentvolumeclaim="{{label}}",namespace="{{namespace}}"})`,
+		},
 	}
 )
 
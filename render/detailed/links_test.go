@@ -25,6 +25,9 @@ var (
 	sampleContainerNode = report.MakeNode("coo").
 				WithTopology(report.Container).
 				WithLatests(map[string]string{docker.ContainerName: "cooname"})
+	samplePVCNode = report.MakeNode("poo").
+			WithTopology(report.PersistentVolumeClaim).
+			WithLatests(map[string]string{kubernetes.Namespace: "poospace"})
 	sampleMetrics = []report.MetricRow{
 		{ID: docker.MemoryUsage},
 		{ID: docker.CPUTotalUsage},
@@ -76,6 +79,20 @@ func TestRenderMetricURLs_Container(t *testing.T) {
 		[]string{"container_cpu_usage_seconds", `name=\"cooname\"`})
 }
 
+func TestRenderMetricURLs_PersistentVolumeClaim(t *testing.T) {
+	s := nodeSummaryWithMetrics("foo", []report.MetricRow{{ID: kubernetes.VolumeUsedBytes, Priority: 1}})
+	result := detailed.RenderMetricURLs(s, samplePVCNode, report.MakeReport(), sampleMetricsGraphURL)
+
+	checkURL(t, result.Metrics[0].URL, sampleMetricsGraphURL,
+		[]string{"kubelet_volume_stats_used_bytes", `persistentvolumeclaim=\"foo\"`, `namespace=\"poospace\"`})
+
+	// Claims not mounted on any probe's node still link to their available space
+	assert.Equal(t, kubernetes.VolumeAvailableBytes, result.Metrics[1].ID)
+	assert.True(t, result.Metrics[1].ValueEmpty)
+	checkURL(t, result.Metrics[1].URL, sampleMetricsGraphURL,
+		[]string{"kubelet_volume_stats_available_bytes", `persistentvolumeclaim=\"foo\"`})
+}
+
 func TestRenderMetricURLs_EmptyMetrics(t *testing.T) {
 	result := detailed.RenderMetricURLs(detailed.NodeSummary{}, samplePodNode, report.MakeReport(), sampleMetricsGraphURL)
 
@@ -10,6 +10,7 @@ import (
 	"github.com/weaveworks/scope/probe/docker"
 	"github.com/weaveworks/scope/probe/kubernetes"
 	"github.com/weaveworks/scope/probe/process"
+	"github.com/weaveworks/scope/render"
 	"github.com/weaveworks/scope/report"
 )
 
@@ -41,13 +42,14 @@ func (*ControlInstance) UnmarshalJSON(b []byte) error {
 }
 
 type wiredControlInstance struct {
-	ProbeID      string `json:"probeId"`
-	NodeID       string `json:"nodeId"`
-	ID           string `json:"id"`
-	Human        string `json:"human"`
-	Icon         string `json:"icon"`
-	Confirmation string `json:"confirmation,omitempty"`
-	Rank         int    `json:"rank"`
+	ProbeID      string                `json:"probeId"`
+	NodeID       string                `json:"nodeId"`
+	ID           string                `json:"id"`
+	Human        string                `json:"human"`
+	Icon         string                `json:"icon"`
+	Confirmation string                `json:"confirmation,omitempty"`
+	Rank         int                   `json:"rank"`
+	Params       []report.ControlParam `json:"params,omitempty"`
 }
 
 // CodecEncodeSelf marshals this ControlInstance. It takes the basic Metric
@@ -61,6 +63,7 @@ func (c *ControlInstance) CodecEncodeSelf(encoder *codec.Encoder) {
 		Icon:         c.Control.Icon,
 		Confirmation: c.Control.Confirmation,
 		Rank:         c.Control.Rank,
+		Params:       c.Control.Params,
 	})
 }
 
@@ -77,6 +80,7 @@ func (c *ControlInstance) CodecDecodeSelf(decoder *codec.Decoder) {
 			Icon:         in.Icon,
 			Confirmation: in.Confirmation,
 			Rank:         in.Rank,
+			Params:       in.Params,
 		},
 	}
 }
@@ -87,28 +91,29 @@ type RenderContext struct {
 	MetricsGraphURL string
 }
 
+// ControlFilter decides which controls of a node are shown.
+type ControlFilter func(n report.Node, c report.Control) bool
+
 // MakeNode transforms a renderable node to a detailed node. It uses
 // aggregate metadata, plus the set of origin node IDs, to produce tables.
 func MakeNode(topologyID string, rc RenderContext, ns report.Nodes, n report.Node) Node {
-	summary, _ := MakeNodeSummary(rc, n)
-	return Node{
-		NodeSummary: summary,
-		Controls:    controls(rc.Report, n, ""),
-		Children:    children(rc, n),
-		Connections: []ConnectionsSummary{
-			incomingConnectionsSummary(topologyID, rc.Report, n, ns),
-			outgoingConnectionsSummary(topologyID, rc.Report, n, ns),
-		},
-	}
+	return MakeNodeWithControlFilter(topologyID, nil, rc, ns, n)
 }
 
 // MakeNodeWithReadOnlyControls transforms a renderable node to a detailed node. It uses
 // aggregate metadata, plus the set of origin node IDs, to produce tables.
 func MakeNodeWithReadOnlyControls(topologyID, userKind string, rc RenderContext, ns report.Nodes, n report.Node) Node {
+	return MakeNodeWithControlFilter(topologyID, ReadOnlyControls(userKind), rc, ns, n)
+}
+
+// MakeNodeWithControlFilter transforms a renderable node to a detailed node,
+// with only the controls the filter lets through. A nil filter lets all
+// controls through.
+func MakeNodeWithControlFilter(topologyID string, filter ControlFilter, rc RenderContext, ns report.Nodes, n report.Node) Node {
 	summary, _ := MakeNodeSummary(rc, n)
 	return Node{
 		NodeSummary: summary,
-		Controls:    controls(rc.Report, n, userKind),
+		Controls:    controls(rc.Report, n, filter),
 		Children:    children(rc, n),
 		Connections: []ConnectionsSummary{
 			incomingConnectionsSummary(topologyID, rc.Report, n, ns),
@@ -117,7 +122,18 @@ func MakeNodeWithReadOnlyControls(topologyID, userKind string, rc RenderContext,
 	}
 }
 
-func controlsFor(topology report.Topology, nodeID, userKind string) []ControlInstance {
+// ReadOnlyControls returns the filter of the controls a kind of user may
+// see: read-only ones for read admins, all of them otherwise.
+func ReadOnlyControls(userKind string) ControlFilter {
+	if userKind != report.ReadAdminUSer {
+		return nil
+	}
+	return func(_ report.Node, c report.Control) bool {
+		return c.Category == report.ReadOnlyControl
+	}
+}
+
+func controlsFor(topology report.Topology, nodeID string, filter ControlFilter) []ControlInstance {
 	result := []ControlInstance{}
 	node, ok := topology.Nodes[nodeID]
 	if !ok {
@@ -131,32 +147,30 @@ func controlsFor(topology report.Topology, nodeID, userKind string) []ControlIns
 		if data.Dead {
 			return
 		}
-		if userKind == report.ReadAdminUSer {
-			if control, ok := topology.Controls[controlID]; ok {
-				if control.Category == report.ReadOnlyControl {
-					result = append(result, ControlInstance{
-						ProbeID: probeID,
-						NodeID:  nodeID,
-						Control: control,
-					})
-				}
-			}
-		} else {
-			if control, ok := topology.Controls[controlID]; ok {
-				result = append(result, ControlInstance{
-					ProbeID: probeID,
-					NodeID:  nodeID,
-					Control: control,
-				})
-			}
+		control, ok := topology.Controls[controlID]
+		if !ok || (filter != nil && !filter(node, control)) {
+			return
 		}
+		result = append(result, ControlInstance{
+			ProbeID: probeID,
+			NodeID:  nodeID,
+			Control: control,
+		})
 	})
 	return result
 }
 
-func controls(r report.Report, n report.Node, userKind string) []ControlInstance {
+func controls(r report.Report, n report.Node, filter ControlFilter) []ControlInstance {
 	if t, ok := r.Topology(n.Topology); ok {
-		return controlsFor(t, n.ID, userKind)
+		result := controlsFor(t, n.ID, filter)
+		// Kubernetes node controls are handled by the probe talking to
+		// the Kubernetes API, but are shown on the host they apply to.
+		if n.Topology == report.Host {
+			if kubeNode, ok := render.KubernetesNodeForHost(r, n); ok {
+				result = append(result, controlsFor(r.KubernetesNode, kubeNode.ID, filter)...)
+			}
+		}
+		return result
 	}
 	return []ControlInstance{}
 }
@@ -278,6 +292,27 @@ var nodeSummaryGroupSpecs = []struct {
 			Columns: []Column{},
 		},
 	},
+	{
+		topologyID: report.JivaVolume,
+		NodeSummaryGroup: NodeSummaryGroup{
+			Label:   "Jiva Volumes",
+			Columns: []Column{},
+		},
+	},
+	{
+		topologyID: report.CStorVolumeConfig,
+		NodeSummaryGroup: NodeSummaryGroup{
+			Label:   "CStor Volume Configs",
+			Columns: []Column{},
+		},
+	},
+	{
+		topologyID: report.CStorVolumeAttachment,
+		NodeSummaryGroup: NodeSummaryGroup{
+			Label:   "CStor Volume Attachments",
+			Columns: []Column{},
+		},
+	},
 	{
 		topologyID: report.CStorVolumeReplica,
 		NodeSummaryGroup: NodeSummaryGroup{
@@ -348,7 +383,7 @@ func children(rc RenderContext, n report.Node) []NodeSummaryGroup {
 		if len(summaries[spec.topologyID]) == 0 {
 			continue
 		}
-		apiTopology, ok := primaryAPITopology[spec.topologyID]
+		apiTopology, ok := apiTopologyFor(spec.topologyID)
 		if !ok {
 			continue
 		}
@@ -368,7 +403,7 @@ func children(rc RenderContext, n report.Node) []NodeSummaryGroup {
 		if !ok {
 			continue
 		}
-		apiTopology, ok := primaryAPITopology[topologyID]
+		apiTopology, ok := apiTopologyFor(topologyID)
 		if !ok {
 			continue
 		}
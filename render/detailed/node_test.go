@@ -3,9 +3,11 @@ package detailed_test
 import (
 	"context"
 	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/sirupsen/logrus"
+	"github.com/ugorji/go/codec"
 	"github.com/weaveworks/common/test"
 	"github.com/weaveworks/scope/probe/docker"
 	"github.com/weaveworks/scope/probe/kubernetes"
@@ -176,6 +178,42 @@ func TestMakeDetailedHostNode(t *testing.T) {
 	}
 }
 
+func TestMakeDetailedHostNodeKubernetesNode(t *testing.T) {
+	rpt := fixture.Report.Copy()
+	rpt.Host.Nodes[fixture.ClientHostNodeID] = rpt.Host.Nodes[fixture.ClientHostNodeID].
+		WithLatest(report.KubernetesNodeName, fixture.Now, "node-1")
+	rpt.KubernetesNode = report.MakeTopology().WithMetadataTemplates(kubernetes.NodeMetadataTemplates)
+	rpt.KubernetesNode.AddNode(report.MakeNodeWith(report.MakeKubernetesNodeNodeID("node-1-uid"), map[string]string{
+		kubernetes.Name:       "node-1",
+		kubernetes.NodeReady:  "True",
+		report.ControlProbeID: "cluster-probe",
+	}).WithLatestActiveControls(kubernetes.CordonNode))
+	rpt.KubernetesNode.Controls.AddControls(kubernetes.NodeControls)
+	rpt.Host = rpt.Host.WithMetadataTemplates(kubernetes.NodeMetadataTemplates)
+
+	renderableNodes := render.HostRenderer.Render(context.Background(), rpt).Nodes
+	have := detailed.MakeNode("hosts", detailed.RenderContext{Report: rpt}, renderableNodes, renderableNodes[fixture.ClientHostNodeID])
+
+	found := false
+	for _, m := range have.Metadata {
+		if m.ID == kubernetes.NodeReady && m.Value == "True" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected host to have the node's ready condition, have %v", have.Metadata)
+	}
+	found = false
+	for _, c := range have.Controls {
+		if c.Control.ID == kubernetes.CordonNode && c.ProbeID == "cluster-probe" && c.NodeID == report.MakeKubernetesNodeNodeID("node-1-uid") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected host to have the node's cordon control, have %v", have.Controls)
+	}
+}
+
 func TestMakeDetailedContainerNode(t *testing.T) {
 	id := fixture.ServerContainerNodeID
 	renderableNodes := render.ContainerWithImageNameRenderer.Render(context.Background(), fixture.Report).Nodes
@@ -421,3 +459,68 @@ func TestMakeDetailedPodNode(t *testing.T) {
 		t.Errorf("%s", test.Diff(want, have))
 	}
 }
+
+func TestControlInstanceCodec(t *testing.T) {
+	min := int64(0)
+	want := detailed.ControlInstance{
+		ProbeID: "probe",
+		NodeID:  "node",
+		Control: report.Control{
+			ID:    "scale",
+			Human: "Scale",
+			Icon:  "fa fa-sliders-h",
+			Rank:  1,
+			Params: []report.ControlParam{
+				{Name: "replicas", Label: "Replicas", Type: report.ControlParamInt, Required: true, Min: &min},
+			},
+		},
+	}
+	var buf []byte
+	if err := codec.NewEncoderBytes(&buf, &codec.JsonHandle{}).Encode(&want); err != nil {
+		t.Fatal(err)
+	}
+	var have detailed.ControlInstance
+	if err := codec.NewDecoderBytes(buf, &codec.JsonHandle{}).Decode(&have); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, have) {
+		t.Error(test.Diff(want, have))
+	}
+}
+
+func TestMakeNodeWithControlFilter(t *testing.T) {
+	rpt := report.MakeReport()
+	id := report.MakePodNodeID("pod")
+	rpt.Pod.AddNode(report.MakeNodeWith(id, map[string]string{
+		report.ControlProbeID:      "probe",
+		report.KubernetesNamespace: "dev",
+	}).WithTopology(report.Pod).WithLatestActiveControls("describe", "delete"))
+	rpt.Pod.Controls.AddControl(report.Control{ID: "describe", Category: report.ReadOnlyControl})
+	rpt.Pod.Controls.AddControl(report.Control{ID: "delete"})
+	n := rpt.Pod.Nodes[id]
+
+	controlIDs := func(filter detailed.ControlFilter) []string {
+		ids := []string{}
+		for _, c := range detailed.MakeNodeWithControlFilter("pods", filter, detailed.RenderContext{Report: rpt}, rpt.Pod.Nodes, n).Controls {
+			ids = append(ids, c.Control.ID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	for _, tc := range []struct {
+		name   string
+		filter detailed.ControlFilter
+		want   []string
+	}{
+		{"all", nil, []string{"delete", "describe"}},
+		{"read-only", detailed.ReadOnlyControls(report.ReadAdminUSer), []string{"describe"}},
+		{"by namespace", func(n report.Node, c report.Control) bool {
+			namespace, _ := n.Latest.Lookup(report.KubernetesNamespace)
+			return namespace == "dev" && c.ID == "delete"
+		}, []string{"delete"}},
+	} {
+		if have := controlIDs(tc.filter); !reflect.DeepEqual(tc.want, have) {
+			t.Errorf("%s: %s", tc.name, test.Diff(tc.want, have))
+		}
+	}
+}
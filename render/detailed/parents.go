@@ -1,6 +1,8 @@
 package detailed
 
 import (
+	"sort"
+
 	"github.com/weaveworks/scope/report"
 )
 
@@ -20,6 +22,7 @@ var parentTopologies = []string{
 	report.DaemonSet,
 	report.StatefulSet,
 	report.CronJob,
+	report.GenericController,
 	report.Service,
 	report.ECSTask,
 	report.ECSService,
@@ -34,12 +37,16 @@ func Parents(r report.Report, n report.Node) []Parent {
 		return nil
 	}
 	result := make([]Parent, 0, n.Parents.Size())
-	for _, topologyID := range parentTopologies {
+	topologyIDs := parentTopologies
+	if custom := customResourceParentTopologies(n); len(custom) > 0 {
+		topologyIDs = append(append([]string{}, parentTopologies...), custom...)
+	}
+	for _, topologyID := range topologyIDs {
 		topology, ok := r.Topology(topologyID)
 		if !ok {
 			continue
 		}
-		apiTopologyID, ok := primaryAPITopology[topologyID]
+		apiTopologyID, ok := apiTopologyFor(topologyID)
 		if !ok {
 			continue
 		}
@@ -66,3 +73,16 @@ func Parents(r report.Report, n report.Node) []Parent {
 	}
 	return result
 }
+
+// customResourceParentTopologies returns the custom resource topologies
+// the parents of a node are in, sorted.
+func customResourceParentTopologies(n report.Node) []string {
+	var topologyIDs []string
+	for _, topologyID := range n.Parents.Keys() {
+		if report.IsCustomResourceTopology(topologyID) {
+			topologyIDs = append(topologyIDs, topologyID)
+		}
+	}
+	sort.Strings(topologyIDs)
+	return topologyIDs
+}
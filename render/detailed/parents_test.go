@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/weaveworks/common/test"
+	"github.com/weaveworks/scope/probe/kubernetes"
 	"github.com/weaveworks/scope/render"
 	"github.com/weaveworks/scope/render/detailed"
 	"github.com/weaveworks/scope/render/expected"
@@ -65,3 +66,32 @@ func TestParents(t *testing.T) {
 		}
 	}
 }
+
+func TestCustomResourceParents(t *testing.T) {
+	var (
+		rpt     = report.MakeReport()
+		kafkas  = report.MakeCustomResourceTopology("kafkas.kafka.strimzi.io")
+		topics  = report.MakeCustomResourceTopology("kafkatopics.kafka.strimzi.io")
+		kafkaID = report.MakeCustomResourceNodeID(kafkas, "kafka")
+	)
+	rpt.CustomResourceTopology("kafkas.kafka.strimzi.io").AddNode(report.MakeNodeWith(kafkaID, map[string]string{
+		kubernetes.Name:               "my-cluster",
+		kubernetes.CustomResourceKind: "Kafka",
+	}).WithTopology(kafkas))
+	topic := report.MakeNodeWith(report.MakeCustomResourceNodeID(topics, "topic"), map[string]string{
+		kubernetes.Name:               "topic-resource",
+		kubernetes.CustomResourceName: "orders",
+		kubernetes.CustomResourceKind: "KafkaTopic",
+	}).WithTopology(topics).WithParent(kafkas, kafkaID)
+
+	want := []detailed.Parent{
+		{ID: kafkaID, Label: "my-cluster", TopologyID: "custom-resources-kafkas.kafka.strimzi.io"},
+	}
+	if have := detailed.Parents(rpt, topic); !reflect.DeepEqual(want, have) {
+		t.Error(test.Diff(want, have))
+	}
+	summary, ok := detailed.MakeBasicNodeSummary(rpt, topic)
+	if !ok || summary.Label != "orders" || summary.LabelMinor != "KafkaTopic" {
+		t.Errorf("unexpected summary of the topic: %+v", summary)
+	}
+}
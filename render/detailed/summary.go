@@ -80,6 +80,7 @@ var renderers = map[string]func(BasicNodeSummary, report.Node) BasicNodeSummary{
 	report.StatefulSet:           podGroupNodeSummary,
 	report.CronJob:               podGroupNodeSummary,
 	report.Job:                   podGroupNodeSummary,
+	report.GenericController:     genericControllerNodeSummary,
 	report.ECSTask:               ecsTaskNodeSummary,
 	report.ECSService:            ecsServiceNodeSummary,
 	report.SwarmService:          swarmServiceNodeSummary,
@@ -103,6 +104,12 @@ var renderers = map[string]func(BasicNodeSummary, report.Node) BasicNodeSummary{
 	report.CsiVolumeSnapshot:     csiVolumeSnapshotNodeSummary,
 	report.VolumeSnapshotClass:   volumeSnapshotClassNodeSummary,
 	report.VolumeSnapshotContent: volumeSnapshotContentNodeSummary,
+	report.NetworkPolicy:         networkPolicyNodeSummary,
+	report.Ingress:               ingressNodeSummary,
+	report.CSIDriver:             csiDriverNodeSummary,
+	report.JivaVolume:            jivaVolumeNodeSummary,
+	report.CStorVolumeConfig:     cStorVolumeConfigNodeSummary,
+	report.CStorVolumeAttachment: cStorVolumeAttachmentNodeSummary,
 }
 
 // For each report.Topology, map to a 'primary' API topology. This can then be used in a variety of places.
@@ -116,6 +123,7 @@ var primaryAPITopology = map[string]string{
 	report.StatefulSet:           "kube-controllers",
 	report.CronJob:               "kube-controllers",
 	report.Job:                   "kube-controllers",
+	report.GenericController:     "kube-controllers",
 	report.Service:               "services",
 	report.ECSTask:               "ecs-tasks",
 	report.ECSService:            "ecs-services",
@@ -129,6 +137,9 @@ var primaryAPITopology = map[string]string{
 	report.Disk:                  "hosts",
 	report.StoragePoolClaim:      "pools",
 	report.CStorVolume:           "volumes",
+	report.JivaVolume:            "volumes",
+	report.CStorVolumeConfig:     "volumes",
+	report.CStorVolumeAttachment: "volumes",
 	report.CStorVolumeReplica:    "volumes",
 	report.CStorPool:             "volumes",
 	report.BlockDevice:           "pools",
@@ -138,6 +149,19 @@ var primaryAPITopology = map[string]string{
 	report.CsiVolumeSnapshot:     "volumes",
 	report.VolumeSnapshotClass:   "volumes",
 	report.VolumeSnapshotContent: "volumes",
+	report.NetworkPolicy:         "network-policies",
+	report.Ingress:               "ingresses",
+	report.CSIDriver:             "csi-drivers",
+}
+
+// apiTopologyFor returns the API topology nodes of a report topology are
+// primarily shown in. Custom resources have an API topology each.
+func apiTopologyFor(topologyID string) (string, bool) {
+	if report.IsCustomResourceTopology(topologyID) {
+		return render.CustomResourceAPITopologyID(topologyID), true
+	}
+	apiTopology, ok := primaryAPITopology[topologyID]
+	return apiTopology, ok
 }
 
 // MakeBasicNodeSummary returns a basic summary of a node, if
@@ -161,6 +185,11 @@ func MakeBasicNodeSummary(r report.Report, n report.Node) (BasicNodeSummary, boo
 		return renderer(summary, n), true
 	}
 
+	// Is it a custom resource topology?
+	if report.IsCustomResourceTopology(n.Topology) {
+		return customResourceNodeSummary(summary, n), true
+	}
+
 	// Is it a group topology?
 	if strings.HasPrefix(n.Topology, "group:") {
 		return groupNodeSummary(summary, r, n), true
@@ -366,6 +395,16 @@ func podGroupNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary
 	return base
 }
 
+// genericControllerNodeSummary names generic controllers after their
+// kind, which only the probe knows.
+func genericControllerNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary {
+	base = podGroupNodeSummary(base, n)
+	if kind, ok := n.Latest.Lookup(kubernetes.NodeType); ok {
+		base.LabelMinor = fmt.Sprintf("%s of %s", kind, pluralize(n.Counters, report.Pod, "pod", "pods"))
+	}
+	return base
+}
+
 func ecsTaskNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary {
 	base.Label, _ = n.Latest.Lookup(awsecs.TaskFamily)
 	if base.Label == "" {
@@ -457,6 +496,27 @@ func volumeSnapshotContentNodeSummary(base BasicNodeSummary, n report.Node) Basi
 	return base
 }
 
+func networkPolicyNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary {
+	base = addKubernetesLabelAndRank(base, n)
+	base.LabelMinor = "Network policy"
+	return base
+}
+
+func ingressNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary {
+	base = addKubernetesLabelAndRank(base, n)
+	base.LabelMinor = "Ingress"
+	if hosts, ok := n.Latest.Lookup(kubernetes.Hosts); ok {
+		base.LabelMinor = hosts
+	}
+	return base
+}
+
+func csiDriverNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary {
+	base = addKubernetesLabelAndRank(base, n)
+	base.LabelMinor = "CSI driver"
+	return base
+}
+
 func volumeSnapshotDataNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary {
 	base = addKubernetesLabelAndRank(base, n)
 	base.LabelMinor = "Volume snapshot data"
@@ -488,6 +548,37 @@ func cStorVolumeNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSumma
 	return base
 }
 
+func jivaVolumeNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary {
+	base = addKubernetesLabelAndRank(base, n)
+	base.LabelMinor = "Jiva Volume"
+	return base
+}
+
+func cStorVolumeConfigNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary {
+	base = addKubernetesLabelAndRank(base, n)
+	base.LabelMinor = "cStor Volume Config"
+	return base
+}
+
+func cStorVolumeAttachmentNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary {
+	base = addKubernetesLabelAndRank(base, n)
+	base.LabelMinor = "cStor Volume Attachment"
+	return base
+}
+
+func customResourceNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary {
+	base = addKubernetesLabelAndRank(base, n)
+	if name, ok := n.Latest.Lookup(kubernetes.CustomResourceName); ok {
+		base.Label = name
+	}
+	if label, ok := n.Latest.Lookup(kubernetes.CustomResourceLabel); ok {
+		base.LabelMinor = label
+	} else {
+		base.LabelMinor, _ = n.Latest.Lookup(kubernetes.CustomResourceKind)
+	}
+	return base
+}
+
 func cStorVolumeReplicaNodeSummary(base BasicNodeSummary, n report.Node) BasicNodeSummary {
 	base = addKubernetesLabelAndRank(base, n)
 	base.LabelMinor = "cStor Volume Replica"
@@ -474,3 +474,19 @@ func TestNodeTables(t *testing.T) {
 		}
 	}
 }
+
+func TestGenericControllerNodeSummary(t *testing.T) {
+	id := report.MakeGenericControllerNodeID("rollout")
+	n := report.MakeNodeWith(id, map[string]string{
+		report.KubernetesName:      "pong",
+		report.KubernetesNamespace: "ping",
+		report.KubernetesNodeType:  "Rollout",
+	}).WithTopology(report.GenericController).WithCounters(map[string]int{report.Pod: 2})
+	have, ok := detailed.MakeBasicNodeSummary(report.MakeReport(), n)
+	if !ok {
+		t.Fatal("expected a summary of the generic controller")
+	}
+	if have.Label != "pong" || have.LabelMinor != "Rollout of 2 pods" || !have.Stack {
+		t.Errorf("expected the rollout to be summarised after its kind, have %+v", have)
+	}
+}
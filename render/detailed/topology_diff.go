@@ -2,6 +2,7 @@ package detailed
 
 import (
 	"reflect"
+	"sort"
 )
 
 // Diff is returned by TopoDiff. It represents the changes between two
@@ -38,3 +39,55 @@ func TopoDiff(a, b NodeSummaries) Diff {
 
 	return diff
 }
+
+// Edge is a directed edge between two rendered nodes.
+type Edge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// EdgeDiff is returned by TopoEdgeDiff. It represents the adjacencies
+// which appeared and disappeared between two NodeSummary maps.
+type EdgeDiff struct {
+	Add    []Edge `json:"add"`
+	Remove []Edge `json:"remove"`
+}
+
+// TopoEdgeDiff gives you the edges to add and remove to get from A to B.
+// Edges are sorted by source, then target.
+func TopoEdgeDiff(a, b NodeSummaries) EdgeDiff {
+	aEdges, bEdges := edgesOf(a), edgesOf(b)
+	diff := EdgeDiff{}
+	for edge := range bEdges {
+		if _, ok := aEdges[edge]; !ok {
+			diff.Add = append(diff.Add, edge)
+		}
+	}
+	for edge := range aEdges {
+		if _, ok := bEdges[edge]; !ok {
+			diff.Remove = append(diff.Remove, edge)
+		}
+	}
+	sortEdges(diff.Add)
+	sortEdges(diff.Remove)
+	return diff
+}
+
+func edgesOf(ns NodeSummaries) map[Edge]struct{} {
+	edges := map[Edge]struct{}{}
+	for id, n := range ns {
+		for _, target := range n.Adjacency {
+			edges[Edge{Source: id, Target: target}] = struct{}{}
+		}
+	}
+	return edges
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Source != edges[j].Source {
+			return edges[i].Source < edges[j].Source
+		}
+		return edges[i].Target < edges[j].Target
+	})
+}
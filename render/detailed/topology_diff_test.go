@@ -108,3 +108,50 @@ func TestTopoDiff(t *testing.T) {
 		}
 	}
 }
+
+func TestTopoEdgeDiff(t *testing.T) {
+	nodes := func(adjacencies map[string][]string) detailed.NodeSummaries {
+		r := detailed.NodeSummaries{}
+		for id, adj := range adjacencies {
+			r[id] = detailed.NodeSummary{
+				BasicNodeSummary: detailed.BasicNodeSummary{ID: id},
+				Adjacency:        report.MakeIDList(adj...),
+			}
+		}
+		return r
+	}
+
+	for _, c := range []struct {
+		label string
+		a, b  detailed.NodeSummaries
+		want  detailed.EdgeDiff
+	}{
+		{
+			label: "no change",
+			a:     nodes(map[string][]string{"a": {"b"}, "b": nil}),
+			b:     nodes(map[string][]string{"a": {"b"}, "b": nil}),
+			want:  detailed.EdgeDiff{},
+		},
+		{
+			label: "edges appear and disappear",
+			a:     nodes(map[string][]string{"a": {"b"}, "b": {"c"}, "c": nil}),
+			b:     nodes(map[string][]string{"a": {"b", "c"}, "c": {"a"}}),
+			want: detailed.EdgeDiff{
+				Add:    []detailed.Edge{{Source: "a", Target: "c"}, {Source: "c", Target: "a"}},
+				Remove: []detailed.Edge{{Source: "b", Target: "c"}},
+			},
+		},
+		{
+			label: "from nothing",
+			a:     nil,
+			b:     nodes(map[string][]string{"b": {"a"}, "a": {"b"}}),
+			want: detailed.EdgeDiff{
+				Add: []detailed.Edge{{Source: "a", Target: "b"}, {Source: "b", Target: "a"}},
+			},
+		},
+	} {
+		if have := detailed.TopoEdgeDiff(c.a, c.b); !reflect.DeepEqual(c.want, have) {
+			t.Errorf("%s - %s", c.label, test.Diff(c.want, have))
+		}
+	}
+}
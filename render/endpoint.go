@@ -29,6 +29,20 @@ func MapEndpoints(f endpointMapFunc, topology string) Renderer {
 	return mapEndpoints{f: f, topology: topology}
 }
 
+// endpointOwners maps the IDs of the endpoints the rendered nodes were
+// mapped from to the IDs of these nodes.
+func endpointOwners(nodes report.Nodes) map[string]string {
+	owners := map[string]string{}
+	for id, n := range nodes {
+		n.Children.ForEach(func(child report.Node) {
+			if child.Topology == report.Endpoint {
+				owners[child.ID] = id
+			}
+		})
+	}
+	return owners
+}
+
 func (e mapEndpoints) Render(ctx context.Context, rpt report.Report) Nodes {
 	local := LocalNetworks(rpt)
 	endpoints := SelectEndpoint.Render(ctx, rpt)
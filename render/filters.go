@@ -267,6 +267,44 @@ var FilterUnconnected = filterUnconnected{onlyPseudo: false}
 // pseudo nodes
 var FilterUnconnectedPseudo = filterUnconnected{onlyPseudo: true}
 
+type filterUDPEdges struct{}
+
+// Transform implements Transformer
+func (filterUDPEdges) Transform(input Nodes) Nodes {
+	owners := endpointOwners(input.Nodes)
+	output := make(report.Nodes, len(input.Nodes))
+	for id, node := range input.Nodes {
+		// target node ID -> whether all connections to it are udp
+		udpOnly := map[string]bool{}
+		node.Children.ForEach(func(child report.Node) {
+			if child.Topology != report.Endpoint {
+				return
+			}
+			protocol, _ := child.Latest.Lookup(report.Protocol)
+			for _, adjacent := range child.Adjacency {
+				if target, ok := owners[adjacent]; ok {
+					only, seen := udpOnly[target]
+					udpOnly[target] = (only || !seen) && protocol == "udp"
+				}
+			}
+		})
+		newAdjacency := report.MakeIDList()
+		for _, dstID := range node.Adjacency {
+			if !udpOnly[dstID] {
+				newAdjacency = newAdjacency.Add(dstID)
+			}
+		}
+		node.Adjacency = newAdjacency
+		output[id] = node
+	}
+	return Nodes{Nodes: output, Filtered: input.Filtered}
+}
+
+// FilterUDPEdges is a transformer that removes the edges made only of udp
+// flows. Edges are attributed to connections through the endpoints the
+// nodes were mapped from; edges which can't be attributed are kept.
+var FilterUDPEdges = filterUDPEdges{}
+
 // Noop allows all nodes through
 func Noop(_ report.Node) bool { return true }
 
@@ -353,6 +391,22 @@ func IsNamespace(namespace string) FilterFunc {
 	}
 }
 
+// KubernetesNamespaceOf returns the Kubernetes namespace of a node, if it is
+// a Kubernetes object or a container of a pod.
+func KubernetesNamespaceOf(n report.Node) (string, bool) {
+	keys := []string{report.KubernetesNamespace, report.DockerLabelPrefix + k8sNamespaceLabel}
+	if n.Topology == report.Namespace {
+		keys = []string{report.KubernetesName}
+	}
+	for _, key := range keys {
+		// Cluster-wide objects have an empty namespace
+		if namespace, ok := n.Latest.Lookup(key); ok && namespace != "" {
+			return namespace, true
+		}
+	}
+	return "", false
+}
+
 // IsTopology checks if the node is from a particular report topology
 func IsTopology(topology string) FilterFunc {
 	return func(n report.Node) bool {
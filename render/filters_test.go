@@ -100,3 +100,34 @@ func TestFilterUnconnectedSelf(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterUDPEdges(t *testing.T) {
+	endpoint := func(id, protocol string, adjacent ...string) report.Node {
+		n := report.MakeNode(id).WithTopology(report.Endpoint).WithAdjacent(adjacent...)
+		if protocol != "" {
+			n = n.WithLatests(map[string]string{report.Protocol: protocol})
+		}
+		return n
+	}
+	node := func(id string, children ...report.Node) report.Node {
+		return report.MakeNode(id).WithChildren(report.MakeNodeSet(children...))
+	}
+	input := render.Nodes{Nodes: report.Nodes{
+		"client": node("client",
+			endpoint(";10.0.0.1;1001", "", ";10.0.0.2;80"),
+			endpoint(";10.0.0.1;1002", "udp", ";10.0.0.2;8125"),
+			endpoint(";10.0.0.1;1003", "udp", ";10.0.0.3;53"),
+		).WithAdjacent("server", "dns", "other"),
+		"server": node("server", endpoint(";10.0.0.2;80", ""), endpoint(";10.0.0.2;8125", "udp")),
+		"dns":    node("dns", endpoint(";10.0.0.3;53", "udp")),
+		"other":  node("other"),
+	}}
+
+	have := render.FilterUDPEdges.Transform(input).Nodes["client"].Adjacency
+	// the server has a tcp connection too, and the edge to the other node
+	// can't be attributed to any connection
+	want := report.MakeIDList("server", "other")
+	if !reflect.DeepEqual(want, have) {
+		t.Error(test.Diff(want, have))
+	}
+}
@@ -9,17 +9,70 @@ import (
 )
 
 // HostRenderer is a Renderer which produces a renderable host
-// graph from the host topology.
+// graph from the host topology, with the metadata of the Kubernetes
+// nodes the hosts run.
 //
 // not memoised
-var HostRenderer = MakeReduce(
+var HostRenderer = kubernetesNodeMetadata{MakeReduce(
 	CustomRenderer{RenderFunc: nodes2Hosts, Renderer: ProcessRenderer},
 	CustomRenderer{RenderFunc: nodes2Hosts, Renderer: ContainerRenderer},
 	CustomRenderer{RenderFunc: nodes2Hosts, Renderer: ContainerImageRenderer},
 	CustomRenderer{RenderFunc: nodes2Hosts, Renderer: PodRenderer},
 	MapEndpoints(endpoint2Host, report.Host),
 	DiskRenderer,
-)
+)}
+
+// kubernetesNodeMetadata merges the metadata of Kubernetes nodes into
+// the hosts running them, matched by node name.
+type kubernetesNodeMetadata struct {
+	Renderer
+}
+
+// Render implements Renderer
+func (k kubernetesNodeMetadata) Render(ctx context.Context, rpt report.Report) Nodes {
+	input := k.Renderer.Render(ctx, rpt)
+	if len(rpt.KubernetesNode.Nodes) == 0 {
+		return input
+	}
+	output := make(report.Nodes, len(input.Nodes))
+	for id, n := range input.Nodes {
+		if kubeNode, ok := KubernetesNodeForHost(rpt, n); ok {
+			for _, key := range kubernetes.NodeHostKeys {
+				if value, timestamp, ok := kubeNode.Latest.LookupEntry(key); ok {
+					n = n.WithLatest(key, timestamp, value)
+				}
+			}
+		}
+		output[id] = n
+	}
+	return Nodes{Nodes: output, Filtered: input.Filtered}
+}
+
+// KubernetesNodeForHost returns the Kubernetes node a host runs, if any.
+func KubernetesNodeForHost(rpt report.Report, host report.Node) (report.Node, bool) {
+	nodeName, ok := host.Latest.Lookup(report.KubernetesNodeName)
+	if !ok {
+		return report.Node{}, false
+	}
+	for _, n := range rpt.KubernetesNode.Nodes {
+		if name, _ := n.Latest.Lookup(kubernetes.Name); name == nodeName {
+			return n, true
+		}
+	}
+	return report.Node{}, false
+}
+
+// hostsByKubernetesNodeName indexes the hosts running Kubernetes nodes by
+// the names of those nodes.
+func hostsByKubernetesNodeName(rpt report.Report) map[string]report.Node {
+	hosts := map[string]report.Node{}
+	for _, h := range rpt.Host.Nodes {
+		if nodeName, ok := h.Latest.Lookup(report.KubernetesNodeName); ok {
+			hosts[nodeName] = h
+		}
+	}
+	return hosts
+}
 
 // nodes2Hosts maps any Nodes to host Nodes.
 //
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"context"
+	"strings"
+
+	"github.com/weaveworks/scope/probe/kubernetes"
+	"github.com/weaveworks/scope/report"
+)
+
+// IngressRenderer is a Renderer which produces a renderable kubernetes
+// ingress graph: each ingress is linked to the services it routes to, and
+// each of those services to the pods backing it. Ingresses whose
+// controllers take connections from the Internet are linked from it.
+var IngressRenderer = ConditionalRenderer(renderKubernetesTopologies, ingressRenderer{})
+
+type ingressRenderer struct{}
+
+// Render implements Renderer
+func (ingressRenderer) Render(ctx context.Context, rpt report.Report) Nodes {
+	services := map[string]report.Node{}
+	loadBalancers := map[string][]report.Node{}
+	for _, n := range rpt.Service.Nodes {
+		namespace, _ := n.Latest.Lookup(kubernetes.Namespace)
+		name, _ := n.Latest.Lookup(kubernetes.Name)
+		services[namespace+"/"+name] = n
+		if addresses, ok := n.Latest.Lookup(kubernetes.LoadBalancerAddresses); ok {
+			for _, address := range strings.Split(addresses, ",") {
+				loadBalancers[address] = append(loadBalancers[address], n)
+			}
+		}
+	}
+
+	// The pods the Internet connects to
+	var internet report.Node
+	internetPods := report.IDList{}
+	if in, ok := PodRenderer.Render(ctx, rpt).Nodes[IncomingInternetID]; ok {
+		internet, internetPods = in, in.Adjacency
+		internet.Adjacency = report.MakeIDList()
+	}
+
+	nodes := report.Nodes{}
+	for id, n := range rpt.Ingress.Nodes {
+		n = n.WithTopology(report.Ingress)
+		namespace, _ := n.Latest.Lookup(kubernetes.Namespace)
+		backends, _ := n.Latest.Lookup(kubernetes.IngressBackends)
+		for _, backend := range strings.Split(backends, report.ScopeDelim) {
+			service, ok := services[namespace+"/"+backend]
+			if !ok {
+				continue
+			}
+			n = n.WithAdjacent(service.ID)
+			if _, ok := nodes[service.ID]; !ok {
+				nodes[service.ID] = serviceWithPods(rpt, service, nodes)
+			}
+		}
+		nodes[id] = n
+		if len(internetPods) > 0 && fromInternet(rpt, n, loadBalancers, internetPods) {
+			internet = internet.WithAdjacent(id)
+		}
+	}
+	if len(internet.Adjacency) > 0 {
+		nodes[IncomingInternetID] = internet
+	}
+	return Nodes{Nodes: nodes}
+}
+
+// fromInternet tells whether the controller of an ingress takes
+// connections from the Internet. Its controller pods are those behind the
+// load balancer services at the addresses in the ingress status.
+// Controllers reached otherwise, e.g. on host ports, are not found.
+func fromInternet(rpt report.Report, ingress report.Node, loadBalancers map[string][]report.Node, internetPods report.IDList) bool {
+	addresses, _ := ingress.Latest.Lookup(kubernetes.PublicIP)
+	for _, address := range strings.Split(addresses, ",") {
+		for _, service := range loadBalancers[address] {
+			for _, podID := range servicePods(rpt, service) {
+				if internetPods.Contains(podID) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// serviceWithPods links a service to the live pods behind it, adding
+// them to nodes. The pods are the ones its endpoints point to or, for
+// probes which don't report endpoints, the ones which have it as parent.
+func serviceWithPods(rpt report.Report, service report.Node, nodes report.Nodes) report.Node {
+	service = service.WithTopology(report.Service)
+	for _, podID := range servicePods(rpt, service) {
+		service = service.WithAdjacent(podID)
+		nodes[podID] = rpt.Pod.Nodes[podID].WithTopology(report.Pod)
+	}
+	return service
+}
+
+// servicePods returns the IDs of the live pods behind a service.
+func servicePods(rpt report.Report, service report.Node) []string {
+	var pods []string
+	endpointPods, hasEndpoints := service.Sets.Lookup(kubernetes.EndpointPods)
+	for podID, pod := range rpt.Pod.Nodes {
+		if state, ok := pod.Latest.Lookup(report.KubernetesState); ok && (state == report.StateDeleted || state == report.StateFailed) {
+			continue
+		}
+		if hasEndpoints {
+			if !endpointPods.Contains(podID) {
+				continue
+			}
+		} else if parents, _ := pod.Parents.Lookup(report.Service); !parents.Contains(service.ID) {
+			continue
+		}
+		pods = append(pods, podID)
+	}
+	return pods
+}
@@ -0,0 +1,136 @@
+package render_test
+
+import (
+	"context"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/weaveworks/scope/probe/kubernetes"
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
+)
+
+func TestIngressRenderer(t *testing.T) {
+	var (
+		rpt       = report.MakeReport()
+		ingressID = report.MakeIngressNodeID("ingress")
+		webID     = report.MakeServiceNodeID("web")
+		apiID     = report.MakeServiceNodeID("api")
+	)
+	service := func(uid, name string, endpointPods ...string) report.Node {
+		n := report.MakeNodeWith(report.MakeServiceNodeID(uid), map[string]string{
+			kubernetes.Name:      name,
+			kubernetes.Namespace: "ns",
+		})
+		if endpointPods != nil {
+			n = n.WithSet(kubernetes.EndpointPods, report.MakeStringSet(endpointPods...))
+		}
+		return n
+	}
+	pod := func(uid, service string) report.Node {
+		return report.MakeNodeWith(report.MakePodNodeID(uid), map[string]string{
+			kubernetes.Name:      uid,
+			kubernetes.Namespace: "ns",
+		}).WithParent(report.Service, service)
+	}
+	// web reports endpoints, so only its ready pods are linked; api
+	// doesn't, so its pods are found through their parents.
+	rpt.Service.AddNode(service("web", "web", report.MakePodNodeID("web-1")))
+	rpt.Service.AddNode(service("api", "api"))
+	rpt.Service.AddNode(service("db", "db"))
+	rpt.Pod.AddNode(pod("web-1", webID))
+	rpt.Pod.AddNode(pod("web-2", webID))
+	rpt.Pod.AddNode(pod("api-1", apiID))
+	rpt.Pod.AddNode(pod("db-1", report.MakeServiceNodeID("db")))
+
+	backend := func(name string) networkingv1.IngressBackend {
+		return networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+			Name: name,
+			Port: networkingv1.ServiceBackendPort{Number: 80},
+		}}
+	}
+	rpt.Ingress.AddNode(kubernetes.NewIngress(&networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "ns", UID: types.UID("ingress")},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/", Backend: backend("web")},
+						{Path: "/api", Backend: backend("api")},
+					},
+				}},
+			}},
+		},
+	}).GetNode(""))
+
+	nodes := render.IngressRenderer.Render(context.Background(), rpt).Nodes
+	for id, want := range map[string][]string{
+		ingressID: {apiID, webID},
+		webID:     {report.MakePodNodeID("web-1")},
+		apiID:     {report.MakePodNodeID("api-1")},
+	} {
+		n, ok := nodes[id]
+		if !ok {
+			t.Fatalf("expected node %s, have %v", id, nodes)
+		}
+		if !n.Adjacency.Equal(report.MakeIDList(want...)) {
+			t.Errorf("expected %s to be adjacent to %v, have %v", id, want, n.Adjacency)
+		}
+	}
+	for _, id := range []string{report.MakeServiceNodeID("db"), report.MakePodNodeID("web-2"), report.MakePodNodeID("db-1")} {
+		if _, ok := nodes[id]; ok {
+			t.Errorf("expected %s not to be rendered", id)
+		}
+	}
+}
+
+func TestIngressRendererInternet(t *testing.T) {
+	var (
+		rpt        = report.MakeReport()
+		exposedID  = report.MakeIngressNodeID("exposed")
+		internalID = report.MakeIngressNodeID("internal")
+		controller = report.MakePodNodeID("controller")
+	)
+	rpt.Pod.AddNode(report.MakeNodeWith(controller, map[string]string{
+		kubernetes.Name:      "controller",
+		kubernetes.Namespace: "ingress",
+		kubernetes.IP:        "10.0.0.9",
+	}))
+	rpt.Service.AddNode(report.MakeNodeWith(report.MakeServiceNodeID("lb"), map[string]string{
+		kubernetes.Name:                  "lb",
+		kubernetes.Namespace:             "ingress",
+		kubernetes.LoadBalancerAddresses: "1.2.3.4",
+	}).WithSet(kubernetes.EndpointPods, report.MakeStringSet(controller)))
+	endpoint := func(id string) report.Node {
+		return report.MakeNodeWith(id, map[string]string{report.HostNodeID: "host;<host>"}).WithTopology(report.Endpoint)
+	}
+	// The Internet's endpoints aren't seen by any probe
+	rpt.Endpoint.AddNode(report.MakeNode(";8.8.8.8;40000").WithTopology(report.Endpoint).WithAdjacent(";10.0.0.9;443"))
+	rpt.Endpoint.AddNode(endpoint(";10.0.0.9;443"))
+	rpt.Host.AddNode(report.MakeNode("host;<host>").WithSets(report.MakeSets().
+		Add(report.HostLocalNetworks, report.MakeStringSet("10.0.0.0/8"))))
+
+	ingress := func(uid, address string) report.Node {
+		return kubernetes.NewIngress(&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: uid, Namespace: "ns", UID: types.UID(uid)},
+		}).GetNode("").WithLatests(map[string]string{kubernetes.PublicIP: address})
+	}
+	rpt.Ingress.AddNode(ingress("exposed", "1.2.3.4"))
+	rpt.Ingress.AddNode(ingress("internal", "10.1.1.1"))
+
+	nodes := render.IngressRenderer.Render(context.Background(), rpt).Nodes
+	internet, ok := nodes[render.IncomingInternetID]
+	if !ok {
+		t.Fatalf("expected the Internet node, have %v", nodes)
+	}
+	if !internet.Adjacency.Equal(report.MakeIDList(exposedID)) {
+		t.Errorf("expected the Internet to connect to %s only, have %v", exposedID, internet.Adjacency)
+	}
+	if _, ok := nodes[internalID]; !ok {
+		t.Errorf("expected node %s", internalID)
+	}
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"context"
+
+	"github.com/weaveworks/scope/probe/kubernetes"
+	"github.com/weaveworks/scope/report"
+)
+
+// JivaVolumeRenderer is a Renderer which produces a renderable graph of
+// the Jiva volumes: each volume stands for its controller pod, and is
+// linked to its replica pods, which are linked to the hosts keeping their
+// data.
+var JivaVolumeRenderer = jivaVolumeRenderer{}
+
+type jivaVolumeRenderer struct{}
+
+// Render implements Renderer
+func (jivaVolumeRenderer) Render(ctx context.Context, rpt report.Report) Nodes {
+	hosts := hostsByKubernetesNodeName(rpt)
+
+	nodes := report.Nodes{}
+	for id, n := range rpt.JivaVolume.Nodes {
+		n = n.WithTopology(report.JivaVolume)
+		controllers, _ := n.Sets.Lookup(kubernetes.JivaControllerPod)
+		for _, podID := range controllers {
+			if pod, ok := rpt.Pod.Nodes[podID]; ok {
+				n.Children = n.Children.Add(pod)
+			}
+		}
+		replicas, _ := n.Sets.Lookup(kubernetes.JivaReplicaPods)
+		for _, podID := range replicas {
+			pod, ok := rpt.Pod.Nodes[podID]
+			if !ok {
+				continue
+			}
+			pod = pod.WithTopology(report.Pod)
+			nodeName, _ := pod.Latest.Lookup(report.KubernetesNodeName)
+			if h, ok := hosts[nodeName]; ok {
+				pod = pod.WithAdjacent(h.ID)
+				nodes[h.ID] = h.WithTopology(report.Host)
+			}
+			n = n.WithAdjacent(podID)
+			nodes[podID] = pod
+		}
+		nodes[id] = n
+	}
+	return Nodes{Nodes: nodes}
+}
+
+// isJivaController tells whether a pod is the controller of a Jiva volume,
+// which is rendered as the volume itself.
+func isJivaController(n report.Node) bool {
+	role, _ := n.Latest.Lookup(report.KubernetesJivaRole)
+	return role == kubernetes.JivaControllerRole
+}
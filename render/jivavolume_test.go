@@ -0,0 +1,55 @@
+package render_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/weaveworks/scope/probe/kubernetes"
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
+)
+
+func TestJivaVolumeRenderer(t *testing.T) {
+	var (
+		rpt          = report.MakeReport()
+		pvID         = report.MakePersistentVolumeNodeID("pv")
+		volumeID     = report.MakeJivaVolumeNodeID("pvc-jiva")
+		controllerID = report.MakePodNodeID("ctrl")
+		replicaID    = report.MakePodNodeID("rep")
+		hostA        = report.MakeHostNodeID("host-a")
+	)
+	rpt.Host.AddNode(report.MakeNodeWith(hostA, map[string]string{report.KubernetesNodeName: "node-a"}))
+	rpt.Host.AddNode(report.MakeNodeWith(report.MakeHostNodeID("host-b"), map[string]string{report.KubernetesNodeName: "node-b"}))
+	rpt.PersistentVolume.AddNode(report.MakeNodeWith(pvID, map[string]string{kubernetes.Name: "pvc-jiva"}))
+	rpt.Pod.AddNode(report.MakeNodeWith(controllerID, map[string]string{
+		kubernetes.VolumeName: "pvc-jiva",
+		kubernetes.VolumePod:  "true",
+		kubernetes.JivaRole:   kubernetes.JivaControllerRole,
+	}))
+	rpt.Pod.AddNode(report.MakeNodeWith(replicaID, map[string]string{
+		kubernetes.VolumePod:      "true",
+		kubernetes.JivaRole:       kubernetes.JivaReplicaRole,
+		report.KubernetesNodeName: "node-a",
+	}))
+	rpt.JivaVolume.AddNode(report.MakeNodeWith(volumeID, map[string]string{kubernetes.Name: "pvc-jiva"}).
+		WithSet(kubernetes.JivaControllerPod, report.MakeStringSet(controllerID)).
+		WithSet(kubernetes.JivaReplicaPods, report.MakeStringSet(replicaID)))
+
+	nodes := render.KubernetesVolumesRenderer.Render(context.Background(), rpt).Nodes
+	for id, want := range map[string][]string{
+		pvID:      {volumeID},
+		volumeID:  {replicaID},
+		replicaID: {hostA},
+	} {
+		n, ok := nodes[id]
+		if !ok {
+			t.Fatalf("expected node %s, have %v", id, nodes)
+		}
+		if !n.Adjacency.Equal(report.MakeIDList(want...)) {
+			t.Errorf("expected %s to be adjacent to %v, have %v", id, want, n.Adjacency)
+		}
+	}
+	if _, ok := nodes[controllerID]; ok {
+		t.Errorf("expected the controller pod to be rendered as its Jiva volume")
+	}
+}
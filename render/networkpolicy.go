@@ -0,0 +1,184 @@
+package render
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/weaveworks/scope/probe/kubernetes"
+	"github.com/weaveworks/scope/report"
+)
+
+// NetworkPolicyRenderer is a Renderer which produces a renderable
+// kubernetes network policy graph, with the pods each policy selects as
+// children.
+var NetworkPolicyRenderer = ConditionalRenderer(renderKubernetesTopologies, networkPolicyRenderer{})
+
+type networkPolicyRenderer struct{}
+
+// Render implements Renderer
+func (networkPolicyRenderer) Render(ctx context.Context, rpt report.Report) Nodes {
+	peers := policyPeers(rpt)
+	nodes := make(report.Nodes, len(rpt.NetworkPolicy.Nodes))
+	for id, n := range rpt.NetworkPolicy.Nodes {
+		n = n.WithTopology(report.NetworkPolicy)
+		if policy, ok := kubernetes.NetworkPolicyRulesFromNode(n); ok {
+			for podID, peer := range peers {
+				if policy.Selects(peer) {
+					n = n.WithChild(rpt.Pod.Nodes[podID])
+					n.Counters = n.Counters.Add(report.Pod, 1)
+				}
+			}
+		}
+		nodes[id] = n
+	}
+	return Nodes{Nodes: nodes}
+}
+
+// annotateNetworkPolicies records, on each rendered pod, which of the pods
+// it connects to the network policies allow it to reach, and which they
+// deny. Edges are judged on the connections they were mapped from; an
+// edge is denied if any of its connections is. Edges without known
+// connections are denied if the policies allow no traffic at all.
+type annotateNetworkPolicies struct {
+	Renderer
+}
+
+type connection struct {
+	port     int
+	protocol string
+}
+
+// Render implements Renderer
+func (a annotateNetworkPolicies) Render(ctx context.Context, rpt report.Report) Nodes {
+	input := a.Renderer.Render(ctx, rpt)
+	var policies []kubernetes.NetworkPolicyRules
+	for _, n := range rpt.NetworkPolicy.Nodes {
+		if policy, ok := kubernetes.NetworkPolicyRulesFromNode(n); ok {
+			policies = append(policies, policy)
+		}
+	}
+	if len(policies) == 0 {
+		return input
+	}
+
+	peers := policyPeers(rpt)
+	owners := endpointOwners(input.Nodes)
+	output := make(report.Nodes, len(input.Nodes))
+	for id, n := range input.Nodes {
+		src, ok := peers[id]
+		if !ok {
+			output[id] = n
+			continue
+		}
+		connections := map[string][]connection{}
+		n.Children.ForEach(func(child report.Node) {
+			if child.Topology != report.Endpoint {
+				return
+			}
+			protocol, ok := child.Latest.Lookup(report.Protocol)
+			if !ok {
+				protocol = "tcp"
+			}
+			for _, adjacent := range child.Adjacency {
+				target, ok := owners[adjacent]
+				if !ok {
+					continue
+				}
+				if _, _, port, ok := report.ParseEndpointNodeID(adjacent); ok {
+					p, _ := strconv.Atoi(port)
+					connections[target] = append(connections[target], connection{p, protocol})
+				}
+			}
+		})
+
+		var allowed, denied []string
+		for _, target := range n.Adjacency {
+			dst, ok := peers[target]
+			if !ok {
+				continue
+			}
+			isDenied := len(connections[target]) == 0 &&
+				!kubernetes.NetworkPolicyAllows(policies, src, dst, kubernetes.AnyPort, "")
+			for _, c := range connections[target] {
+				if !kubernetes.NetworkPolicyAllows(policies, src, dst, c.port, c.protocol) {
+					isDenied = true
+					break
+				}
+			}
+			if isDenied {
+				denied = append(denied, target)
+			} else {
+				allowed = append(allowed, target)
+			}
+		}
+		if len(allowed) > 0 {
+			n = n.WithSet(report.KubernetesNetworkPolicyAllowed, report.MakeStringSet(allowed...))
+		}
+		if len(denied) > 0 {
+			n = n.WithSet(report.KubernetesNetworkPolicyDenied, report.MakeStringSet(denied...))
+		}
+		output[id] = n
+	}
+	return Nodes{Nodes: output, Filtered: input.Filtered}
+}
+
+// policyPeers returns the pods of the report, by node ID, as seen by
+// network policies.
+func policyPeers(rpt report.Report) map[string]kubernetes.PolicyPeer {
+	namespaceLabels := map[string]map[string]string{}
+	for _, n := range rpt.Namespace.Nodes {
+		if name, ok := n.Latest.Lookup(kubernetes.Name); ok {
+			namespaceLabels[name] = prefixedLabels(n, kubernetes.LabelPrefix)
+		}
+	}
+	peers := make(map[string]kubernetes.PolicyPeer, len(rpt.Pod.Nodes))
+	for id, n := range rpt.Pod.Nodes {
+		if state, ok := n.Latest.Lookup(report.KubernetesState); ok && (state == report.StateDeleted || state == report.StateFailed) {
+			continue
+		}
+		namespace, _ := n.Latest.Lookup(kubernetes.Namespace)
+		ip, _ := n.Latest.Lookup(kubernetes.IP)
+		peers[id] = kubernetes.PolicyPeer{
+			Namespace:       namespace,
+			Labels:          prefixedLabels(n, kubernetes.LabelPrefix),
+			NamespaceLabels: namespaceLabels[namespace],
+			IP:              ip,
+		}
+	}
+	return peers
+}
+
+func prefixedLabels(n report.Node, prefix string) map[string]string {
+	labels := map[string]string{}
+	n.Latest.ForEach(func(key string, _ time.Time, value string) {
+		if strings.HasPrefix(key, prefix) {
+			labels[strings.TrimPrefix(key, prefix)] = value
+		}
+	})
+	return labels
+}
+
+type filterAllowedEdges struct{}
+
+// Transform implements Transformer
+func (filterAllowedEdges) Transform(input Nodes) Nodes {
+	output := make(report.Nodes, len(input.Nodes))
+	for id, node := range input.Nodes {
+		denied, _ := node.Sets.Lookup(report.KubernetesNetworkPolicyDenied)
+		newAdjacency := report.MakeIDList()
+		for _, dstID := range node.Adjacency {
+			if denied.Contains(dstID) {
+				newAdjacency = newAdjacency.Add(dstID)
+			}
+		}
+		node.Adjacency = newAdjacency
+		output[id] = node
+	}
+	return Nodes{Nodes: output, Filtered: input.Filtered}
+}
+
+// FilterAllowedEdges is a transformer that removes the edges the network
+// policies allow, leaving the edges they would deny.
+var FilterAllowedEdges = filterAllowedEdges{}
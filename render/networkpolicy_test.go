@@ -0,0 +1,133 @@
+package render_test
+
+import (
+	"context"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/weaveworks/scope/probe/kubernetes"
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
+)
+
+func networkPolicyReport() report.Report {
+	rpt := report.MakeReport()
+	pod := func(uid, app, ip string) {
+		rpt.Pod.AddNode(report.MakeNodeWith(report.MakePodNodeID(uid), map[string]string{
+			kubernetes.Name:      uid,
+			kubernetes.Namespace: "ns",
+			kubernetes.IP:        ip,
+		}).AddPrefixPropertyList(kubernetes.LabelPrefix, map[string]string{"app": app}))
+	}
+	pod("client", "client", "10.0.0.1")
+	pod("server", "server", "10.0.0.2")
+	pod("other", "other", "10.0.0.3")
+	endpoint := func(id string) report.Node {
+		return report.MakeNodeWith(id, map[string]string{report.HostNodeID: "host;<host>"}).WithTopology(report.Endpoint)
+	}
+	connect := func(from, to string) {
+		rpt.Endpoint.AddNode(endpoint(from).WithAdjacent(to))
+		rpt.Endpoint.AddNode(endpoint(to))
+	}
+	connect(";10.0.0.1;1001", ";10.0.0.2;80")
+	connect(";10.0.0.3;1002", ";10.0.0.2;80")
+	connect(";10.0.0.1;1003", ";10.0.0.3;8080")
+
+	port := intstr.FromInt(80)
+	policy := kubernetes.NewNetworkPolicy(&networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "server", Namespace: "ns", UID: types.UID("policy")},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "server"}},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "client"}}}},
+				Ports: []networkingv1.NetworkPolicyPort{{Port: &port}},
+			}},
+		},
+	})
+	rpt.NetworkPolicy.AddNode(policy.GetNode(""))
+	return rpt
+}
+
+func TestPodRendererNetworkPolicies(t *testing.T) {
+	var (
+		client = report.MakePodNodeID("client")
+		server = report.MakePodNodeID("server")
+		other  = report.MakePodNodeID("other")
+		rpt    = networkPolicyReport()
+		nodes  = render.PodRenderer.Render(context.Background(), rpt)
+	)
+
+	for _, c := range []struct {
+		from, to, key string
+	}{
+		{client, server, report.KubernetesNetworkPolicyAllowed},
+		{client, other, report.KubernetesNetworkPolicyAllowed},
+		{other, server, report.KubernetesNetworkPolicyDenied},
+	} {
+		set, _ := nodes.Nodes[c.from].Sets.Lookup(c.key)
+		if !set.Contains(c.to) {
+			t.Errorf("expected %s -> %s in %s, have %v", c.from, c.to, c.key, nodes.Nodes[c.from].Sets)
+		}
+	}
+
+	denied := render.FilterAllowedEdges.Transform(nodes)
+	if have := denied.Nodes[client].Adjacency; len(have) != 0 {
+		t.Errorf("expected allowed edges to be removed, have %v", have)
+	}
+	if have := denied.Nodes[other].Adjacency; len(have) != 1 || have[0] != server {
+		t.Errorf("expected denied edge to be kept, have %v", have)
+	}
+}
+
+func TestNetworkPolicyRenderer(t *testing.T) {
+	nodes := render.NetworkPolicyRenderer.Render(context.Background(), networkPolicyReport()).Nodes
+	policy, ok := nodes[report.MakeNetworkPolicyNodeID("policy")]
+	if !ok {
+		t.Fatalf("expected network policy node, have %v", nodes)
+	}
+	if count, _ := policy.Counters.Lookup(report.Pod); count != 1 {
+		t.Errorf("expected policy to select 1 pod, have %d", count)
+	}
+	if _, ok := policy.Children.Lookup(report.MakePodNodeID("server")); !ok {
+		t.Errorf("expected server pod as child, have %v", policy.Children)
+	}
+}
+
+// Edges whose connections are unknown are judged on any port.
+func TestNetworkPolicyAllowsAnyPort(t *testing.T) {
+	var policies []kubernetes.NetworkPolicyRules
+	for _, n := range networkPolicyReport().NetworkPolicy.Nodes {
+		policy, ok := kubernetes.NetworkPolicyRulesFromNode(n)
+		if !ok {
+			t.Fatalf("expected network policy rules in %v", n)
+		}
+		policies = append(policies, policy)
+	}
+	peer := func(app, ip string) kubernetes.PolicyPeer {
+		return kubernetes.PolicyPeer{Namespace: "ns", Labels: map[string]string{"app": app}, IP: ip}
+	}
+	var (
+		client = peer("client", "10.0.0.1")
+		server = peer("server", "10.0.0.2")
+		other  = peer("other", "10.0.0.3")
+	)
+
+	for _, c := range []struct {
+		from, to kubernetes.PolicyPeer
+		want     bool
+	}{
+		// server is isolated, and only admits client
+		{client, server, true},
+		{other, server, false},
+		// other isn't isolated
+		{client, other, true},
+	} {
+		if have := kubernetes.NetworkPolicyAllows(policies, c.from, c.to, kubernetes.AnyPort, ""); have != c.want {
+			t.Errorf("%s -> %s: want %v, have %v", c.from.Labels["app"], c.to.Labels["app"], c.want, have)
+		}
+	}
+}
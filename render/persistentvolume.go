@@ -9,8 +9,11 @@ import (
 
 // KubernetesVolumesRenderer is a Renderer which combines all Kubernetes
 // volumes components such as stateful Pods, Persistent Volume, Persistent Volume Claim, Storage Class.
-var KubernetesVolumesRenderer = MakeReduce(
+var KubernetesVolumesRenderer = volumeStatsRenderer{MakeReduce(
 	CStorVolumeRenderer,
+	JivaVolumeRenderer,
+	CStorVolumeConfigRenderer,
+	PVToHostRenderer,
 	VolumesRenderer,
 	PodToVolumeRenderer,
 	PVCToStorageClassRenderer,
@@ -25,14 +28,68 @@ var KubernetesVolumesRenderer = MakeReduce(
 	MakeFilter(
 		func(n report.Node) bool {
 			value, _ := n.Latest.Lookup(report.KubernetesVolumePod)
-			if value == "true" {
+			if value == "true" && !isJivaController(n) {
 				return true
 			}
 			return false
 		},
 		PodRenderer,
 	),
-)
+)}
+
+// volumeStatsRenderer renders with the volume statistics of host probes
+// attached to the claims and volumes they are of.
+type volumeStatsRenderer struct {
+	Renderer
+}
+
+// Render implements Renderer
+func (r volumeStatsRenderer) Render(ctx context.Context, rpt report.Report) Nodes {
+	return r.Renderer.Render(ctx, AttachVolumeStats(rpt))
+}
+
+// AttachVolumeStats moves the usage statistics of persistent volume claims,
+// which host probes report on nodes of their own as kubelet only knows the
+// namespace and name of claims, onto the claims and the volumes bound to
+// them. The report is left as is, as reports are shared.
+func AttachVolumeStats(rpt report.Report) report.Report {
+	stats := map[string]report.Metrics{}
+	for id, n := range rpt.PersistentVolumeClaim.Nodes {
+		if claim, ok := report.ParseVolumeClaimStatsNodeID(id); ok {
+			stats[claim] = n.Metrics
+		}
+	}
+	if len(stats) == 0 {
+		return rpt
+	}
+	claims := make(report.Nodes, len(rpt.PersistentVolumeClaim.Nodes)-len(stats))
+	volumeStats := map[string]report.Metrics{}
+	for id, n := range rpt.PersistentVolumeClaim.Nodes {
+		if _, ok := report.ParseVolumeClaimStatsNodeID(id); ok {
+			continue
+		}
+		namespace, _ := n.Latest.Lookup(report.KubernetesNamespace)
+		name, _ := n.Latest.Lookup(report.KubernetesName)
+		if metrics, ok := stats[namespace+"/"+name]; ok {
+			n = n.WithMetrics(metrics)
+			if volume, ok := n.Latest.Lookup(report.KubernetesVolumeName); ok {
+				volumeStats[volume] = metrics
+			}
+		}
+		claims[id] = n
+	}
+	rpt.PersistentVolumeClaim.Nodes = claims
+
+	volumes := make(report.Nodes, len(rpt.PersistentVolume.Nodes))
+	for id, n := range rpt.PersistentVolume.Nodes {
+		if name, _ := n.Latest.Lookup(report.KubernetesName); volumeStats[name] != nil {
+			n = n.WithMetrics(volumeStats[name])
+		}
+		volumes[id] = n
+	}
+	rpt.PersistentVolume.Nodes = volumes
+	return rpt
+}
 
 // VolumesRenderer is a Renderer which produces a renderable kubernetes PV & PVC
 // graph by merging the pods graph and the Persistent Volume topology.
@@ -147,7 +204,7 @@ func (v pvToControllerRenderer) Render(ctx context.Context, rpt report.Report) N
 		volumeClaimName, _ := p.Latest.Lookup(report.KubernetesVolumeClaim)
 		for _, podNode := range rpt.Pod.Nodes {
 			podVolumeName, _ := podNode.Latest.Lookup(report.KubernetesVolumeName)
-			if volumeName == podVolumeName {
+			if volumeName == podVolumeName && !isJivaController(podNode) {
 				p.Adjacency = p.Adjacency.Add(podNode.ID)
 				p.Children = p.Children.Add(podNode)
 			}
@@ -177,6 +234,11 @@ func (v pvToControllerRenderer) Render(ctx context.Context, rpt report.Report) N
 			}
 		}
 
+		if jivaNode, ok := rpt.JivaVolume.Nodes[report.MakeJivaVolumeNodeID(volumeName)]; ok {
+			p.Adjacency = p.Adjacency.Add(jivaNode.ID)
+			p.Children = p.Children.Add(jivaNode)
+		}
+
 		_, casOk := p.Latest.Lookup(report.KubernetesCASType)
 		bdcNameFromPV, bdcOk := p.Latest.Lookup(report.KubernetesBlockDeviceClaimName)
 		if casOk && bdcOk {
@@ -197,6 +259,27 @@ func (v pvToControllerRenderer) Render(ctx context.Context, rpt report.Report) N
 	return Nodes{Nodes: nodes}
 }
 
+// PVToHostRenderer is a Renderer which links local persistent volumes, such
+// as OpenEBS LocalPV ones, to the host they are pinned to.
+var PVToHostRenderer = pvToHostRenderer{}
+
+// pvToHostRenderer is a Renderer to render local PVs & their hosts.
+type pvToHostRenderer struct{}
+
+// Render renders the local PV & host nodes with adjacency.
+func (v pvToHostRenderer) Render(ctx context.Context, rpt report.Report) Nodes {
+	nodes := make(report.Nodes)
+	hosts := hostsByKubernetesNodeName(rpt)
+	for pvNodeID, p := range rpt.PersistentVolume.Nodes {
+		hostName, _ := p.Latest.Lookup(report.KubernetesHostName)
+		if h, ok := hosts[hostName]; ok {
+			nodes[pvNodeID] = p.WithTopology(report.PersistentVolume).WithAdjacent(h.ID)
+			nodes[h.ID] = h.WithTopology(report.Host)
+		}
+	}
+	return Nodes{Nodes: nodes}
+}
+
 // VolumeSnapshotRenderer is a renderer which produces a renderable Kubernetes Volume Snapshot and Volume Snapshot Data
 var VolumeSnapshotRenderer = volumeSnapshotRenderer{}
 
@@ -0,0 +1,52 @@
+package render_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
+)
+
+func TestAttachVolumeStats(t *testing.T) {
+	var (
+		rpt    = report.MakeReport()
+		now    = time.Now()
+		used   = report.Metrics{report.KubernetesVolumeUsedBytes: report.MakeSingletonMetric(now, 400)}
+		claim  = report.MakePersistentVolumeClaimNodeID("data")
+		other  = report.MakePersistentVolumeClaimNodeID("other")
+		volume = report.MakePersistentVolumeNodeID("pvc-data")
+	)
+	rpt.PersistentVolumeClaim.AddNode(report.MakeNodeWith(claim, map[string]string{
+		report.KubernetesNamespace:  "ping",
+		report.KubernetesName:       "data",
+		report.KubernetesVolumeName: "pvc-data",
+	}))
+	rpt.PersistentVolumeClaim.AddNode(report.MakeNodeWith(other, map[string]string{
+		report.KubernetesNamespace: "pong",
+		report.KubernetesName:      "data",
+	}))
+	rpt.PersistentVolume.AddNode(report.MakeNodeWith(volume, map[string]string{
+		report.KubernetesName: "pvc-data",
+	}))
+	rpt.PersistentVolumeClaim.AddNode(report.MakeNodeWith(report.MakeVolumeClaimStatsNodeID("ping/data"), map[string]string{
+		report.KubernetesNamespace: "ping",
+		report.KubernetesName:      "data",
+	}).WithMetrics(used))
+
+	attached := render.AttachVolumeStats(rpt)
+	if have := len(attached.PersistentVolumeClaim.Nodes); have != 2 {
+		t.Errorf("Expected the statistics nodes to be removed, have %d claims", have)
+	}
+	for _, n := range []report.Node{attached.PersistentVolumeClaim.Nodes[claim], attached.PersistentVolume.Nodes[volume]} {
+		if _, ok := n.Metrics.Lookup(report.KubernetesVolumeUsedBytes); !ok {
+			t.Errorf("Expected %s to have the volume statistics, have %v", n.ID, n.Metrics)
+		}
+	}
+	if _, ok := attached.PersistentVolumeClaim.Nodes[other].Metrics.Lookup(report.KubernetesVolumeUsedBytes); ok {
+		t.Errorf("Expected no statistics for a claim of another namespace")
+	}
+	if have := len(rpt.PersistentVolumeClaim.Nodes); have != 3 {
+		t.Errorf("Expected the report not to be modified, got %d claims", have)
+	}
+}
@@ -29,6 +29,7 @@ func renderKubernetesTopologies(rpt report.Report) bool {
 		&rpt.PersistentVolumeClaim,
 		&rpt.StorageClass,
 		&rpt.Job,
+		&rpt.GenericController,
 	}
 	for _, t := range topologies {
 		if len(t.Nodes) > 0 {
@@ -44,9 +45,10 @@ func isPauseContainer(n report.Node) bool {
 }
 
 // PodRenderer is a Renderer which produces a renderable kubernetes
-// graph by merging the container graph and the pods topology.
+// graph by merging the container graph and the pods topology. Edges
+// between pods are annotated as allowed or denied by the network policies.
 var PodRenderer = Memoise(ConditionalRenderer(renderKubernetesTopologies,
-	MakeFilter(
+	annotateNetworkPolicies{MakeFilter(
 		func(n report.Node) bool {
 			state, ok := n.Latest.Lookup(report.KubernetesState)
 			return !ok || !(state == report.StateDeleted || state == report.StateFailed)
@@ -66,7 +68,7 @@ var PodRenderer = Memoise(ConditionalRenderer(renderKubernetesTopologies,
 			),
 			ConnectionJoin(MapPod2IP, report.Pod),
 		),
-	),
+	)},
 ))
 
 // Pods are not tagged with a Host parent, but their container children are.
@@ -110,7 +112,7 @@ var PodServiceRenderer = ConditionalRenderer(renderKubernetesTopologies,
 // not memoised
 var KubeControllerRenderer = ConditionalRenderer(renderKubernetesTopologies,
 	renderParents(
-		report.Pod, []string{report.Deployment, report.DaemonSet, report.StatefulSet, report.CronJob, report.Job}, UnmanagedID,
+		report.Pod, []string{report.Deployment, report.DaemonSet, report.StatefulSet, report.CronJob, report.Job, report.GenericController}, UnmanagedID,
 		PodRenderer,
 	),
 )
@@ -66,3 +66,32 @@ func TestPodServiceFilterRenderer(t *testing.T) {
 		t.Error(test.Diff(want, have))
 	}
 }
+
+func TestKubeControllerRendererGenericControllers(t *testing.T) {
+	var (
+		rpt       = report.MakeReport()
+		rolloutID = report.MakeGenericControllerNodeID("rollout")
+		managedID = report.MakePodNodeID("managed")
+		bareID    = report.MakePodNodeID("bare")
+	)
+	rpt.GenericController.AddNode(report.MakeNodeWith(rolloutID, map[string]string{
+		report.KubernetesName:     "pong",
+		report.KubernetesNodeType: "Rollout",
+	}))
+	rpt.Pod.AddNode(report.MakeNodeWith(managedID, map[string]string{report.KubernetesName: "pong-5d8f-x"}).
+		WithParent(report.GenericController, rolloutID))
+	rpt.Pod.AddNode(report.MakeNodeWith(bareID, map[string]string{report.KubernetesName: "bare"}))
+
+	nodes := render.KubeControllerRenderer.Render(context.Background(), rpt).Nodes
+	rollout, ok := nodes[rolloutID]
+	if !ok {
+		t.Fatalf("expected the rollout to be rendered, have %v", nodes)
+	}
+	if _, ok := rollout.Children.Lookup(managedID); !ok {
+		t.Errorf("expected the rollout to contain its pod, have %v", rollout.Children)
+	}
+	unmanaged := nodes[render.MakePseudoNodeID(render.UnmanagedID, "")]
+	if _, ok := unmanaged.Children.Lookup(bareID); !ok || unmanaged.Children.Size() != 1 {
+		t.Errorf("expected only the pod without a controller to be unmanaged, have %v", unmanaged.Children)
+	}
+}
@@ -0,0 +1,99 @@
+package render
+
+import (
+	"github.com/weaveworks/scope/probe/docker"
+	"github.com/weaveworks/scope/probe/kubernetes"
+	"github.com/weaveworks/scope/report"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// podResources are the resources pods request and are limited to, with
+// the metrics of their usage by containers.
+var podResources = []struct {
+	metric, request, limit string
+}{
+	{docker.CPUTotalUsage, kubernetes.CPURequest, kubernetes.CPULimit},
+	{docker.MemoryUsage, kubernetes.MemoryRequest, kubernetes.MemoryLimit},
+}
+
+// IsOverLimit checks if a pod uses as much CPU or memory as it is limited
+// to.
+func IsOverLimit(n report.Node) bool {
+	for _, r := range podResources {
+		usage, ok := podUsage(n, r.metric)
+		if !ok {
+			continue
+		}
+		if limit, ok := latestQuantity(n, r.limit); ok && usage >= limit {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUnderRequested checks if a pod uses more CPU or memory than it
+// requests. Pods using resources they don't request at all are
+// under-requested.
+func IsUnderRequested(n report.Node) bool {
+	for _, r := range podResources {
+		usage, ok := podUsage(n, r.metric)
+		if !ok {
+			continue
+		}
+		if request, _ := latestQuantity(n, r.request); usage > request {
+			return true
+		}
+	}
+	return false
+}
+
+// podUsage returns the latest usage of a resource by the containers of a
+// pod, in the units of its requests: CPUs or bytes. Docker reports the CPU
+// usage of containers as a percentage of all the CPUs of their host, which
+// is converted using the CPU capacity of the node of the pod.
+func podUsage(n report.Node, metric string) (float64, bool) {
+	if n.Topology != report.Pod {
+		return 0, false
+	}
+	scale := 1.0
+	if metric == docker.CPUTotalUsage {
+		capacity, ok := latestQuantity(n, kubernetes.NodeCPUCapacity)
+		if !ok {
+			return 0, false
+		}
+		scale = capacity / 100
+	}
+	var (
+		usage float64
+		found bool
+	)
+	n.Children.ForEach(func(child report.Node) {
+		if child.Topology != report.Container {
+			return
+		}
+		m, ok := child.Metrics.Lookup(metric)
+		if !ok {
+			return
+		}
+		if s, ok := m.LastSample(); ok {
+			usage += s.Value * scale
+			found = true
+		}
+	})
+	return usage, found
+}
+
+// latestQuantity returns a Kubernetes resource quantity of a node, such
+// as "250m" or "64Mi".
+func latestQuantity(n report.Node, key string) (float64, bool) {
+	value, ok := n.Latest.Lookup(key)
+	if !ok {
+		return 0, false
+	}
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, false
+	}
+	return float64(q.MilliValue()) / 1000, true
+}
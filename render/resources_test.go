@@ -0,0 +1,94 @@
+package render_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/weaveworks/scope/probe/docker"
+	"github.com/weaveworks/scope/probe/kubernetes"
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
+)
+
+func TestPodResourceFilters(t *testing.T) {
+	now := time.Now()
+	container := func(id string, cpuPercent, memory float64) report.Node {
+		return report.MakeNode(id).WithTopology(report.Container).
+			WithMetric(docker.CPUTotalUsage, report.MakeSingletonMetric(now, cpuPercent)).
+			WithMetric(docker.MemoryUsage, report.MakeSingletonMetric(now, memory))
+	}
+	pod := func(latests map[string]string, children ...report.Node) report.Node {
+		n := report.MakeNodeWith(report.MakePodNodeID("pod"), latests).WithTopology(report.Pod)
+		for _, c := range children {
+			n = n.WithChild(c)
+		}
+		return n
+	}
+	// 10% of 4 CPUs is 400m, and both containers use 64Mi
+	containers := []report.Node{container("a", 6, 32*1024*1024), container("b", 4, 32*1024*1024)}
+
+	for _, tc := range []struct {
+		name                      string
+		latests                   map[string]string
+		overLimit, underRequested bool
+	}{
+		{
+			name: "within requests and limits",
+			latests: map[string]string{
+				kubernetes.NodeCPUCapacity: "4",
+				kubernetes.CPURequest:      "500m",
+				kubernetes.CPULimit:        "1",
+				kubernetes.MemoryRequest:   "128Mi",
+				kubernetes.MemoryLimit:     "256Mi",
+			},
+		},
+		{
+			name: "over its CPU limit",
+			latests: map[string]string{
+				kubernetes.NodeCPUCapacity: "4",
+				kubernetes.CPURequest:      "300m",
+				kubernetes.CPULimit:        "300m",
+				kubernetes.MemoryRequest:   "128Mi",
+			},
+			overLimit:      true,
+			underRequested: true,
+		},
+		{
+			name: "over its memory request",
+			latests: map[string]string{
+				kubernetes.NodeCPUCapacity: "4",
+				kubernetes.CPURequest:      "500m",
+				kubernetes.MemoryRequest:   "32Mi",
+				kubernetes.MemoryLimit:     "256Mi",
+			},
+			underRequested: true,
+		},
+		{
+			name: "without requests",
+			latests: map[string]string{
+				kubernetes.NodeCPUCapacity: "4",
+			},
+			underRequested: true,
+		},
+		{
+			name: "with an unknown CPU capacity",
+			latests: map[string]string{
+				kubernetes.CPURequest:    "100m",
+				kubernetes.CPULimit:      "100m",
+				kubernetes.MemoryRequest: "128Mi",
+			},
+		},
+	} {
+		n := pod(tc.latests, containers...)
+		if have := render.IsOverLimit(n); have != tc.overLimit {
+			t.Errorf("%s: expected over limit to be %v", tc.name, tc.overLimit)
+		}
+		if have := render.IsUnderRequested(n); have != tc.underRequested {
+			t.Errorf("%s: expected under requested to be %v", tc.name, tc.underRequested)
+		}
+	}
+
+	if render.IsUnderRequested(pod(nil)) {
+		t.Errorf("expected a pod without metrics not to be under requested")
+	}
+}
@@ -31,6 +31,7 @@ var (
 	SelectStatefulSet           = TopologySelector(report.StatefulSet)
 	SelectCronJob               = TopologySelector(report.CronJob)
 	SelectJob                   = TopologySelector(report.Job)
+	SelectGenericController     = TopologySelector(report.GenericController)
 	SelectECSTask               = TopologySelector(report.ECSTask)
 	SelectECSService            = TopologySelector(report.ECSService)
 	SelectSwarmService          = TopologySelector(report.SwarmService)
@@ -49,4 +50,7 @@ var (
 	SelectCsiVolumeSnapshot     = TopologySelector(report.CsiVolumeSnapshot)
 	SelectVolumeSnapshotClass   = TopologySelector(report.VolumeSnapshotClass)
 	SelectVolumeSnapshotContent = TopologySelector(report.VolumeSnapshotContent)
+	SelectNetworkPolicy         = TopologySelector(report.NetworkPolicy)
+	SelectIngress               = TopologySelector(report.Ingress)
+	SelectCSIDriver             = TopologySelector(report.CSIDriver)
 )
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"sort"
+
+	"github.com/weaveworks/scope/report"
+)
+
+// Throughput is the rate of traffic over a set of connections.
+type Throughput struct {
+	BytesPerSecond   float64 `json:"bytesPerSecond"`
+	PacketsPerSecond float64 `json:"packetsPerSecond"`
+}
+
+// Add returns the sum of two throughputs.
+func (t Throughput) Add(other Throughput) Throughput {
+	return Throughput{
+		BytesPerSecond:   t.BytesPerSecond + other.BytesPerSecond,
+		PacketsPerSecond: t.PacketsPerSecond + other.PacketsPerSecond,
+	}
+}
+
+// EndpointThroughput returns the throughput of the connection made from an
+// endpoint node to an adjacent endpoint, derived from the byte and packet
+// counters of that flow reported over the window. It returns false when
+// the counters don't have at least two samples.
+func EndpointThroughput(n report.Node, adjacentID string) (Throughput, bool) {
+	bytes, ok := counterRate(n, report.MakeFlowCounterKey(report.EndpointBytes, adjacentID))
+	if !ok {
+		return Throughput{}, false
+	}
+	packets, _ := counterRate(n, report.MakeFlowCounterKey(report.EndpointPackets, adjacentID))
+	return Throughput{BytesPerSecond: bytes, PacketsPerSecond: packets}, true
+}
+
+// counterRate returns the per-second increase of a counter metric between
+// its first and last samples.
+func counterRate(n report.Node, key string) (float64, bool) {
+	metric, ok := n.Metrics.Lookup(key)
+	if !ok || metric.Len() < 2 {
+		return 0, false
+	}
+	first, last := metric.Samples[0], metric.Samples[metric.Len()-1]
+	seconds := last.Timestamp.Sub(first.Timestamp).Seconds()
+	if seconds <= 0 {
+		return 0, false
+	}
+	if last.Value < first.Value {
+		// the counters were reset, e.g. the 4-tuple was reused
+		return 0, true
+	}
+	return (last.Value - first.Value) / seconds, true
+}
+
+// EdgeThroughput is the throughput of an edge between two rendered nodes.
+type EdgeThroughput struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Throughput
+}
+
+// RankEdges returns the edges between the rendered nodes with a known
+// throughput, heaviest first. The throughput of an edge is the sum of the
+// throughputs of the flows from the endpoints the source node was mapped
+// from (see MapEndpoints) to endpoints of the target node.
+func RankEdges(nodes report.Nodes) []EdgeThroughput {
+	owners := endpointOwners(nodes)
+	type edge struct{ source, target string }
+	type flow struct{ source, target string }
+	throughputs := map[edge]Throughput{}
+	// NAT copies of an endpoint carry the counters of the original; count
+	// each flow once.
+	counted := map[flow]struct{}{}
+	for id, n := range nodes {
+		n.Children.ForEach(func(child report.Node) {
+			if child.Topology != report.Endpoint {
+				return
+			}
+			source := child.ID
+			if original, ok := child.Latest.Lookup(report.CopyOf); ok {
+				source = original
+			}
+			for _, adjacent := range child.Adjacency {
+				target, ok := owners[adjacent]
+				if !ok || !n.Adjacency.Contains(target) {
+					continue
+				}
+				if _, ok := counted[flow{source, adjacent}]; ok {
+					continue
+				}
+				throughput, ok := EndpointThroughput(child, adjacent)
+				if !ok {
+					continue
+				}
+				counted[flow{source, adjacent}] = struct{}{}
+				e := edge{id, target}
+				throughputs[e] = throughputs[e].Add(throughput)
+			}
+		})
+	}
+
+	result := make([]EdgeThroughput, 0, len(throughputs))
+	for e, throughput := range throughputs {
+		result = append(result, EdgeThroughput{Source: e.source, Target: e.target, Throughput: throughput})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].BytesPerSecond != result[j].BytesPerSecond {
+			return result[i].BytesPerSecond > result[j].BytesPerSecond
+		}
+		if result[i].Source != result[j].Source {
+			return result[i].Source < result[j].Source
+		}
+		return result[i].Target < result[j].Target
+	})
+	return result
+}
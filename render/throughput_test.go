@@ -0,0 +1,90 @@
+package render_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/weaveworks/common/test"
+	"github.com/weaveworks/scope/render"
+	"github.com/weaveworks/scope/report"
+	"github.com/weaveworks/scope/test/reflect"
+)
+
+// counters returns the counters of the flow to the adjacent endpoint.
+func counters(t time.Time, adjacent string, bytes, packets float64) report.Metrics {
+	return report.Metrics{
+		report.MakeFlowCounterKey(report.EndpointBytes, adjacent):   report.MakeSingletonMetric(t, bytes),
+		report.MakeFlowCounterKey(report.EndpointPackets, adjacent): report.MakeSingletonMetric(t, packets),
+	}
+}
+
+func TestEndpointThroughput(t *testing.T) {
+	now := time.Now()
+	endpoint := report.MakeNode("a;1.2.3.4;5678").WithTopology(report.Endpoint)
+
+	adjacent := "b;5.6.7.8;80"
+
+	if _, ok := render.EndpointThroughput(endpoint, adjacent); ok {
+		t.Error("expected no throughput without counters")
+	}
+	endpoint = endpoint.WithMetrics(counters(now, adjacent, 1000, 10))
+	if _, ok := render.EndpointThroughput(endpoint, adjacent); ok {
+		t.Error("expected no throughput with a single sample")
+	}
+	endpoint = endpoint.WithMetrics(endpoint.Metrics.Merge(counters(now.Add(2*time.Second), adjacent, 5000, 30)))
+	have, ok := render.EndpointThroughput(endpoint, adjacent)
+	if want := (render.Throughput{BytesPerSecond: 2000, PacketsPerSecond: 10}); !ok || want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	if _, ok := render.EndpointThroughput(endpoint, "b;5.6.7.9;80"); ok {
+		t.Error("expected no throughput of another flow")
+	}
+}
+
+func TestRankEdges(t *testing.T) {
+	now := time.Now()
+	// endpoint makes an endpoint with flows to the adjacent endpoints,
+	// each at the given bytes per second
+	endpoint := func(id string, bytes float64, adjacent ...string) report.Node {
+		n := report.MakeNode(id).WithTopology(report.Endpoint).WithAdjacent(adjacent...)
+		if bytes > 0 {
+			for _, a := range adjacent {
+				n = n.WithMetrics(n.Metrics.Merge(counters(now, a, 0, 0).Merge(counters(now.Add(time.Second), a, bytes, 1))))
+			}
+		}
+		return n
+	}
+	node := func(id string, children ...report.Node) report.Node {
+		return report.MakeNode(id).WithChildren(report.MakeNodeSet(children...))
+	}
+
+	nodes := report.Nodes{
+		"client": node("client",
+			endpoint(";10.0.0.1;1001", 100, ";10.0.0.3;80"),
+			endpoint(";10.0.0.1;1002", 200, ";10.0.0.3;80"),
+			endpoint(";10.0.0.1;1003", 50, ";10.0.0.4;5432"),
+		).WithAdjacent("server", "db"),
+		"server": node("server",
+			endpoint(";10.0.0.3;80", 0),
+			endpoint(";10.0.0.3;1004", 0, ";10.0.0.4;5432"),
+		).WithAdjacent("db"),
+		"db": node("db", endpoint(";10.0.0.4;5432", 0)),
+		// one socket with flows to endpoints of two nodes only counts
+		// each flow on its own edge
+		"dns": node("dns",
+			endpoint(";10.0.0.5;53", 20, ";10.0.0.6;53", ";10.0.0.7;53"),
+		).WithAdjacent("resolver1", "resolver2"),
+		"resolver1": node("resolver1", endpoint(";10.0.0.6;53", 0)),
+		"resolver2": node("resolver2", endpoint(";10.0.0.7;53", 0)),
+	}
+
+	want := []render.EdgeThroughput{
+		{Source: "client", Target: "server", Throughput: render.Throughput{BytesPerSecond: 300, PacketsPerSecond: 2}},
+		{Source: "client", Target: "db", Throughput: render.Throughput{BytesPerSecond: 50, PacketsPerSecond: 1}},
+		{Source: "dns", Target: "resolver1", Throughput: render.Throughput{BytesPerSecond: 20, PacketsPerSecond: 1}},
+		{Source: "dns", Target: "resolver2", Throughput: render.Throughput{BytesPerSecond: 20, PacketsPerSecond: 1}},
+	}
+	if have := render.RankEdges(nodes); !reflect.DeepEqual(want, have) {
+		t.Error(test.Diff(want, have))
+	}
+}
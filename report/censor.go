@@ -46,23 +46,32 @@ func CensorRawReport(rawReport Report, cfg CensorConfig) Report {
 	censoredReport.WalkTopologies(func(t *Topology) {
 		for nodeID, node := range t.Nodes {
 			if node.Latest != nil {
-				latest := make(StringLatestMap, 0, cap(node.Latest))
-				for _, entry := range node.Latest {
-					// If environment variables are to be hidden, omit passing them to the final report.
-					if cfg.HideEnvironmentVariables && IsEnvironmentVarsEntry(entry.key) {
-						continue
-					}
-					// If command line arguments are to be hidden, strip them away.
-					if cfg.HideCommandLineArguments && IsCommandEntry(entry.key) {
-						entry.Value = StripCommandArgs(entry.Value)
-					}
-					// Pass the latest entry to the final report.
-					latest = append(latest, entry)
-				}
-				node.Latest = latest
-				t.Nodes[nodeID] = node
+				t.Nodes[nodeID] = CensorRawNode(node, cfg)
 			}
 		}
 	})
 	return censoredReport
 }
+
+// CensorRawNode removes any sensitive data from the latest entries of a
+// node, e.g. a rendered one, without modifying the original.
+func CensorRawNode(node Node, cfg CensorConfig) Node {
+	if node.Latest == nil {
+		return node
+	}
+	latest := make(StringLatestMap, 0, cap(node.Latest))
+	for _, entry := range node.Latest {
+		// If environment variables are to be hidden, omit passing them to the final report.
+		if cfg.HideEnvironmentVariables && IsEnvironmentVarsEntry(entry.key) {
+			continue
+		}
+		// If command line arguments are to be hidden, strip them away.
+		if cfg.HideCommandLineArguments && IsCommandEntry(entry.key) {
+			entry.Value = StripCommandArgs(entry.Value)
+		}
+		// Pass the latest entry to the final report.
+		latest = append(latest, entry)
+	}
+	node.Latest = latest
+	return node
+}
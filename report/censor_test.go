@@ -157,3 +157,26 @@ func TestCensorRawReport(t *testing.T) {
 		}
 	}
 }
+
+func TestCensorRawNode(t *testing.T) {
+	mtime.NowForce(time.Now())
+	defer mtime.NowReset()
+
+	n := report.MakeNodeWith("b", map[string]string{
+		"cmdline":     "scope --token=blibli",
+		"docker_env_": "var",
+	})
+	have := report.CensorRawNode(n, report.CensorConfig{
+		HideCommandLineArguments: true,
+		HideEnvironmentVariables: true,
+	})
+	want := report.MakeNodeWith("b", map[string]string{
+		"cmdline": "scope",
+	})
+	if !reflect.DeepEqual(want, have) {
+		t.Error(test.Diff(want, have))
+	}
+	if cmdline, _ := n.Latest.Lookup("cmdline"); cmdline != "scope --token=blibli" {
+		t.Errorf("expected the original node to be left alone, got %q", cmdline)
+	}
+}
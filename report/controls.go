@@ -1,5 +1,12 @@
 package report
 
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
 // Controls describe the control tags within the Nodes
 type Controls map[string]Control
 
@@ -11,6 +18,116 @@ type Control struct {
 	Icon         string `json:"icon"` // from https://fortawesome.github.io/Font-Awesome/cheatsheet/ please
 	Confirmation string `json:"confirmation,omitempty"`
 	Rank         int    `json:"rank"`
+	// Params declare the arguments the control takes. Controls without
+	// params take any arguments.
+	Params []ControlParam `json:"params,omitempty"`
+}
+
+// Types of control params
+const (
+	ControlParamString = "string"
+	ControlParamInt    = "int"
+	ControlParamEnum   = "enum"
+	ControlParamBool   = "bool"
+)
+
+// ControlParam describes an argument of a control
+type ControlParam struct {
+	Name     string `json:"name"`
+	Label    string `json:"label"`
+	Type     string `json:"type"`
+	Required bool   `json:"required,omitempty"`
+	Default  string `json:"default,omitempty"`
+
+	// Options are the values of enum params
+	Options []string `json:"options,omitempty"`
+	// Min and Max bound the values of int params
+	Min *int64 `json:"min,omitempty"`
+	Max *int64 `json:"max,omitempty"`
+	// Pattern is a regular expression string params must match
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// ValidateArgs checks the arguments of a request of the control against
+// its params, returning them with the defaults of missing params filled
+// in.
+func (c Control) ValidateArgs(args map[string]string) (map[string]string, error) {
+	if len(c.Params) == 0 {
+		return args, nil
+	}
+	params := map[string]ControlParam{}
+	for _, p := range c.Params {
+		params[p.Name] = p
+	}
+	for name := range args {
+		if _, ok := params[name]; !ok {
+			return nil, fmt.Errorf("unknown argument %q", name)
+		}
+	}
+	result := map[string]string{}
+	for _, p := range c.Params {
+		value, ok := args[p.Name]
+		if !ok || value == "" {
+			switch {
+			case p.Default != "":
+				value = p.Default
+			case p.Required:
+				return nil, fmt.Errorf("missing argument %q", p.Name)
+			default:
+				continue
+			}
+		}
+		value, err := p.validate(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %q: %v", p.Name, err)
+		}
+		result[p.Name] = value
+	}
+	return result, nil
+}
+
+// validate checks a value of the param, returning it in canonical form.
+func (p ControlParam) validate(value string) (string, error) {
+	switch p.Type {
+	case ControlParamString:
+		if p.Pattern == "" {
+			return value, nil
+		}
+		re, err := compilePattern(p.Pattern)
+		if err != nil {
+			return "", fmt.Errorf("bad pattern %q: %v", p.Pattern, err)
+		}
+		if !re.MatchString(value) {
+			return "", fmt.Errorf("%q does not match %s", value, p.Pattern)
+		}
+		return value, nil
+	case ControlParamInt:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("%q is not an integer", value)
+		}
+		if p.Min != nil && i < *p.Min {
+			return "", fmt.Errorf("%d is less than %d", i, *p.Min)
+		}
+		if p.Max != nil && i > *p.Max {
+			return "", fmt.Errorf("%d is more than %d", i, *p.Max)
+		}
+		return strconv.FormatInt(i, 10), nil
+	case ControlParamEnum:
+		for _, o := range p.Options {
+			if value == o {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("%q is not one of %v", value, p.Options)
+	case ControlParamBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%q is not a boolean", value)
+		}
+		return strconv.FormatBool(b), nil
+	}
+	return "", fmt.Errorf("unknown type %q", p.Type)
 }
 
 // Merge merges other with cs, returning a fresh Controls.
@@ -68,3 +185,30 @@ func (cs Controls) DisableAdminControls() Controls {
 type NodeControlData struct {
 	Dead bool `json:"dead"`
 }
+
+// maxCachedPatterns bounds the patterns compilePattern keeps. Patterns come
+// from the controls probes define, so there are few of them.
+const maxCachedPatterns = 1000
+
+var patterns = struct {
+	sync.Mutex
+	compiled map[string]*regexp.Regexp
+}{compiled: map[string]*regexp.Regexp{}}
+
+// compilePattern compiles the pattern of a param, once.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	patterns.Lock()
+	defer patterns.Unlock()
+	if re, ok := patterns.compiled[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns.compiled) >= maxCachedPatterns {
+		patterns.compiled = map[string]*regexp.Regexp{}
+	}
+	patterns.compiled[pattern] = re
+	return re, nil
+}
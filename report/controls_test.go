@@ -0,0 +1,48 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/weaveworks/scope/report"
+	"github.com/weaveworks/scope/test/reflect"
+)
+
+func TestControlValidateArgs(t *testing.T) {
+	control := report.Control{
+		ID: "snapshot",
+		Params: []report.ControlParam{
+			{Name: "name", Type: report.ControlParamString, Required: true, Pattern: `^[a-z0-9-]+$`},
+			{Name: "consistent", Type: report.ControlParamBool, Default: "false"},
+		},
+	}
+	for _, tc := range []struct {
+		args map[string]string
+		want map[string]string
+	}{
+		{map[string]string{"name": "nightly"}, map[string]string{"name": "nightly", "consistent": "false"}},
+		{map[string]string{"name": "nightly", "consistent": "1"}, map[string]string{"name": "nightly", "consistent": "true"}},
+		{map[string]string{"name": "Nightly!"}, nil},
+		{map[string]string{"name": "nightly", "consistent": "maybe"}, nil},
+		{map[string]string{"consistent": "true"}, nil},
+	} {
+		have, err := control.ValidateArgs(tc.args)
+		if tc.want == nil {
+			if err == nil {
+				t.Errorf("%v: expected an error, got %v", tc.args, have)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.args, err)
+		} else if !reflect.DeepEqual(have, tc.want) {
+			t.Errorf("%v: expected %v, got %v", tc.args, tc.want, have)
+		}
+	}
+
+	// Controls without params take any arguments, such as the resizing of
+	// TTYs
+	args := map[string]string{"pipeID": "pipe", "height": "24", "width": "80"}
+	if have, err := (report.Control{ID: "resize"}).ValidateArgs(args); err != nil || !reflect.DeepEqual(have, args) {
+		t.Errorf("Expected arguments of controls without params to pass through, got %v, %v", have, err)
+	}
+}
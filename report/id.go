@@ -43,6 +43,24 @@ func MakeEndpointNodeIDB(hostID string, namespaceID uint32, addressIP net.IP, po
 	return makeAddressID(hostID, namespace, addressIP.String(), addressIP) + ScopeDelim + strconv.Itoa(int(port))
 }
 
+// MakeProtocolEndpointNodeID makes the ID of an endpoint of another
+// transport protocol than TCP from the ID of the TCP endpoint on the same
+// address and port, so that they are different nodes. TCP endpoint IDs
+// carry no protocol, as they did before other protocols were tracked.
+func MakeProtocolEndpointNodeID(endpointNodeID, protocol string) string {
+	if protocol == "" || protocol == "tcp" {
+		return endpointNodeID
+	}
+	return endpointNodeID + ScopeDelim + protocol
+}
+
+// MakeFlowCounterKey makes the key of a counter metric, e.g. EndpointBytes,
+// of the flow from an endpoint to the adjacent endpoint. An endpoint can
+// have flows to several endpoints, each with its own counters.
+func MakeFlowCounterKey(counter, adjacentEndpointID string) string {
+	return counter + EdgeDelim + adjacentEndpointID
+}
+
 // MakeAddressNodeID produces an address node ID from its composite parts.
 func MakeAddressNodeID(hostID, address string) string {
 	addressIP := net.ParseIP(address)
@@ -192,6 +210,13 @@ var (
 	// ParsePersistentVolumeClaimNodeID parses a Persistent Volume Claim node ID
 	ParsePersistentVolumeClaimNodeID = parseSingleComponentID("persistent_volume_claim")
 
+	// MakeVolumeClaimStatsNodeID produces the ID of a node carrying the usage
+	// statistics of a Persistent Volume Claim, from its namespace/name.
+	MakeVolumeClaimStatsNodeID = makeSingleComponentID("volume_claim_stats")
+
+	// ParseVolumeClaimStatsNodeID parses a volume claim statistics node ID
+	ParseVolumeClaimStatsNodeID = parseSingleComponentID("volume_claim_stats")
+
 	// MakeStorageClassNodeID produces a storage class node ID from its composite parts.
 	MakeStorageClassNodeID = makeSingleComponentID("storage_class")
 
@@ -228,6 +253,54 @@ var (
 	// ParseVolumeSnapshotContentNodeID parses a volume snapshot content node ID
 	ParseVolumeSnapshotContentNodeID = parseSingleComponentID("volume_snapshot_content")
 
+	// MakeNetworkPolicyNodeID produces a network policy node ID from its composite parts.
+	MakeNetworkPolicyNodeID = makeSingleComponentID("network_policy")
+
+	// ParseNetworkPolicyNodeID parses a network policy node ID
+	ParseNetworkPolicyNodeID = parseSingleComponentID("network_policy")
+
+	// MakeIngressNodeID produces an ingress node ID from its composite parts.
+	MakeIngressNodeID = makeSingleComponentID("ingress")
+
+	// ParseIngressNodeID parses an ingress node ID
+	ParseIngressNodeID = parseSingleComponentID("ingress")
+
+	// MakeKubernetesNodeNodeID produces a kubernetes node node ID from its composite parts.
+	MakeKubernetesNodeNodeID = makeSingleComponentID("kubernetes_node")
+
+	// ParseKubernetesNodeNodeID parses a kubernetes node node ID
+	ParseKubernetesNodeNodeID = parseSingleComponentID("kubernetes_node")
+
+	// MakeCSIDriverNodeID produces a CSI driver node ID from its composite parts.
+	MakeCSIDriverNodeID = makeSingleComponentID("csi_driver")
+
+	// ParseCSIDriverNodeID parses a CSI driver node ID
+	ParseCSIDriverNodeID = parseSingleComponentID("csi_driver")
+
+	// MakeJivaVolumeNodeID produces a Jiva volume node ID from its composite parts.
+	MakeJivaVolumeNodeID = makeSingleComponentID("jiva_volume")
+
+	// ParseJivaVolumeNodeID parses a Jiva volume node ID
+	ParseJivaVolumeNodeID = parseSingleComponentID("jiva_volume")
+
+	// MakeCStorVolumeConfigNodeID produces a cStor volume config node ID from its composite parts.
+	MakeCStorVolumeConfigNodeID = makeSingleComponentID("cstor_volume_config")
+
+	// ParseCStorVolumeConfigNodeID parses a cStor volume config node ID
+	ParseCStorVolumeConfigNodeID = parseSingleComponentID("cstor_volume_config")
+
+	// MakeCStorVolumeAttachmentNodeID produces a cStor volume attachment node ID from its composite parts.
+	MakeCStorVolumeAttachmentNodeID = makeSingleComponentID("cstor_volume_attachment")
+
+	// ParseCStorVolumeAttachmentNodeID parses a cStor volume attachment node ID
+	ParseCStorVolumeAttachmentNodeID = parseSingleComponentID("cstor_volume_attachment")
+
+	// MakeGenericControllerNodeID produces a generic controller node ID from its composite parts.
+	MakeGenericControllerNodeID = makeSingleComponentID("generic_controller")
+
+	// ParseGenericControllerNodeID parses a generic controller node ID
+	ParseGenericControllerNodeID = parseSingleComponentID("generic_controller")
+
 	// MakeDiskNodeID produces a disk node ID from its composite parts.
 	MakeDiskNodeID = makeSingleComponentID("disk")
 
@@ -283,6 +356,38 @@ var (
 	ParseCStorPoolInstanceNodeID = parseSingleComponentID("cstor_pool_instance")
 )
 
+// MakeCustomResourceTopology returns the name of the topology of the
+// custom resources of a CustomResourceDefinition, named <plural>.<group>.
+func MakeCustomResourceTopology(crd string) string {
+	return CustomResourcePrefix + crd
+}
+
+// IsCustomResourceTopology tells whether a topology is that of custom
+// resources.
+func IsCustomResourceTopology(topology string) bool {
+	return strings.HasPrefix(topology, CustomResourcePrefix)
+}
+
+// MakeCustomResourceNodeID produces a custom resource node ID from its
+// topology and uid.
+func MakeCustomResourceNodeID(topology, uid string) string {
+	return makeSingleComponentID(topology)(uid)
+}
+
+// ParseCustomResourceNodeID parses a custom resource node ID into its
+// topology and uid.
+func ParseCustomResourceNodeID(id string) (topology, uid string, ok bool) {
+	uid, tag, ok := ParseNodeID(id)
+	if !ok || !strings.HasPrefix(tag, "<") || !strings.HasSuffix(tag, ">") {
+		return "", "", false
+	}
+	topology = tag[1 : len(tag)-1]
+	if !IsCustomResourceTopology(topology) {
+		return "", "", false
+	}
+	return topology, uid, true
+}
+
 // makeSingleComponentID makes a single-component node id encoder
 func makeSingleComponentID(tag string) func(string) string {
 	return func(id string) string {
@@ -339,19 +444,30 @@ func ParseNodeID(nodeID string) (id string, tag string, ok bool) {
 	return split2(nodeID, ScopeDelim)
 }
 
-// ParseEndpointNodeID produces the scope, address, and port and remainder.
-// Note that scope may be blank.
+// ParseEndpointNodeID produces the scope, address, and port. Note that scope
+// may be blank. See ParseEndpointNodeIDProtocol for the protocol.
 func ParseEndpointNodeID(endpointNodeID string) (scope, address, port string, ok bool) {
+	scope, address, port, _, ok = ParseEndpointNodeIDProtocol(endpointNodeID)
+	return
+}
+
+// ParseEndpointNodeIDProtocol is like ParseEndpointNodeID, but it also
+// produces the transport protocol of the endpoint, "tcp" for IDs without.
+func ParseEndpointNodeIDProtocol(endpointNodeID string) (scope, address, port, protocol string, ok bool) {
 	// Not using strings.SplitN() to avoid a heap allocation
 	first := strings.Index(endpointNodeID, ScopeDelim)
 	if first == -1 {
-		return "", "", "", false
+		return "", "", "", "", false
 	}
 	second := strings.Index(endpointNodeID[first+1:], ScopeDelim)
 	if second == -1 {
-		return "", "", "", false
+		return "", "", "", "", false
+	}
+	scope, address, port, protocol = endpointNodeID[:first], endpointNodeID[first+1:first+1+second], endpointNodeID[first+1+second+1:], "tcp"
+	if third := strings.Index(port, ScopeDelim); third != -1 {
+		port, protocol = port[:third], port[third+1:]
 	}
-	return endpointNodeID[:first], endpointNodeID[first+1 : first+1+second], endpointNodeID[first+1+second+1:], true
+	return scope, address, port, protocol, true
 }
 
 // ParseAddressNodeID produces the host ID, address from an address node ID.
@@ -67,6 +67,27 @@ func TestEndpointNodeID(t *testing.T) {
 	}
 }
 
+func TestProtocolEndpointNodeID(t *testing.T) {
+	for input, want := range map[string]struct{ name, address, port, protocol string }{
+		report.MakeEndpointNodeID("host.com", "namespaceid", "127.0.0.1", "c"): {"host.com-namespaceid", "127.0.0.1", "c", "tcp"},
+		"a;b;c": {"a", "b", "c", "tcp"},
+		report.MakeProtocolEndpointNodeID(report.MakeEndpointNodeID("host.com", "", "1.2.3.4", "53"), "udp"): {"", "1.2.3.4", "53", "udp"},
+		report.MakeProtocolEndpointNodeID(report.MakeEndpointNodeID("host.com", "", "1.2.3.4", "53"), "tcp"): {"", "1.2.3.4", "53", "tcp"},
+	} {
+		haveName, haveAddress, havePort, haveProtocol, ok := report.ParseEndpointNodeIDProtocol(input)
+		if !ok {
+			t.Errorf("%q: not OK", input)
+			continue
+		}
+		if want.name != haveName ||
+			want.address != haveAddress ||
+			want.port != havePort ||
+			want.protocol != haveProtocol {
+			t.Errorf("%q: want %q, have {%q, %q, %q, %q}", input, want, haveName, haveAddress, havePort, haveProtocol)
+		}
+	}
+}
+
 func TestECSServiceNodeIDCompat(t *testing.T) {
 	testID := "my-service;<ecs_service>"
 	testName := "my-service"
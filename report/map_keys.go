@@ -6,6 +6,9 @@ const (
 	ReverseDNSNames = "reverse_dns_names"
 	SnoopedDNSNames = "snooped_dns_names"
 	CopyOf          = "copy_of"
+	EndpointBytes   = "endpoint_bytes"
+	EndpointPackets = "endpoint_packets"
+	Protocol        = "protocol"
 	// probe/process
 	PID     = "pid"
 	Name    = "name" // also used by probe/docker
@@ -150,6 +153,76 @@ const (
 	KubernetesNodeName                     = "kubernetes_node_name"
 	KubernetesDriver                       = "kubernetes_driver"
 	KubernetesDeletionPolicy               = "kubernetes_deletion_policy"
+	KubernetesPodSelector                  = "kubernetes_pod_selector"
+	KubernetesPolicyTypes                  = "kubernetes_policy_types"
+	KubernetesIngressRules                 = "kubernetes_ingress_rules"
+	KubernetesEgressRules                  = "kubernetes_egress_rules"
+	KubernetesNetworkPolicySpec            = "kubernetes_network_policy_spec"
+	KubernetesNetworkPolicyAllowed         = "kubernetes_network_policy_allowed"
+	KubernetesNetworkPolicyDenied          = "kubernetes_network_policy_denied"
+	KubernetesIngressClass                 = "kubernetes_ingress_class"
+	KubernetesHosts                        = "kubernetes_hosts"
+	KubernetesTLSHosts                     = "kubernetes_tls_hosts"
+	KubernetesIngressBackends              = "kubernetes_ingress_backends"
+	KubernetesIngressRulesPrefix           = "kubernetes_ingress_rules_"
+	KubernetesReadyEndpoints               = "kubernetes_ready_endpoints"
+	KubernetesNotReadyEndpoints            = "kubernetes_not_ready_endpoints"
+	KubernetesEndpointPods                 = "kubernetes_endpoint_pods"
+	KubernetesLoadBalancerAddresses        = "kubernetes_load_balancer_addresses"
+	KubernetesNodeReady                    = "kubernetes_node_ready"
+	KubernetesNodeConditions               = "kubernetes_node_conditions"
+	KubernetesTaints                       = "kubernetes_taints"
+	KubernetesAllocatableCPU               = "kubernetes_allocatable_cpu"
+	KubernetesAllocatableMemory            = "kubernetes_allocatable_memory"
+	KubernetesAllocatablePods              = "kubernetes_allocatable_pods"
+	KubernetesKubeletVersion               = "kubernetes_kubelet_version"
+	KubernetesUnschedulable                = "kubernetes_unschedulable"
+	KubernetesCordonNode                   = "kubernetes_cordon_node"
+	KubernetesUncordonNode                 = "kubernetes_uncordon_node"
+	KubernetesDrainNode                    = "kubernetes_drain_node"
+	KubernetesEventsPrefix                 = "kubernetes_events_"
+	KubernetesAttachRequired               = "kubernetes_attach_required"
+	KubernetesVolumeLifecycleModes         = "kubernetes_volume_lifecycle_modes"
+	KubernetesSnapshotSupport              = "kubernetes_snapshot_support"
+	KubernetesCSINodes                     = "kubernetes_csi_nodes"
+	KubernetesJivaRole                     = "kubernetes_jiva_role"
+	KubernetesJivaHostPath                 = "kubernetes_jiva_host_path"
+	KubernetesJivaController               = "kubernetes_jiva_controller"
+	KubernetesJivaControllerPod            = "kubernetes_jiva_controller_pod"
+	KubernetesJivaReplicaPods              = "kubernetes_jiva_replica_pods"
+	KubernetesJivaRebuildingReplicas       = "kubernetes_jiva_rebuilding_replicas"
+	KubernetesJivaReplicasPrefix           = "kubernetes_jiva_replicas_"
+	KubernetesLocalPath                    = "kubernetes_local_path"
+	KubernetesCStorPoolInstances           = "kubernetes_cstor_pool_instances"
+	KubernetesPublishedNode                = "kubernetes_published_node"
+	KubernetesDevicePath                   = "kubernetes_device_path"
+	KubernetesVolumeUsedBytes              = "kubernetes_volume_used_bytes"
+	KubernetesVolumeAvailableBytes         = "kubernetes_volume_available_bytes"
+	KubernetesVolumeInodesUsed             = "kubernetes_volume_inodes_used"
+	KubernetesVolumeInodesFree             = "kubernetes_volume_inodes_free"
+	KubernetesPoolUsedBytes                = "kubernetes_pool_used_bytes"
+	KubernetesPoolFreeBytes                = "kubernetes_pool_free_bytes"
+	KubernetesDeviceCapacityBytes          = "kubernetes_device_capacity_bytes"
+	KubernetesExpandVolumeClaim            = "kubernetes_expand_volume_claim"
+	KubernetesDeleteVolumeClaim            = "kubernetes_delete_volume_claim"
+	KubernetesResizeStatus                 = "kubernetes_resize_status"
+	KubernetesVolumeExpansion              = "kubernetes_volume_expansion"
+	KubernetesCustomResourceKind           = "kubernetes_custom_resource_kind"
+	KubernetesCustomResourceName           = "kubernetes_custom_resource_name"
+	KubernetesCustomResourceLabel          = "kubernetes_custom_resource_label"
+	KubernetesAutoscaler                   = "kubernetes_autoscaler"
+	KubernetesMinReplicas                  = "kubernetes_min_replicas"
+	KubernetesMaxReplicas                  = "kubernetes_max_replicas"
+	KubernetesAutoscalerMetricsPrefix      = "kubernetes_autoscaler_metrics_"
+	KubernetesCPURequest                   = "kubernetes_cpu_request"
+	KubernetesCPULimit                     = "kubernetes_cpu_limit"
+	KubernetesMemoryRequest                = "kubernetes_memory_request"
+	KubernetesMemoryLimit                  = "kubernetes_memory_limit"
+	KubernetesNodeCPUCapacity              = "kubernetes_node_cpu_capacity"
+	KubernetesRestartRollout               = "kubernetes_restart_rollout"
+	KubernetesRollbackDeployment           = "kubernetes_rollback_deployment"
+	KubernetesSetReplicas                  = "kubernetes_set_replicas"
+	KubernetesRolloutHistoryPrefix         = "kubernetes_rollout_history_"
 	// probe/awsecs
 	ECSCluster             = "ecs_cluster"
 	ECSCreatedAt           = "ecs_created_at"
@@ -169,6 +242,8 @@ const (
 	HostCPUUsage      = "host_cpu_usage_percent"
 	HostMemoryUsage   = "host_mem_usage_bytes"
 	ScopeVersion      = "host_scope_version"
+	// probe/endpoint, on hosts
+	HostConnectionCounters = "host_connection_counters"
 	// probe/overlay/weave
 	WeavePeerName     = "weave_peer_name"
 	WeavePeerNickName = "weave_peer_nick_name"
@@ -215,6 +290,9 @@ var commonKeys = map[string]string{
 	ReverseDNSNames: ReverseDNSNames,
 	SnoopedDNSNames: SnoopedDNSNames,
 	CopyOf:          CopyOf,
+	EndpointBytes:   EndpointBytes,
+	EndpointPackets: EndpointPackets,
+	Protocol:        Protocol,
 
 	PID:     PID,
 	Name:    Name,
@@ -301,6 +379,8 @@ var commonKeys = map[string]string{
 	HostMemoryUsage:   HostMemoryUsage,
 	ScopeVersion:      ScopeVersion,
 
+	HostConnectionCounters: HostConnectionCounters,
+
 	WeavePeerName:     WeavePeerName,
 	WeavePeerNickName: WeavePeerNickName,
 }
@@ -3,6 +3,7 @@ package report
 import (
 	"fmt"
 	"math/rand"
+	"sort"
 	"strings"
 	"time"
 
@@ -46,6 +47,18 @@ const (
 	CsiVolumeSnapshot     = "csi_volume_snapshot"
 	VolumeSnapshotClass   = "volume_snapshot_class"
 	VolumeSnapshotContent = "volume_snapshot_content"
+	NetworkPolicy         = "network_policy"
+	Ingress               = "ingress"
+	KubernetesNode        = "kubernetes_node"
+	CSIDriver             = "csi_driver"
+	JivaVolume            = "jiva_volume"
+	CStorVolumeConfig     = "cstor_volume_config"
+	CStorVolumeAttachment = "cstor_volume_attachment"
+	GenericController     = "generic_controller"
+
+	// CustomResourcePrefix prefixes the names of the topologies of custom
+	// resources, which are configured in the probe rather than known here.
+	CustomResourcePrefix = "custom_resource:"
 
 	// Shapes used for different nodes
 	Circle          = "circle"
@@ -108,6 +121,14 @@ var topologyNames = []string{
 	CsiVolumeSnapshot,
 	VolumeSnapshotClass,
 	VolumeSnapshotContent,
+	NetworkPolicy,
+	Ingress,
+	KubernetesNode,
+	CSIDriver,
+	JivaVolume,
+	CStorVolumeConfig,
+	CStorVolumeAttachment,
+	GenericController,
 }
 
 // Report is the core data type. It's produced by probes, and consumed and
@@ -256,6 +277,40 @@ type Report struct {
 	// VolumeSnapshotContent represent all Kubernetes CSI Volume Snapshot content on hosts running probes.
 	VolumeSnapshotContent Topology
 
+	// NetworkPolicy represent all Kubernetes Network Policies on hosts running probes.
+	NetworkPolicy Topology
+
+	// Ingress represent all Kubernetes Ingresses on hosts running probes.
+	Ingress Topology
+
+	// KubernetesNode represent all Kubernetes Nodes, as reported by the
+	// Kubernetes API. Their metadata is merged into the host topology.
+	KubernetesNode Topology
+
+	// CSIDriver represent all CSI drivers registered in Kubernetes, with
+	// the nodes they run on.
+	CSIDriver Topology
+
+	// JivaVolume represent all Jiva volumes running in cluster, made of
+	// their controller and replica pods.
+	JivaVolume Topology
+
+	// CStorVolumeConfig represent all CStorVolumeConfigs of cStor volumes
+	// provisioned through CSI
+	CStorVolumeConfig Topology
+
+	// CStorVolumeAttachment represent all CStorVolumeAttachments, which
+	// record the nodes CSI cStor volumes are attached to
+	CStorVolumeAttachment Topology
+
+	// GenericController represent the controllers of pods of kinds without
+	// a topology of their own, found by following owner references
+	GenericController Topology
+
+	// CustomResources represent the custom resources the probes have been
+	// configured to report, by topology name.
+	CustomResources map[string]*Topology `deepequal:"nil==empty"`
+
 	DNS DNSRecords `json:"nodes,omitempty" deepequal:"nil==empty"`
 
 	// Sampling data for this report.
@@ -426,6 +481,40 @@ func MakeReport() Report {
 			WithTag(Camera).
 			WithLabel("volume snapshot content", "volume snapshot content"),
 
+		NetworkPolicy: MakeTopology().
+			WithShape(Hexagon).
+			WithLabel("network policy", "network policies"),
+
+		Ingress: MakeTopology().
+			WithShape(Cloud).
+			WithLabel("ingress", "ingresses"),
+
+		KubernetesNode: MakeTopology().
+			WithShape(Circle).
+			WithLabel("kubernetes node", "kubernetes nodes"),
+
+		CSIDriver: MakeTopology().
+			WithShape(Square).
+			WithLabel("CSI driver", "CSI drivers"),
+
+		JivaVolume: MakeTopology().
+			WithShape(Controller).
+			WithLabel("Jiva Volume", "Jiva Volumes"),
+
+		CStorVolumeConfig: MakeTopology().
+			WithShape(DottedSquare).
+			WithLabel("cStor Volume Config", "cStor Volume Configs"),
+
+		CStorVolumeAttachment: MakeTopology().
+			WithShape(DottedRectangle).
+			WithLabel("cStor Volume Attachment", "cStor Volume Attachments"),
+
+		GenericController: MakeTopology().
+			WithShape(Heptagon).
+			WithLabel("controller", "controllers"),
+
+		CustomResources: map[string]*Topology{},
+
 		DNS: DNSRecords{},
 
 		Sampling: Sampling{},
@@ -486,6 +575,9 @@ func (r *Report) WalkTopologies(f func(*Topology)) {
 	for _, name := range topologyNames {
 		f(r.topology(name))
 	}
+	for _, name := range customResourceTopologyNames(r) {
+		f(r.CustomResources[name])
+	}
 }
 
 // WalkNamedTopologies iterates through the Topologies of the report,
@@ -494,14 +586,66 @@ func (r *Report) WalkNamedTopologies(f func(string, *Topology)) {
 	for _, name := range topologyNames {
 		f(name, r.topology(name))
 	}
+	for _, name := range customResourceTopologyNames(r) {
+		f(name, r.CustomResources[name])
+	}
 }
 
 // WalkPairedTopologies iterates through the Topologies of this and another report,
 // potentially modifying one or both.
+// Custom resource topologies only found in the other report are added to
+// this one, but not the other way round.
 func (r *Report) WalkPairedTopologies(o *Report, f func(*Topology, *Topology)) {
 	for _, name := range topologyNames {
 		f(r.topology(name), o.topology(name))
 	}
+	for _, name := range customResourceTopologyNames(r, o) {
+		theirs, ok := o.CustomResources[name]
+		if !ok {
+			t := MakeTopology()
+			theirs = &t
+		}
+		f(r.customResourceTopology(name), theirs)
+	}
+}
+
+// customResourceTopologyNames returns the names of the custom resource
+// topologies found in any of the reports, sorted.
+func customResourceTopologyNames(reports ...*Report) []string {
+	names := []string{}
+	seen := map[string]struct{}{}
+	for _, r := range reports {
+		for name := range r.CustomResources {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// customResourceTopology returns a reference to the topology of a custom
+// resource, adding it to the report if missing.
+func (r *Report) customResourceTopology(name string) *Topology {
+	if r.CustomResources == nil {
+		r.CustomResources = map[string]*Topology{}
+	}
+	t, ok := r.CustomResources[name]
+	if !ok {
+		topology := MakeTopology()
+		t = &topology
+		r.CustomResources[name] = t
+	}
+	return t
+}
+
+// CustomResourceTopology returns a reference to the topology of a custom
+// resource, selected by the name of its CustomResourceDefinition, adding
+// it to the report if missing.
+func (r *Report) CustomResourceTopology(crd string) *Topology {
+	return r.customResourceTopology(MakeCustomResourceTopology(crd))
 }
 
 // topology returns a reference to one of the report's topologies,
@@ -578,6 +722,25 @@ func (r *Report) topology(name string) *Topology {
 		return &r.VolumeSnapshotClass
 	case VolumeSnapshotContent:
 		return &r.VolumeSnapshotContent
+	case NetworkPolicy:
+		return &r.NetworkPolicy
+	case Ingress:
+		return &r.Ingress
+	case KubernetesNode:
+		return &r.KubernetesNode
+	case CSIDriver:
+		return &r.CSIDriver
+	case JivaVolume:
+		return &r.JivaVolume
+	case CStorVolumeConfig:
+		return &r.CStorVolumeConfig
+	case CStorVolumeAttachment:
+		return &r.CStorVolumeAttachment
+	case GenericController:
+		return &r.GenericController
+	}
+	if IsCustomResourceTopology(name) {
+		return r.CustomResources[name]
 	}
 	return nil
 }
@@ -593,11 +756,11 @@ func (r Report) Topology(name string) (Topology, bool) {
 // Validate checks the report for various inconsistencies.
 func (r Report) Validate() error {
 	var errs []string
-	for _, name := range topologyNames {
-		if err := r.topology(name).Validate(); err != nil {
+	r.WalkTopologies(func(t *Topology) {
+		if err := t.Validate(); err != nil {
 			errs = append(errs, err.Error())
 		}
-	}
+	})
 	if r.Sampling.Count > r.Sampling.Total {
 		errs = append(errs, fmt.Sprintf("sampling count (%d) bigger than total (%d)", r.Sampling.Count, r.Sampling.Total))
 	}
@@ -767,4 +930,7 @@ const (
 	HostNodeID = "host_node_id"
 	// ControlProbeID is the random ID of the probe which controls the specific node.
 	ControlProbeID = "control_probe_id"
+	// ProbeIdentity is the common name of the certificate the probe which
+	// reported a host authenticated with.
+	ProbeIdentity = "probe_identity"
 )
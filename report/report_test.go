@@ -136,3 +136,42 @@ func TestReportUnMerge(t *testing.T) {
 		t.Error(test.Diff(expected, r2))
 	}
 }
+
+func TestReportCustomResources(t *testing.T) {
+	var (
+		topology = report.MakeCustomResourceTopology("kafkas.kafka.strimzi.io")
+		id1      = report.MakeCustomResourceNodeID(topology, "uid1")
+		id2      = report.MakeCustomResourceNodeID(topology, "uid2")
+		r1       = report.MakeReport()
+		r2       = report.MakeReport()
+	)
+	r1.CustomResourceTopology("kafkas.kafka.strimzi.io").AddNode(report.MakeNode(id1))
+	r2.CustomResourceTopology("kafkas.kafka.strimzi.io").AddNode(report.MakeNode(id2))
+
+	merged := report.MakeReport().Merge(r1).Merge(r2)
+	have, ok := merged.Topology(topology)
+	if !ok {
+		t.Fatalf("expected the %s topology to be found", topology)
+	}
+	if len(have.Nodes) != 2 {
+		t.Errorf("expected both custom resources to be merged, have %v", have.Nodes)
+	}
+	if len(r1.CustomResources[topology].Nodes) != 1 {
+		t.Errorf("expected merging not to modify the merged reports")
+	}
+
+	names := []string{}
+	merged.WalkNamedTopologies(func(name string, _ *report.Topology) {
+		names = append(names, name)
+	})
+	if names[len(names)-1] != topology {
+		t.Errorf("expected custom resource topologies to be walked, have %v", names)
+	}
+
+	if parsedTopology, uid, ok := report.ParseCustomResourceNodeID(id1); !ok || parsedTopology != topology || uid != "uid1" {
+		t.Errorf("failed to parse %s: %s %s %v", id1, parsedTopology, uid, ok)
+	}
+	if _, _, ok := report.ParseCustomResourceNodeID(report.MakePodNodeID("uid1")); ok {
+		t.Errorf("expected pod node IDs not to parse as custom resources")
+	}
+}